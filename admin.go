@@ -0,0 +1,90 @@
+package mockhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// AdminPath is the conventional path to mount the admin handler.
+const AdminPath = "/__mockhttp"
+
+// maxAdminUnmatched is the max number of the most recent unmatched requests listed by the admin handler.
+const maxAdminUnmatched = 100
+
+type adminHandler struct {
+	client *Client
+	mux    *http.ServeMux
+}
+
+// NewAdminHandler creates read-only HTTP handler to inspect the state of the client and its resolver as JSON,
+// for services running mockhttp in long-lived staging deployments:
+//   - GET /definitions : loaded definitions with the hit counts (resolver must implement StatsReporter)
+//   - GET /unmatched   : most recent requests with no mock response (from the client journal)
+//
+// The handler routes are relative, mount it with http.StripPrefix:
+//
+//	mux.Handle(mockhttp.AdminPath+"/", http.StripPrefix(mockhttp.AdminPath, mockhttp.NewAdminHandler(client)))
+func NewAdminHandler(client *Client) http.Handler {
+	h := &adminHandler{client: client, mux: http.NewServeMux()}
+	h.mux.HandleFunc("/definitions", h.definitions)
+	h.mux.HandleFunc("/unmatched", h.unmatched)
+	return h
+}
+
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "mockhttp: method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *adminHandler) definitions(w http.ResponseWriter, r *http.Request) {
+	reporter, ok := h.client.snapshot().resolver.(StatsReporter)
+	if !ok {
+		http.Error(w, "mockhttp: resolver does not report definition stats", http.StatusNotImplemented)
+		return
+	}
+	writeJSON(w, reporter.Stats())
+}
+
+// adminUnmatchedRequest is a single unmatched request listed by the admin handler.
+type adminUnmatchedRequest struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	URL        string    `json:"url"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+func (h *adminHandler) unmatched(w http.ResponseWriter, r *http.Request) {
+	unmatched := h.client.Journal().Filter(func(entry JournalEntry) bool {
+		return !entry.Info.Mocked && !entry.Info.Bypassed
+	})
+	if len(unmatched) > maxAdminUnmatched {
+		unmatched = unmatched[len(unmatched)-maxAdminUnmatched:]
+	}
+
+	requests := make([]adminUnmatchedRequest, 0, len(unmatched))
+	for _, entry := range unmatched {
+		request := adminUnmatchedRequest{
+			Time:       entry.Time,
+			Method:     entry.Method,
+			URL:        entry.URL.String(),
+			StatusCode: entry.StatusCode,
+		}
+		if entry.Err != nil {
+			request.Error = entry.Err.Error()
+		}
+		requests = append(requests, request)
+	}
+	writeJSON(w, requests)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(v)
+}
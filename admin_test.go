@@ -0,0 +1,64 @@
+package mockhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminHandler(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer upstream.Close()
+
+	client := newTestClient(newTestResolver(t, `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+`))
+	_, err := client.Get("http://marketplace.com/products")
+	assert.Nil(t, err)
+	_, err = client.Get(upstream.URL + "/orders")
+	assert.Nil(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle(AdminPath+"/", http.StripPrefix(AdminPath, NewAdminHandler(client)))
+	admin := httptest.NewServer(mux)
+	defer admin.Close()
+
+	t.Run("definitions", func(t *testing.T) {
+		resp, err := http.Get(admin.URL + AdminPath + "/definitions")
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+		var stats []DefinitionStats
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(&stats))
+		assert.Len(t, stats, 1)
+		assert.Equal(t, "GET marketplace.com/products", stats[0].Definition)
+		assert.Equal(t, uint64(1), stats[0].Hits)
+	})
+
+	t.Run("unmatched", func(t *testing.T) {
+		resp, err := http.Get(admin.URL + AdminPath + "/unmatched")
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+
+		var unmatched []adminUnmatchedRequest
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(&unmatched))
+		assert.Len(t, unmatched, 1)
+		assert.Equal(t, upstream.URL+"/orders", unmatched[0].URL)
+		assert.Equal(t, http.StatusOK, unmatched[0].StatusCode)
+	})
+
+	t.Run("read only", func(t *testing.T) {
+		resp, err := http.Post(admin.URL+AdminPath+"/definitions", "application/json", nil)
+		assert.Nil(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
@@ -0,0 +1,13 @@
+package mockhttp
+
+// BodyLimitPolicy decide how the request body exceeding the max body size (see WithMaxBodySize) is matched.
+type BodyLimitPolicy int
+
+const (
+	// BodyLimitReject fail the request with ErrBodyTooLarge, when the matched definition needs the body.
+	// It is the default policy.
+	BodyLimitReject BodyLimitPolicy = iota
+	// BodyLimitIgnore match the request as if it has no body: `body` is nil and `raw` is empty for the rules,
+	// so the responses without body rule can still be selected.
+	BodyLimitIgnore
+)
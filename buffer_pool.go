@@ -0,0 +1,32 @@
+package mockhttp
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBufferSize is the max capacity of the buffer returned into the pool,
+// so a single large body does not keep its memory allocated forever.
+const maxPooledBufferSize = 1 << 20
+
+// bufferPool is the pool of the temporary buffers used to capture the bodies and render the templates,
+// to reduce the allocations when many requests are served (ex: load tests through the mock client).
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// getBuffer returns an empty buffer from the pool, it must be returned with putBuffer once not used anymore.
+// The buffer content must not be retained after returned, copy it instead (ex: buf.String()).
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns the buffer into the pool.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	bufferPool.Put(buf)
+}
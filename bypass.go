@@ -0,0 +1,34 @@
+package mockhttp
+
+import (
+	"context"
+	"strings"
+)
+
+// BypassHeader is the reserved request header to skip the mock resolver for a single request,
+// so the request always hit the actual upstream service. The header is removed before sending the request upstream.
+//
+// ex: X-Mockhttp-Bypass: true
+const BypassHeader = "X-Mockhttp-Bypass"
+
+type bypassContextKey struct{}
+
+// WithBypass returns a copy of ctx that makes Client.Do skip the mock resolver
+// for any request using the context, so a test can mix mocked and real calls to the same endpoint.
+func WithBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassContextKey{}, true)
+}
+
+// shouldBypass check whether the request should skip the mock resolver,
+// either via context (WithBypass) or via reserved header (BypassHeader).
+//
+// The reserved header is always removed from the request.
+func shouldBypass(req *Request) bool {
+	bypass, _ := req.Context().Value(bypassContextKey{}).(bool)
+
+	if value := req.Header.Get(BypassHeader); value != "" {
+		req.Header.Del(BypassHeader)
+		bypass = bypass || strings.EqualFold(value, "true")
+	}
+	return bypass
+}
@@ -0,0 +1,149 @@
+package mockhttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultCallbackTimeout is the max duration of a single callback request (excluding the callback delay).
+const defaultCallbackTimeout = 30 * time.Second
+
+// CallbackDrainer is implemented by resolver adapters that trigger the mock response callbacks asynchronously,
+// so the pending callbacks can be delivered before the process exits.
+//
+// The built-in file based resolver implements CallbackDrainer:
+//
+//	err := resolver.(mockhttp.CallbackDrainer).DrainCallbacks(shutdownCtx)
+type CallbackDrainer interface {
+	DrainCallbacks(ctx context.Context) error
+}
+
+// fileBasedResolver fireCallbacks
+// Trigger all callbacks defined on the served mock response asynchronously,
+// to simulate upstream service that call back the caller via webhook.
+//
+// Each callback is executed on its own goroutine, after waiting for the configured delay (in milliseconds).
+// Callback failures don't affect the served mock response, they are emitted as EventCallbackError instead.
+func (r *fileBasedResolver) fireCallbacks(request *incomingRequest, response *mockResponse) {
+	if len(response.Callbacks) == 0 {
+		return
+	}
+
+	data := request.collectAllParams()
+	for _, callback := range response.Callbacks {
+		r.callbacks.Add(1)
+		go func(callback mockCallback) {
+			defer r.callbacks.Done()
+			if err := r.fireCallback(callback, data); err != nil {
+				r.emit(Event{Type: EventCallbackError, Method: request.Method, URL: request.URL, Definition: request.Definition, Err: err})
+			}
+		}(callback)
+	}
+}
+
+func (r *fileBasedResolver) fireCallback(callback mockCallback, data params) error {
+	if callback.Delay > 0 {
+		timer := time.NewTimer(time.Duration(callback.Delay) * time.Millisecond)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.callbackCtx.Done():
+			return fmt.Errorf("callback %s: %w", callback.URL, r.callbackCtx.Err())
+		}
+	}
+
+	req, err := r.buildCallbackRequest(callback, data)
+	if err != nil {
+		return fmt.Errorf("callback %s: %w", callback.URL, err)
+	}
+
+	resp, err := r.callbackClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("callback %s %s: %w", req.Method, req.URL, err)
+	}
+	io.Copy(io.Discard, resp.Body) // nolint: errcheck
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("callback %s %s: unexpected status %d", req.Method, req.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+// fileBasedResolver DrainCallbacks
+// Wait for the pending callbacks (including their delay) to be delivered until ctx is done,
+// then abort the remaining callbacks and return ctx error.
+// Call it after the mock stopped serving requests (ex: on server shutdown), as the callbacks triggered afterward
+// are not waited for.
+func (r *fileBasedResolver) DrainCallbacks(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.callbacks.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		r.cancelCallbacks()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// fileBasedResolver buildCallbackRequest generate the outgoing webhook request based on callback definition.
+//
+// Support templating via Go text/template for url and body if `enable_template` is true.
+func (r *fileBasedResolver) buildCallbackRequest(callback mockCallback, data params) (*http.Request, error) {
+	url, body := callback.URL, callback.Body
+	if callback.EnableTemplate {
+		var err error
+		if url, err = r.renderTemplate(url, data); err != nil {
+			return nil, err
+		}
+		if body, err = r.renderTemplate(body, data); err != nil {
+			return nil, err
+		}
+	}
+
+	method := callback.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequestWithContext(r.callbackCtx, strings.ToUpper(method), url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range callback.Headers {
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
+
+// fileBasedResolver parseTemplate parse text as Go text/template,
+// with `state` function to read value from the state store.
+// Every template (response body, set_state, callbacks and default headers) is parsed the same way.
+func (r *fileBasedResolver) parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(template.FuncMap{"state": r.state.get}).Parse(text)
+}
+
+// fileBasedResolver renderTemplate parse and render text using Go text/template (see parseTemplate).
+func (r *fileBasedResolver) renderTemplate(text string, data params) (string, error) {
+	t, err := r.parseTemplate("mock-text", text)
+	if err != nil {
+		return "", err
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := t.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,140 @@
+package mockhttp
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of the circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed let all upstream requests through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen reject all upstream requests, until the open timeout passed.
+	CircuitOpen
+	// CircuitHalfOpen let a single trial upstream request through, to check whether the upstream had recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitOpenHandler is called instead of the upstream request when the circuit is open,
+// ex: to serve a mock fallback response for degraded upstream.
+type CircuitOpenHandler func(req *Request) (*http.Response, error)
+
+// CircuitBreaker guards the passthrough (non-mocked) upstream requests.
+// The circuit opens after FailureThreshold consecutive failures, rejecting upstream requests for OpenTimeout,
+// then lets a single trial request through (half-open) to decide whether to close or re-open the circuit.
+//
+// CircuitBreaker is safe for concurrent use.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failures to open the circuit.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before allowing a trial request.
+	OpenTimeout time.Duration
+	// IsFailure decide whether the upstream result is a failure.
+	// The default treats connection errors and server errors (5xx) as failure.
+	IsFailure func(resp *http.Response, err error) bool
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trialing bool
+	now      func() time.Time
+}
+
+// NewCircuitBreaker creates a new CircuitBreaker with the failure threshold and open timeout.
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		FailureThreshold: failureThreshold,
+		OpenTimeout:      openTimeout,
+		IsFailure:        DefaultCircuitFailure,
+		now:              time.Now,
+	}
+}
+
+// DefaultCircuitFailure treats connection errors and server errors (5xx) as failure.
+func DefaultCircuitFailure(resp *http.Response, err error) bool {
+	return err != nil || resp == nil || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// State returns the current state of the circuit.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.currentState()
+}
+
+// Allow check whether the upstream request can be executed.
+// On half-open state, only a single trial request is allowed until its result is recorded.
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.currentState() {
+	case CircuitClosed:
+		return true
+	case CircuitHalfOpen:
+		if cb.trialing {
+			return false
+		}
+		cb.trialing = true
+		return true
+	}
+	return false
+}
+
+// Record the upstream request result, to open or close the circuit.
+func (cb *CircuitBreaker) Record(resp *http.Response, err error) {
+	isFailure := cb.IsFailure
+	if isFailure == nil {
+		isFailure = DefaultCircuitFailure
+	}
+	failed := isFailure(resp, err)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	state := cb.currentState()
+	cb.trialing = false
+	if !failed {
+		cb.state = CircuitClosed
+		cb.failures = 0
+		return
+	}
+
+	cb.failures++
+	if state == CircuitHalfOpen || cb.failures >= cb.FailureThreshold {
+		cb.state = CircuitOpen
+		cb.openedAt = cb.clock()
+	}
+}
+
+// currentState returns the state, moving open circuit into half-open once the open timeout passed.
+// Must be called while holding the lock.
+func (cb *CircuitBreaker) currentState() CircuitState {
+	if cb.state == CircuitOpen && cb.clock().Sub(cb.openedAt) >= cb.OpenTimeout {
+		cb.state = CircuitHalfOpen
+	}
+	return cb.state
+}
+
+func (cb *CircuitBreaker) clock() time.Time {
+	if cb.now == nil {
+		return time.Now()
+	}
+	return cb.now()
+}
@@ -0,0 +1,70 @@
+package mockhttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cb := NewCircuitBreaker(2, time.Minute)
+	cb.now = func() time.Time { return now }
+
+	failed := errors.New("connection refused")
+	ok := &http.Response{StatusCode: http.StatusOK}
+
+	assert.True(t, cb.Allow())
+	cb.Record(nil, failed)
+	assert.Equal(t, CircuitClosed, cb.State())
+
+	assert.True(t, cb.Allow())
+	cb.Record(&http.Response{StatusCode: http.StatusBadGateway}, nil)
+	assert.Equal(t, CircuitOpen, cb.State())
+	assert.False(t, cb.Allow())
+
+	// only a single trial request is allowed once the open timeout passed
+	now = now.Add(time.Minute)
+	assert.Equal(t, CircuitHalfOpen, cb.State())
+	assert.True(t, cb.Allow())
+	assert.False(t, cb.Allow())
+
+	// failed trial re-open the circuit
+	cb.Record(nil, failed)
+	assert.Equal(t, CircuitOpen, cb.State())
+
+	// successful trial close the circuit
+	now = now.Add(time.Minute)
+	assert.True(t, cb.Allow())
+	cb.Record(ok, nil)
+	assert.Equal(t, CircuitClosed, cb.State())
+	assert.True(t, cb.Allow())
+}
+
+func TestClient_Do_CircuitBreaker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := newTestClient(noMockResolver{})
+	client.CircuitBreaker = NewCircuitBreaker(1, time.Hour)
+
+	resp, err := client.Get(server.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	_, err = client.Get(server.URL)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	client.CircuitOpenHandler = func(req *Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}
+	resp, err = client.Get(server.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
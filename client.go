@@ -1,6 +1,7 @@
 package mockhttp
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"log"
@@ -9,6 +10,8 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/hashicorp/go-cleanhttp"
 )
@@ -17,13 +20,17 @@ var (
 	// Default mock configuration
 	// defaultLogger is the logger provided with defaultClient
 	defaultLogger = log.New(os.Stderr, "", log.LstdFlags)
+
+	// We need to consume response bodies to maintain http connections, but
+	// limit the size we consume to respReadLimit (unless configured via Client.DrainLimit).
+	respReadLimit = int64(4096)
 )
 
 // Client is used to make HTTP requests. It adds additional functionality
 // for testing purposes to mock certain http requests based on mock definition.
 type Client struct {
 	HTTPClient *http.Client // Internal HTTP client.
-	Logger     interface{}  // Customer logger instance. Can be either Logger or LeveledLogger
+	Logger     interface{}  // Customer logger instance. Can be either Logger or LeveledLogger (ex: *slog.Logger)
 
 	// RequestLogHook allows a user-supplied function to be called
 	// before each httprequest  call.
@@ -33,51 +40,171 @@ type Client struct {
 	// with the response from each HTTP request executed.
 	ResponseLogHook ResponseLogHook
 
+	// ResolvedResponseLogHook is like ResponseLogHook, but called for both mocked and actual upstream response,
+	// with the information whether the response came from a mock and which definition produced it.
+	ResolvedResponseLogHook ResolvedResponseLogHook
+
 	// Resolver represents the mock definition resolver.
 	// The built-in library provides file-based datastore, but it can be easily extended to use any other datastore.
 	Resolver ResolverAdapter
 
+	RetryWaitMin time.Duration // Minimum time to wait between retries of passthrough requests
+	RetryWaitMax time.Duration // Maximum time to wait between retries of passthrough requests
+	RetryMax     int           // Maximum number of retries of passthrough requests, 0 disable retry
+
+	// CheckRetry specifies the policy for handling retries of passthrough (non-mocked) requests,
+	// and is called after each upstream request. The default policy is DefaultRetryPolicy.
+	CheckRetry CheckRetry
+
+	// Backoff specifies the policy for how long to wait between retries.
+	// The default policy is DefaultBackoff.
+	Backoff Backoff
+
+	// CircuitBreaker optionally guards the passthrough (non-mocked) requests, nil disable circuit breaker.
+	CircuitBreaker *CircuitBreaker
+
+	// CircuitOpenHandler is called instead of the upstream request when the circuit is open,
+	// ex: to serve a mock fallback response. Without handler, ErrCircuitOpen is returned.
+	CircuitOpenHandler CircuitOpenHandler
+
+	// ResolverErrorPolicy decide how Do handle the error from Resolver (ex: unsupported content type, rule evaluation error).
+	// The default policy is ResolverErrorLogAndPassthrough.
+	ResolverErrorPolicy ResolverErrorPolicy
+
+	// ResolveHook allows a user-supplied function to be called after each request is resolved (or bypassed),
+	// ex: to tell mocked calls from real ones in traces / metrics.
+	ResolveHook ResolveHook
+
+	// Metrics optionally receives the mock activity of the client (hits, misses, rule failures, passthrough).
+	Metrics MetricsRecorder
+
+	// HostFilter optionally restrict the hosts that are intercepted (mocked / recorded),
+	// the requests to the other hosts are always sent upstream without being recorded.
+	HostFilter *HostFilter
+
+	// Recorder optionally records the upstream interaction of the requests that does not match any mock definition,
+	// ex: NewFileRecorder to write them as mock definitions (record mode).
+	Recorder Recorder
+
+	// MockOnly prevent any request with no mock response from hitting the actual upstream service,
+	// ex: to make sure unit tests never make outbound network calls. Bypassed requests (WithBypass / EnableMock(false))
+	// are still sent upstream.
+	MockOnly bool
+
+	// UnmatchedHandler is called in mock-only mode when no mock response is found,
+	// ex: NotImplementedHandler to respond with 501. Without handler, ErrUnmatchedRequest is returned.
+	UnmatchedHandler UnmatchedHandler
+
+	// NearMissHandler is called with the closest definitions (and which criteria failed) when no mock response is found,
+	// ex: to log why a request silently passthrough to the upstream service.
+	NearMissHandler NearMissHandler
+
+	// CloseIdleConnectionsOnError closes the idle connections of HTTPClient when a request failed,
+	// ex: to avoid reusing broken connections. By default the connection pool is kept warm.
+	CloseIdleConnectionsOnError bool
+
+	// Jar optionally keep the cookies across requests, for both mocked and actual upstream responses
+	// (ex: session based flows). Use this instead of HTTPClient.Jar, to avoid sending duplicate cookies upstream.
+	Jar http.CookieJar
+
+	// JournalLimit is the max number of the intercepted requests kept in the journal (oldest dropped first),
+	// 0 means the default limit (1000 requests), negative means unlimited.
+	JournalLimit int
+
+	// DrainLimit is the max number of bytes read from the discarded upstream response body
+	// (before retrying, on CheckRetry error, or on response handler error), so the connection can be reused.
+	// 0 means the default limit (4096 bytes), negative disable the draining: the body is closed right away,
+	// and the connection is not reused when the body is not fully read.
+	DrainLimit int64
+
+	middlewares      []Middleware
+	mockHitHandlers  []MockHitHandler
+	mockMissHandlers []MockMissHandler
+	eventListeners   []EventListener
+	hostClients      map[string]*http.Client
+
+	// mu guards the Resolver, Logger, hooks and handlers, when reconfigured at runtime via the setters.
+	mu         sync.RWMutex
 	loggerInit sync.Once
 	clientInit sync.Once
+
+	// mockDisabled is toggled at runtime via EnableMock, mock is enabled by default.
+	mockDisabled atomic.Bool
+
+	// journal keep every intercepted request, used to verify how the upstreams were called.
+	journal journal
 }
 
-// NewClient creates a new mockhttp Client with default settings.
-func NewClient(resolver ResolverAdapter) *Client {
-	return &Client{
-		HTTPClient: cleanhttp.DefaultPooledClient(),
-		Logger:     defaultLogger,
-		Resolver:   resolver,
+// ResolverErrorPolicy decide how Client.Do handle the error returned by the resolver.
+// No mock response found (ErrNoMockResponse) is not considered as an error.
+type ResolverErrorPolicy int
+
+const (
+	// ResolverErrorLogAndPassthrough log the error and continue with the actual upstream request.
+	ResolverErrorLogAndPassthrough ResolverErrorPolicy = iota
+	// ResolverErrorPassthrough silently continue with the actual upstream request.
+	ResolverErrorPassthrough
+	// ResolverErrorFail return the error to the caller, without sending the request upstream.
+	ResolverErrorFail
+)
+
+// NewClient creates a new mockhttp Client with default settings, customized by the options (if any).
+func NewClient(resolver ResolverAdapter, opts ...ClientOption) *Client {
+	client := &Client{
+		HTTPClient:   cleanhttp.DefaultPooledClient(),
+		Logger:       defaultLogger,
+		Resolver:     resolver,
+		RetryWaitMin: defaultRetryWaitMin,
+		RetryWaitMax: defaultRetryWaitMax,
+		RetryMax:     defaultRetryMax,
+		CheckRetry:   DefaultRetryPolicy,
+		Backoff:      DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(client)
 	}
+	return client
 }
 
-func (c *Client) logger() interface{} {
-	c.loggerInit.Do(func() {
-		if c.Logger == nil {
-			return
-		}
+// EnableMock turn the mock resolver on / off at runtime (ex: from an ops endpoint), without rebuilding the client.
+// While disabled, every request is sent to the actual upstream service. It is safe for concurrent use.
+func (c *Client) EnableMock(enabled bool) {
+	c.mockDisabled.Store(!enabled)
+}
 
-		switch c.Logger.(type) {
-		case Logger, LeveledLogger:
-			// ok
-		default:
-			// This should happen in dev when they are setting Logger and work on code, not in prod.
-			panic(fmt.Sprintf("invalid logger type passed, must be Logger or LeveledLogger, was %T", c.Logger))
-		}
-	})
+// IsMockEnabled report whether the mock resolver is currently enabled.
+func (c *Client) IsMockEnabled() bool {
+	return !c.mockDisabled.Load()
+}
 
-	return c.Logger
+func (c *Client) logger() interface{} {
+	return c.snapshot().logger
 }
 
 // Do wraps calling an HTTP method to also check if the request
 // should be mock or not, based on mock definition loaded during client initialization.
+//
+// The request goes through the middleware chain (see Use) first, if any.
 func (c *Client) Do(req *Request) (*http.Response, error) {
+	c.mu.RLock()
+	middlewares := c.middlewares
+	c.mu.RUnlock()
+
+	if len(middlewares) == 0 {
+		return c.do(req)
+	}
+	return chain(middlewares, c.do)(req)
+}
+
+func (c *Client) do(req *Request) (resp *http.Response, err error) {
 	c.clientInit.Do(func() {
 		if c.HTTPClient == nil {
 			c.HTTPClient = cleanhttp.DefaultPooledClient()
 		}
 	})
 
-	logger := c.logger()
+	cfg := c.snapshot()
+	logger := cfg.logger
 	if logger != nil {
 		switch v := logger.(type) {
 		case LeveledLogger:
@@ -87,74 +214,320 @@ func (c *Client) Do(req *Request) (*http.Response, error) {
 		}
 	}
 
-	var resp *http.Response
-	if req.body != nil {
-		body, readErr := req.body()
-		if readErr != nil {
-			c.HTTPClient.CloseIdleConnections()
-			return resp, readErr
-		}
-		if c, ok := body.(io.ReadCloser); ok {
-			req.Body = c
-		} else {
-			req.Body = io.NopCloser(body)
-		}
+	if err := req.rewindBody(); err != nil {
+		c.closeIdleConnectionsOnError()
+		return resp, err
 	}
 
-	if c.RequestLogHook != nil {
+	bypass := shouldBypass(req) || !c.IsMockEnabled() || !c.HostFilter.Match(req.URL)
+	c.addCookies(req)
+
+	if cfg.requestLogHook != nil {
 		switch v := logger.(type) {
 		case LeveledLogger:
-			c.RequestLogHook(hookLogger{v}, req.Request)
+			cfg.requestLogHook(hookLogger{v}, req.Request)
 		case Logger:
-			c.RequestLogHook(v, req.Request)
+			cfg.requestLogHook(v, req.Request)
 		default:
-			c.RequestLogHook(nil, req.Request)
+			cfg.requestLogHook(nil, req.Request)
 		}
 	}
 
-	// Check if we should continue with actual http call / use mock
-	mockResponse, err := c.Resolver.Resolve(req.Context(), req)
-	if err != nil {
+	// Check if we should continue with actual http call / use mock (unless the request bypass the mock)
+	mockResponse, info, simulatedErr := resolve(cfg.resolver, req, bypass)
+	if cfg.resolveHook != nil {
+		cfg.resolveHook(req, info)
+	}
+	if c.Metrics != nil {
+		c.Metrics.ObserveResolve(req, info)
+	}
+	cfg.notifyMock(req, info)
+	entry := c.journal.record(req, info, c.JournalLimit)
+	defer func() { c.journal.complete(entry, resp, err) }()
+	if logger != nil {
+		switch v := logger.(type) {
+		case LeveledLogger:
+			v.Debug("resolved request", "method", req.Method, "url", req.URL, "mocked", info.Mocked, "bypassed", info.Bypassed, "definition", info.Definition)
+		case Logger:
+			v.Printf("[DEBUG] %s %s resolved (mocked: %t, bypassed: %t, definition: %q)", req.Method, req.URL, info.Mocked, info.Bypassed, info.Definition)
+		}
+	}
+	if simulatedErr != nil {
+		cfg.emit(EventFaultInjected, req, info, 0, simulatedErr)
+		return c.handleResponse(req)(nil, simulatedErr)
+	}
+	if !bypass {
+		if ctxErr := req.Context().Err(); ctxErr != nil && mockResponse == nil {
+			// the request is cancelled (ex: during mock delay), should not continue to upstream.
+			c.closeIdleConnectionsOnError()
+			return nil, ctxErr
+		}
+		if info.Err != nil {
+			switch c.ResolverErrorPolicy {
+			case ResolverErrorFail:
+				c.closeIdleConnectionsOnError()
+				return nil, info.Err
+			case ResolverErrorLogAndPassthrough:
+				if logger != nil {
+					switch v := logger.(type) {
+					case LeveledLogger:
+						v.Error("error resolving mock response", "err", info.Err)
+					case Logger:
+						v.Printf("[ERROR] error resolving mock response :%s", info.Err.Error())
+					}
+				}
+			}
+		}
+		if mockResponse == nil && info.Err == nil && c.NearMissHandler != nil {
+			c.reportNearMisses(cfg.resolver, req)
+		}
+		if mockResponse != nil {
+			cfg.logResolvedResponse(mockResponse, info)
+			c.storeCookies(req, mockResponse)
+			return c.handleResponse(req)(mockResponse, nil)
+		}
+		if c.MockOnly {
+			c.closeIdleConnectionsOnError()
+			return c.handleResponse(req)(c.unmatched(req))
+		}
+	}
+
+	// Only attempt the request if no mock definition found!
+	checkRetry := c.CheckRetry
+	if checkRetry == nil {
+		checkRetry = DefaultRetryPolicy
+	}
+	backoff := c.Backoff
+	if backoff == nil {
+		backoff = DefaultBackoff
+	}
+
+	var checkErr error
+	for i := 0; ; i++ {
+		// Always rewind the request body, as it had been read by the resolver (or previous attempt).
+		if err := req.rewindBody(); err != nil {
+			c.closeIdleConnectionsOnError()
+			return resp, err
+		}
+
+		if c.CircuitBreaker != nil && !c.CircuitBreaker.Allow() {
+			c.closeIdleConnectionsOnError()
+			if c.CircuitOpenHandler != nil {
+				return c.handleResponse(req)(c.CircuitOpenHandler(req))
+			}
+			return nil, ErrCircuitOpen
+		}
+
+		start := time.Now()
+		resp, err = cfg.httpClient(req, c.HTTPClient).Do(req.Request)
+		if c.Metrics != nil {
+			c.Metrics.ObservePassthrough(req, resp, err, time.Since(start))
+		}
+		if c.CircuitBreaker != nil {
+			c.CircuitBreaker.Record(resp, err)
+		}
+		if err == nil {
+			c.storeCookies(req, resp)
+		}
+		if err != nil {
+			switch v := logger.(type) {
+			case LeveledLogger:
+				v.Error("request failed", "error", err, "method", req.Method, "url", req.URL)
+			case Logger:
+				v.Printf("[ERROR] %s %s request failed: %v", req.Method, req.URL, err)
+			}
+		} else {
+			// Call this here to maintain the behavior of logging all requests,
+			// even if CheckRetry signals to stop.
+			if cfg.responseLogHook != nil {
+				// Call the response logger function if provided.
+				switch v := logger.(type) {
+				case LeveledLogger:
+					cfg.responseLogHook(hookLogger{v}, resp)
+				case Logger:
+					cfg.responseLogHook(v, resp)
+				default:
+					cfg.responseLogHook(nil, resp)
+				}
+			}
+			cfg.logResolvedResponse(resp, info)
+		}
+
+		// Retry is disabled (or exhausted), return the upstream response as is.
+		if i >= c.RetryMax {
+			break
+		}
+
+		var shouldRetry bool
+		shouldRetry, checkErr = checkRetry(req.Context(), resp, err)
+		if !shouldRetry {
+			break
+		}
+
+		// We're going to retry, consume any response to reuse the connection.
+		if err == nil && resp != nil {
+			c.drainBody(resp.Body)
+		}
+
+		wait := backoff(c.RetryWaitMin, c.RetryWaitMax, i, resp)
 		if logger != nil {
+			desc := fmt.Sprintf("%s %s", req.Method, req.URL)
 			switch v := logger.(type) {
 			case LeveledLogger:
-				v.Error("error resolving mock response", "err", err)
+				v.Debug("retrying request", "request", desc, "timeout", wait, "remaining", c.RetryMax-i)
 			case Logger:
-				v.Printf("[ERROR] error resolving mock response :%s", err.Error())
+				v.Printf("[DEBUG] %s: retrying in %s (%d left)", desc, wait, c.RetryMax-i)
 			}
 		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			c.closeIdleConnectionsOnError()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
 	}
-	if mockResponse != nil {
-		return mockResponse, nil
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
 	}
+	cfg.emit(EventPassthrough, req, info, statusCode, err)
 
-	// Only attempt the request if no mock definition found!
-	resp, err = c.HTTPClient.Do(req.Request)
+	if checkErr != nil {
+		// the response is not returned along with the error, consume it to reuse the connection
+		if err == nil && resp != nil {
+			c.drainBody(resp.Body)
+		}
+		c.closeIdleConnectionsOnError()
+		return nil, checkErr
+	}
 	if err != nil {
+		c.closeIdleConnectionsOnError()
+	}
+	if err == nil && c.Recorder != nil && !bypass {
+		c.record(logger, req, resp)
+	}
+	return c.handleResponse(req)(resp, err)
+}
+
+// record the upstream interaction via the Recorder, the response body is buffered so it can still be read by the caller.
+func (c *Client) record(logger interface{}, req *Request, resp *http.Response) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err == nil {
+		err = c.Recorder.Record(req, resp, body)
+	}
+	if err != nil && logger != nil {
 		switch v := logger.(type) {
 		case LeveledLogger:
-			v.Error("request failed", "error", err, "method", req.Method, "url", req.URL)
+			v.Error("error recording response", "error", err, "method", req.Method, "url", req.URL)
 		case Logger:
-			v.Printf("[ERROR] %s %s request failed: %v", req.Method, req.URL, err)
+			v.Printf("[ERROR] %s %s error recording response: %v", req.Method, req.URL, err)
 		}
-	} else {
-		// Call this here to maintain the behavior of logging all requests,
-		// even if CheckRetry signals to stop.
-		if c.ResponseLogHook != nil {
-			// Call the response logger function if provided.
+	}
+}
+
+// handleResponse returns a function that call the request response handler (if any)
+// with the successful response returned by Do, both mocked and actual upstream response.
+//
+// When the handler fails, the response body is drained and the handler error is returned instead.
+func (c *Client) handleResponse(req *Request) func(*http.Response, error) (*http.Response, error) {
+	return func(resp *http.Response, err error) (*http.Response, error) {
+		if err != nil || resp == nil || req.responseHandler == nil {
+			return resp, err
+		}
+		if err := req.responseHandler(resp); err != nil {
+			if resp.Body != nil {
+				c.drainBody(resp.Body)
+			}
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// logResolvedResponse call the ResolvedResponseLogHook (if any) with the response and how it is resolved.
+func (s clientSnapshot) logResolvedResponse(resp *http.Response, info ResolveInfo) {
+	if s.resolvedResponseLogHook == nil {
+		return
+	}
+	switch v := s.logger.(type) {
+	case LeveledLogger:
+		s.resolvedResponseLogHook(hookLogger{v}, resp, info)
+	case Logger:
+		s.resolvedResponseLogHook(v, resp, info)
+	default:
+		s.resolvedResponseLogHook(nil, resp, info)
+	}
+}
+
+// closeIdleConnectionsOnError closes the idle connections when the request failed,
+// only if enabled via CloseIdleConnectionsOnError.
+func (c *Client) closeIdleConnectionsOnError() {
+	if c.CloseIdleConnectionsOnError {
+		c.CloseIdleConnections()
+	}
+}
+
+// CloseIdleConnections closes any idle connections of the underlying HTTP clients (including the host clients).
+func (c *Client) CloseIdleConnections() {
+	if c.HTTPClient != nil {
+		c.HTTPClient.CloseIdleConnections()
+	}
+
+	c.mu.RLock()
+	hostClients := c.hostClients
+	c.mu.RUnlock()
+	for _, client := range hostClients {
+		client.CloseIdleConnections()
+	}
+}
+
+// addCookies add the cookies from the cookie jar (if any) to the request,
+// so both the resolver and the upstream receive the stored cookies.
+func (c *Client) addCookies(req *Request) {
+	if c.Jar == nil {
+		return
+	}
+	for _, cookie := range c.Jar.Cookies(req.URL) {
+		req.AddCookie(cookie)
+	}
+}
+
+// storeCookies store the cookies set by the response (Set-Cookie header) into the cookie jar (if any).
+func (c *Client) storeCookies(req *Request, resp *http.Response) {
+	if c.Jar == nil || resp == nil {
+		return
+	}
+	if cookies := resp.Cookies(); len(cookies) > 0 {
+		c.Jar.SetCookies(req.URL, cookies)
+	}
+}
+
+// Try to read the response body (up to DrainLimit) so we can reuse this connection.
+func (c *Client) drainBody(body io.ReadCloser) {
+	defer body.Close()
+
+	limit := c.DrainLimit
+	if limit == 0 {
+		limit = respReadLimit
+	}
+	if limit < 0 {
+		return
+	}
+	_, err := io.Copy(io.Discard, io.LimitReader(body, limit))
+	if err != nil {
+		if logger := c.logger(); logger != nil {
 			switch v := logger.(type) {
 			case LeveledLogger:
-				c.ResponseLogHook(hookLogger{v}, resp)
+				v.Error("error reading response body", "error", err)
 			case Logger:
-				c.ResponseLogHook(v, resp)
-			default:
-				c.ResponseLogHook(nil, resp)
+				v.Printf("[ERR] error reading response body: %v", err)
 			}
 		}
 	}
-	defer c.HTTPClient.CloseIdleConnections()
-
-	return resp, err
 }
 
 // Get is a convenience helper for doing simple GET requests.
@@ -191,6 +564,39 @@ func (c *Client) PostForm(url string, data url.Values) (*http.Response, error) {
 	return c.Post(url, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
 }
 
+// Put is a convenience method for doing simple PUT requests.
+func (c *Client) Put(url, contentType string, body interface{}) (*http.Response, error) {
+	return c.doWithBody(http.MethodPut, url, contentType, body)
+}
+
+// Patch is a convenience method for doing simple PATCH requests.
+func (c *Client) Patch(url, contentType string, body interface{}) (*http.Response, error) {
+	return c.doWithBody(http.MethodPatch, url, contentType, body)
+}
+
+// Delete is a convenience method for doing simple DELETE requests.
+func (c *Client) Delete(url string) (*http.Response, error) {
+	req, err := NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// DeleteWithBody is a convenience method for doing DELETE requests with request body.
+func (c *Client) DeleteWithBody(url, contentType string, body interface{}) (*http.Response, error) {
+	return c.doWithBody(http.MethodDelete, url, contentType, body)
+}
+
+func (c *Client) doWithBody(method, url, contentType string, body interface{}) (*http.Response, error) {
+	req, err := NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return c.Do(req)
+}
+
 // StandardClient returns a stdlib *http.Client with a custom Transport, which
 // shims in a *mockhttp.Client for added retries.
 func (c *Client) StandardClient() *http.Client {
@@ -0,0 +1,102 @@
+package mockhttp
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// clientSnapshot is the point-in-time copy of the client configuration that can be reconfigured at runtime,
+// taken once per request so a concurrent setter call never affects an in-flight request.
+type clientSnapshot struct {
+	resolver                ResolverAdapter
+	logger                  interface{}
+	requestLogHook          RequestLogHook
+	responseLogHook         ResponseLogHook
+	resolvedResponseLogHook ResolvedResponseLogHook
+	resolveHook             ResolveHook
+	mockHitHandlers         []MockHitHandler
+	mockMissHandlers        []MockMissHandler
+	eventListeners          []EventListener
+	hostClients             map[string]*http.Client
+}
+
+func (c *Client) snapshot() clientSnapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	c.loggerInit.Do(func() {
+		validateLogger(c.Logger)
+	})
+	return clientSnapshot{
+		resolver:                c.Resolver,
+		logger:                  c.Logger,
+		requestLogHook:          c.RequestLogHook,
+		responseLogHook:         c.ResponseLogHook,
+		resolvedResponseLogHook: c.ResolvedResponseLogHook,
+		resolveHook:             c.ResolveHook,
+		mockHitHandlers:         c.mockHitHandlers,
+		mockMissHandlers:        c.mockMissHandlers,
+		eventListeners:          c.eventListeners,
+		hostClients:             c.hostClients,
+	}
+}
+
+// validateLogger panics when the logger is neither Logger nor LeveledLogger.
+func validateLogger(logger interface{}) {
+	if logger == nil {
+		return
+	}
+
+	switch logger.(type) {
+	case Logger, LeveledLogger:
+		// ok
+	default:
+		// This should happen in dev when they are setting Logger and work on code, not in prod.
+		panic(fmt.Sprintf("invalid logger type passed, must be Logger or LeveledLogger, was %T", logger))
+	}
+}
+
+// SetResolver swap the mock definition resolver, ex: between test cases of a shared client.
+// It is safe for concurrent use with Do, in-flight requests keep using the previous resolver.
+func (c *Client) SetResolver(resolver ResolverAdapter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Resolver = resolver
+}
+
+// SetLogger swap the logger, must be either Logger or LeveledLogger. It is safe for concurrent use with Do.
+func (c *Client) SetLogger(logger interface{}) {
+	validateLogger(logger)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Logger = logger
+}
+
+// SetRequestLogHook swap the RequestLogHook. It is safe for concurrent use with Do.
+func (c *Client) SetRequestLogHook(hook RequestLogHook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.RequestLogHook = hook
+}
+
+// SetResponseLogHook swap the ResponseLogHook. It is safe for concurrent use with Do.
+func (c *Client) SetResponseLogHook(hook ResponseLogHook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ResponseLogHook = hook
+}
+
+// SetResolvedResponseLogHook swap the ResolvedResponseLogHook. It is safe for concurrent use with Do.
+func (c *Client) SetResolvedResponseLogHook(hook ResolvedResponseLogHook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ResolvedResponseLogHook = hook
+}
+
+// SetResolveHook swap the ResolveHook. It is safe for concurrent use with Do.
+func (c *Client) SetResolveHook(hook ResolveHook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ResolveHook = hook
+}
@@ -0,0 +1,42 @@
+package mockhttp
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_SetResolver(t *testing.T) {
+	client := newTestClient(staticResolver{statusCode: http.StatusOK})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get("http://marketplace.com/products")
+			assert.Nil(t, err)
+			assert.Contains(t, []int{http.StatusOK, http.StatusTeapot}, resp.StatusCode)
+		}()
+		go func() {
+			defer wg.Done()
+			client.SetResolver(staticResolver{statusCode: http.StatusTeapot})
+			client.SetResolveHook(func(req *Request, info ResolveInfo) {})
+			client.SetLogger(nil)
+		}()
+	}
+	wg.Wait()
+
+	resp, err := client.Get("http://marketplace.com/products")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+}
+
+func TestClient_SetLogger_InvalidLogger(t *testing.T) {
+	client := newTestClient(noMockResolver{})
+	assert.Panics(t, func() {
+		client.SetLogger("not a logger")
+	})
+}
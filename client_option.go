@@ -0,0 +1,47 @@
+package mockhttp
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ClientOption configure optional behavior of the client, on top of the default settings (cleanhttp pooled client).
+type ClientOption func(*Client)
+
+// WithTransport replace the transport of the underlying HTTP client, used for the passthrough (non-mocked) requests.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.HTTPClient.Transport = transport
+	}
+}
+
+// WithTLSConfig set the TLS configuration (ex: custom root CAs, client certificate for mTLS)
+// of the underlying HTTP transport, keeping the other cleanhttp defaults.
+//
+// It only applies to *http.Transport (or nil transport), apply WithTransport first when both are used.
+// It panics with ErrUnsupportedTransport for any other transport (ex: custom http.RoundTripper),
+// instead of silently dropping the TLS configuration.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(c *Client) {
+		var transport *http.Transport
+		switch t := c.HTTPClient.Transport.(type) {
+		case nil:
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			transport = t.Clone()
+		default:
+			panic(fmt.Errorf("mockhttp: WithTLSConfig: %w: %T", ErrUnsupportedTransport, t))
+		}
+		transport.TLSClientConfig = config
+		c.HTTPClient.Transport = transport
+	}
+}
+
+// WithTimeout set the overall timeout of each passthrough request (see http.Client.Timeout), 0 means no timeout.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.HTTPClient.Timeout = timeout
+	}
+}
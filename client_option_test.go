@@ -0,0 +1,66 @@
+package mockhttp
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClient_Options(t *testing.T) {
+	t.Run("with transport", func(t *testing.T) {
+		client := NewClient(noMockResolver{}, WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusAccepted, Body: http.NoBody, Request: req}, nil
+		})))
+		client.Logger = nil
+
+		resp, err := client.Get("http://inventory.internal/stocks")
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	})
+
+	t.Run("with tls config", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client := NewClient(noMockResolver{}, WithTLSConfig(&tls.Config{InsecureSkipVerify: true}))
+		client.Logger = nil
+
+		resp, err := client.Get(server.URL)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// cleanhttp defaults are kept
+		transport := client.HTTPClient.Transport.(*http.Transport)
+		assert.Equal(t, 90*time.Second, transport.IdleConnTimeout)
+	})
+
+	t.Run("with tls config on unsupported transport", func(t *testing.T) {
+		custom := WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, nil
+		}))
+		assert.PanicsWithError(t, "mockhttp: WithTLSConfig: unsupported http transport: mockhttp.roundTripFunc", func() {
+			NewClient(noMockResolver{}, custom, WithTLSConfig(&tls.Config{}))
+		})
+		func() {
+			defer func() {
+				err, _ := recover().(error)
+				assert.ErrorIs(t, err, ErrUnsupportedTransport)
+			}()
+			NewClient(noMockResolver{}, custom, WithTLSConfig(&tls.Config{}))
+		}()
+		assert.Panics(t, func() {
+			NewRoundTripper(noMockResolver{}, roundTripFunc(nil), WithTLSConfig(&tls.Config{}))
+		})
+	})
+
+	t.Run("with timeout", func(t *testing.T) {
+		client := NewClient(noMockResolver{}, WithTimeout(5*time.Second))
+		assert.Equal(t, 5*time.Second, client.HTTPClient.Timeout)
+	})
+}
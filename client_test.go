@@ -0,0 +1,521 @@
+package mockhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// noMockResolver is a resolver that never mock any request, so every request passthrough to upstream.
+type noMockResolver struct{}
+
+func (noMockResolver) LoadDefinition(ctx context.Context) error { return nil }
+
+func (noMockResolver) Resolve(ctx context.Context, req *Request) (*http.Response, error) {
+	return nil, ErrNoMockResponse
+}
+
+func newTestClient(resolver ResolverAdapter) *Client {
+	client := NewClient(resolver)
+	client.Logger = nil
+	return client
+}
+
+func TestClient_Do_Retry(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(body) // nolint: errcheck
+	}))
+	defer server.Close()
+
+	t.Run("retry until upstream recovered", func(t *testing.T) {
+		attempts.Store(0)
+		client := newTestClient(noMockResolver{})
+		client.RetryMax = 3
+		client.RetryWaitMin = time.Millisecond
+		client.RetryWaitMax = time.Millisecond
+
+		resp, err := client.Post(server.URL, "text/plain", []byte("payload"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(3), attempts.Load())
+
+		body, err := io.ReadAll(resp.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, "payload", string(body))
+	})
+
+	t.Run("retry disabled by default", func(t *testing.T) {
+		attempts.Store(0)
+		client := newTestClient(noMockResolver{})
+
+		resp, err := client.Get(server.URL)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+}
+
+// trackedBody records how many bytes of the response body were read, and whether it was closed.
+type trackedBody struct {
+	io.Reader
+	read   int
+	closed bool
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	b.read += n
+	return n, err
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestClient_Do_DrainLimit(t *testing.T) {
+	abort := errors.New("abort")
+
+	tests := []struct {
+		name     string
+		limit    int64
+		expected int
+	}{
+		{"default limit", 0, 4096},
+		{"custom limit", 10, 10},
+		{"disabled", -1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []*trackedBody
+			client := newTestClient(noMockResolver{})
+			client.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				body := &trackedBody{Reader: strings.NewReader(strings.Repeat("a", 8192))}
+				bodies = append(bodies, body)
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: body, Request: req}, nil
+			})
+			client.DrainLimit = tt.limit
+			client.RetryMax = 2
+			client.RetryWaitMin = time.Millisecond
+			client.RetryWaitMax = time.Millisecond
+			client.CheckRetry = func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+				if len(bodies) > 1 {
+					return false, abort
+				}
+				return true, nil
+			}
+
+			resp, err := client.Get("http://marketplace.com/products")
+			assert.ErrorIs(t, err, abort)
+			assert.Nil(t, resp)
+
+			// the body of the retried attempt, and of the aborted attempt are drained and closed
+			assert.Len(t, bodies, 2)
+			for _, body := range bodies {
+				assert.Equal(t, tt.expected, body.read)
+				assert.True(t, body.closed)
+			}
+		})
+	}
+}
+
+func TestDefaultBackoff(t *testing.T) {
+	assert.Equal(t, 4*time.Second, DefaultBackoff(time.Second, 30*time.Second, 2, nil))
+	assert.Equal(t, 30*time.Second, DefaultBackoff(time.Second, 30*time.Second, 10, nil))
+
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"7"}}}
+	assert.Equal(t, 7*time.Second, DefaultBackoff(time.Second, 30*time.Second, 0, resp))
+}
+
+// staticResolver is a resolver that mock every request with the same status code.
+type staticResolver struct {
+	statusCode int
+}
+
+func (staticResolver) LoadDefinition(ctx context.Context) error { return nil }
+
+func (r staticResolver) Resolve(ctx context.Context, req *Request) (*http.Response, error) {
+	return &http.Response{StatusCode: r.statusCode, Body: http.NoBody, Request: req.Request}, nil
+}
+
+func TestClient_Do_Bypass(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(BypassHeader) != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := newTestClient(staticResolver{statusCode: http.StatusTeapot})
+
+	t.Run("mocked without bypass", func(t *testing.T) {
+		resp, err := client.Get(server.URL)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+	})
+
+	t.Run("bypass via context", func(t *testing.T) {
+		req, err := NewRequestWithContext(WithBypass(context.Background()), http.MethodGet, server.URL, nil)
+		assert.Nil(t, err)
+
+		resp, err := client.Do(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("bypass via reserved header", func(t *testing.T) {
+		req, err := NewRequest(http.MethodGet, server.URL, nil)
+		assert.Nil(t, err)
+		req.Header.Set(BypassHeader, "true")
+
+		resp, err := client.Do(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestClient_EnableMock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := newTestClient(staticResolver{statusCode: http.StatusTeapot})
+	assert.True(t, client.IsMockEnabled())
+
+	client.EnableMock(false)
+	assert.False(t, client.IsMockEnabled())
+	resp, err := client.Get(server.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	client.EnableMock(true)
+	resp, err = client.Get(server.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+}
+
+func TestClient_Do_MockOnly(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Run("return error for unmatched request", func(t *testing.T) {
+		client := newTestClient(noMockResolver{})
+		client.MockOnly = true
+
+		resp, err := client.Get(server.URL)
+		assert.Nil(t, resp)
+		assert.ErrorIs(t, err, ErrUnmatchedRequest)
+	})
+
+	t.Run("respond with unmatched handler", func(t *testing.T) {
+		client := newTestClient(noMockResolver{})
+		client.MockOnly = true
+		client.UnmatchedHandler = NotImplementedHandler
+
+		resp, err := client.Get(server.URL)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+	})
+
+	t.Run("bypassed request still hit upstream", func(t *testing.T) {
+		client := newTestClient(noMockResolver{})
+		client.MockOnly = true
+
+		req, err := NewRequestWithContext(WithBypass(context.Background()), http.MethodGet, server.URL, nil)
+		assert.Nil(t, err)
+		resp, err := client.Do(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	assert.Equal(t, int32(1), hits.Load())
+}
+
+// failingResolver is a resolver that always fail to resolve the request.
+type failingResolver struct{}
+
+func (failingResolver) LoadDefinition(ctx context.Context) error { return nil }
+
+func (failingResolver) Resolve(ctx context.Context, req *Request) (*http.Response, error) {
+	return nil, ErrUnsupportedContentType
+}
+
+func TestClient_Do_ResolverErrorPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		policy     ResolverErrorPolicy
+		wantStatus int
+		wantErr    error
+	}{
+		{name: "log and passthrough", policy: ResolverErrorLogAndPassthrough, wantStatus: http.StatusOK},
+		{name: "passthrough", policy: ResolverErrorPassthrough, wantStatus: http.StatusOK},
+		{name: "fail", policy: ResolverErrorFail, wantErr: ErrUnsupportedContentType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(failingResolver{})
+			client.ResolverErrorPolicy = tt.policy
+
+			resp, err := client.Get(server.URL)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestClient_Do_ResponseHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		resolver ResolverAdapter
+		want     int
+	}{
+		{name: "mocked response", resolver: staticResolver{statusCode: http.StatusTeapot}, want: http.StatusTeapot},
+		{name: "upstream response", resolver: noMockResolver{}, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(tt.resolver)
+
+			var handled int
+			req, err := NewRequest(http.MethodGet, server.URL, nil)
+			assert.Nil(t, err)
+			req.SetResponseHandler(func(resp *http.Response) error {
+				handled = resp.StatusCode
+				return nil
+			})
+
+			resp, err := client.Do(req)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, resp.StatusCode)
+			assert.Equal(t, tt.want, handled)
+		})
+	}
+
+	t.Run("handler error", func(t *testing.T) {
+		client := newTestClient(staticResolver{statusCode: http.StatusTeapot})
+
+		req, err := NewRequest(http.MethodGet, server.URL, nil)
+		assert.Nil(t, err)
+		req.SetResponseHandler(func(resp *http.Response) error {
+			return ErrCommon
+		})
+
+		resp, err := client.Do(req)
+		assert.Nil(t, resp)
+		assert.ErrorIs(t, err, ErrCommon)
+	})
+}
+
+func TestClient_ConvenienceMethods(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Content-Type", r.Header.Get("Content-Type"))
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	client := newTestClient(noMockResolver{})
+	tests := []struct {
+		name     string
+		do       func() (*http.Response, error)
+		wantVerb string
+		wantBody string
+	}{
+		{
+			name:     "put",
+			do:       func() (*http.Response, error) { return client.Put(server.URL, "application/json", []byte(`{"a":1}`)) },
+			wantVerb: http.MethodPut,
+			wantBody: `{"a":1}`,
+		},
+		{
+			name:     "patch",
+			do:       func() (*http.Response, error) { return client.Patch(server.URL, "application/json", []byte(`{"b":2}`)) },
+			wantVerb: http.MethodPatch,
+			wantBody: `{"b":2}`,
+		},
+		{
+			name:     "delete",
+			do:       func() (*http.Response, error) { return client.Delete(server.URL) },
+			wantVerb: http.MethodDelete,
+		},
+		{
+			name: "delete with body",
+			do: func() (*http.Response, error) {
+				return client.DeleteWithBody(server.URL, "application/json", []byte(`{"c":3}`))
+			},
+			wantVerb: http.MethodDelete,
+			wantBody: `{"c":3}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.do()
+			assert.Nil(t, err)
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantVerb, resp.Header.Get("X-Method"))
+			assert.Equal(t, tt.wantBody, string(body))
+			if tt.wantBody != "" {
+				assert.Equal(t, "application/json", resp.Header.Get("X-Content-Type"))
+			}
+		})
+	}
+}
+
+// resolverFunc adapts a function into ResolverAdapter, to mock the request selectively in test.
+type resolverFunc func(req *Request) (*http.Response, error)
+
+func (resolverFunc) LoadDefinition(ctx context.Context) error { return nil }
+
+func (f resolverFunc) Resolve(ctx context.Context, req *Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClient_Do_CookieJar(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, cookie.Value)
+	}))
+	defer server.Close()
+
+	client := newTestClient(resolverFunc(func(req *Request) (*http.Response, error) {
+		if req.URL.Path != "/login" {
+			return nil, ErrNoMockResponse
+		}
+		header := http.Header{}
+		header.Add("Set-Cookie", "session=mocked-session; Path=/")
+		return &http.Response{StatusCode: http.StatusOK, Header: header, Body: http.NoBody, Request: req.Request}, nil
+	}))
+	jar, err := cookiejar.New(nil)
+	assert.Nil(t, err)
+	client.Jar = jar
+	defer client.CloseIdleConnections()
+
+	resp, err := client.Post(server.URL+"/login", "application/json", []byte(`{}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = client.Get(server.URL + "/profile")
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "mocked-session", string(body))
+}
+
+func TestClient_Do_ResolvedResponseLogHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := newTestClient(resolverFunc(func(req *Request) (*http.Response, error) {
+		if req.URL.Path != "/mocked" {
+			return nil, ErrNoMockResponse
+		}
+		return &http.Response{StatusCode: http.StatusTeapot, Body: http.NoBody, Request: req.Request}, nil
+	}))
+
+	var mocked []bool
+	client.ResolvedResponseLogHook = func(logger Logger, resp *http.Response, info ResolveInfo) {
+		mocked = append(mocked, info.Mocked)
+	}
+
+	_, err := client.Get(server.URL + "/mocked")
+	assert.Nil(t, err)
+	_, err = client.Get(server.URL + "/real")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []bool{true, false}, mocked)
+}
+
+func TestClient_Do_KeepConnectionPool(t *testing.T) {
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := newTestClient(noMockResolver{})
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		assert.Nil(t, err)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	assert.Equal(t, int32(1), conns.Load())
+}
+
+func TestClient_Do_SimulatedTimeout(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /slow
+method: GET
+responses:
+  - timeout: true
+    delay: 10
+`)
+	client := newTestClient(resolver)
+
+	var hits int
+	client.OnMockHit(func(definition string, req *Request) { hits++ })
+
+	resp, err := client.Get("http://marketplace.com/slow")
+	assert.Nil(t, resp)
+
+	var netErr net.Error
+	assert.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+	assert.Equal(t, 1, hits)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/William9923/go-mockhttp/convert"
+)
+
+func runConvert(args []string, stdout, stderr io.Writer) error {
+	var (
+		from, out string
+		opts      convert.Options
+	)
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&from, "from", "", "OpenAPI / Swagger spec or Postman collection file to convert (JSON or YAML)")
+	fs.StringVar(&out, "out", "./mocks", "directory to write the mock definition files")
+	fs.StringVar(&opts.Host, "host", "", "host of the definitions (default to the server URL of the spec)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if from == "" {
+		fs.Usage()
+		return errors.New("convert requires --from")
+	}
+
+	spec, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	definitions, err := convert.FromSpec(spec, opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", from, err)
+	}
+	files, err := convert.Write(out, definitions)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "mockhttp: wrote %d mock definition(s) into %s\n", len(files), out)
+	return nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunConvert(t *testing.T) {
+	dir := t.TempDir()
+	spec := "openapi: 3.0.3\nservers:\n  - url: https://api.example.com\npaths:\n  /products:\n    get:\n      responses:\n        \"200\":\n          description: ok\n"
+	from := filepath.Join(dir, "openapi.yaml")
+	assert.Nil(t, os.WriteFile(from, []byte(spec), 0o644))
+	out := filepath.Join(dir, "mocks")
+
+	var stdout bytes.Buffer
+	assert.Nil(t, run([]string{"convert", "--from", from, "--out", out}, &stdout, io.Discard))
+	assert.Equal(t, "mockhttp: wrote 1 mock definition(s) into "+out+"\n", stdout.String())
+	assert.FileExists(t, filepath.Join(out, "get_api.example.com_products.yaml"))
+
+	// the converted definitions pass the validation
+	assert.Nil(t, run([]string{"validate", out}, io.Discard, io.Discard))
+
+	assert.NotNil(t, run([]string{"convert", "--out", out}, io.Discard, io.Discard))
+	assert.NotNil(t, run([]string{"convert", "--from", filepath.Join(dir, "missing.yaml")}, io.Discard, io.Discard))
+}
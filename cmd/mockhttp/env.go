@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envPrefix is the prefix of the environment variables configuring the flags, ex: MOCKHTTP_PORT for --port.
+const envPrefix = "MOCKHTTP_"
+
+// envName returns the environment variable configuring the flag, ex: MOCKHTTP_GRPC_PORT for --grpc-port.
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// setFlagsFromEnv set the flags from their environment variable (when set), so the command can be configured
+// with no argument at all (ex: in docker-compose or Kubernetes manifests).
+// It must be called before parsing the arguments, so the flags given explicitly take precedence.
+func setFlagsFromEnv(fs *flag.FlagSet) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		value, exist := os.LookupEnv(envName(f.Name))
+		if !exist || err != nil {
+			return
+		}
+		if setErr := f.Value.Set(value); setErr != nil {
+			err = fmt.Errorf("invalid value %q for %s: %w", value, envName(f.Name), setErr)
+		}
+	})
+	return err
+}
+
+// envUsage print the flags of the command, along with the environment variables configuring them.
+func envUsage(fs *flag.FlagSet, usage string) func() {
+	return func() {
+		output := fs.Output()
+		fmt.Fprintln(output, usage)
+		fs.PrintDefaults()
+		fmt.Fprintf(output, "\nEvery flag can also be set with %s<FLAG> environment variable (ex: %s for --port),\n", envPrefix, envName("port"))
+		fmt.Fprintln(output, "the flags given explicitly take precedence over the environment variables.")
+	}
+}
@@ -0,0 +1,67 @@
+// Command mockhttp serve the mock definition files over HTTP, so non-Go services
+// and frontend developers can consume the same mock catalog as the Go tests.
+//
+// Usage:
+//
+//	mockhttp serve --dir ./mocks --port 8080
+//	mockhttp validate ./mocks
+//	mockhttp convert --from openapi.yaml --out ./mocks
+//	mockhttp record --target https://api.example.com --out ./mocks
+//
+// The flags of the serve command can also be set with MOCKHTTP_<FLAG> environment variables,
+// ex: MOCKHTTP_DIR=/mocks MOCKHTTP_PORT=8080 mockhttp serve.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `Usage: mockhttp <command> [flags]
+
+Commands:
+  serve       serve the mock definitions over HTTP
+  validate    check the mock definitions, intended as CI gate
+  convert     convert OpenAPI / Swagger spec or Postman collection into mock definitions
+  record      proxy the traffic to the upstream, and record it as mock definitions
+
+Run "mockhttp <command> -h" for the flags of the command.
+`
+
+func main() {
+	err := run(os.Args[1:], os.Stdout, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "mockhttp:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return errors.New("missing command")
+	}
+
+	switch args[0] {
+	case "serve":
+		return runServe(args[1:], stdout, stderr)
+	case "validate":
+		return runValidate(args[1:], stdout, stderr)
+	case "convert":
+		return runConvert(args[1:], stdout, stderr)
+	case "record":
+		return runRecord(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	default:
+		fmt.Fprint(stderr, usage)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	mockhttp "github.com/William9923/go-mockhttp"
+)
+
+type recordConfig struct {
+	target string
+	out    string
+	port   int
+}
+
+func parseRecordFlags(args []string, output io.Writer) (recordConfig, error) {
+	var cfg recordConfig
+
+	fs := flag.NewFlagSet("record", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&cfg.target, "target", "", "base URL of the upstream service to record (ex: https://api.example.com)")
+	fs.StringVar(&cfg.out, "out", "./mocks", "directory to write the recorded mock definition files")
+	fs.IntVar(&cfg.port, "port", 8080, "port to listen on")
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	if cfg.target == "" {
+		fs.Usage()
+		return cfg, errors.New("record requires --target")
+	}
+	return cfg, nil
+}
+
+// passthroughResolver never mock any request, so every request is sent to the upstream and recorded.
+type passthroughResolver struct{}
+
+func (passthroughResolver) LoadDefinition(ctx context.Context) error { return nil }
+
+func (passthroughResolver) Resolve(ctx context.Context, req *mockhttp.Request) (*http.Response, error) {
+	return nil, mockhttp.ErrNoMockResponse
+}
+
+// newRecordHandler returns reverse proxy handler, sending every request to the target
+// and recording the interactions as mock definitions into the out directory.
+func newRecordHandler(cfg recordConfig) (http.Handler, error) {
+	target, err := url.Parse(cfg.target)
+	if err != nil {
+		return nil, err
+	}
+	if !target.IsAbs() || target.Host == "" {
+		return nil, fmt.Errorf("target %q must be absolute URL", cfg.target)
+	}
+	if err := os.MkdirAll(cfg.out, 0o755); err != nil {
+		return nil, err
+	}
+
+	client := mockhttp.NewClient(passthroughResolver{})
+	client.Logger = nil
+	client.Recorder = mockhttp.NewFileRecorder(cfg.out)
+	proxy := mockhttp.NewForwardProxy(client)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied := r.Clone(r.Context())
+		proxied.URL = target.JoinPath(r.URL.Path)
+		proxied.URL.RawQuery = r.URL.RawQuery
+		proxied.Host = target.Host
+		proxy.ServeHTTP(w, proxied)
+	}), nil
+}
+
+func runRecord(args []string, stdout, stderr io.Writer) error {
+	cfg, err := parseRecordFlags(args, stderr)
+	if err != nil {
+		return err
+	}
+
+	handler, err := newRecordHandler(cfg)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr:              net.JoinHostPort("", strconv.Itoa(cfg.port)),
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	fmt.Fprintf(stdout, "mockhttp: recording %s into %s on %s\n", cfg.target, cfg.out, server.Addr)
+	return server.ListenAndServe()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordHandler(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"path":"` + r.URL.Path + `","query":"` + r.URL.RawQuery + `"}`))
+	}))
+	defer upstream.Close()
+
+	out := filepath.Join(t.TempDir(), "mocks")
+	handler, err := newRecordHandler(recordConfig{target: upstream.URL + "/api", out: out})
+	assert.Nil(t, err)
+	recorder := httptest.NewServer(handler)
+	defer recorder.Close()
+
+	resp, err := http.Get(recorder.URL + "/products?page=1")
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"path":"/api/products","query":"page=1"}`, string(body))
+
+	files, err := os.ReadDir(out)
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+
+	// the recorded definitions pass the validation
+	assert.Nil(t, run([]string{"validate", out}, io.Discard, io.Discard))
+}
+
+func TestParseRecordFlags(t *testing.T) {
+	cfg, err := parseRecordFlags([]string{"--target", "https://api.example.com"}, io.Discard)
+	assert.Nil(t, err)
+	assert.Equal(t, recordConfig{target: "https://api.example.com", out: "./mocks", port: 8080}, cfg)
+
+	_, err = parseRecordFlags(nil, io.Discard)
+	assert.NotNil(t, err)
+
+	_, err = newRecordHandler(recordConfig{target: "/api", out: t.TempDir()})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	mockhttp "github.com/William9923/go-mockhttp"
+	"github.com/William9923/go-mockhttp/grpcadmin"
+	"github.com/William9923/go-mockhttp/grpcadmin/adminpb"
+)
+
+// serveJournalLimit bound the memory used by the journal of the long-running mock server.
+const serveJournalLimit = 1000
+
+type serveConfig struct {
+	dir         string
+	port        int
+	host        string
+	passthrough string
+	grpcPort    int
+	tlsCert     string
+	tlsKey      string
+	tlsClientCA string
+
+	shutdownTimeout time.Duration
+}
+
+func parseServeFlags(args []string, output io.Writer) (serveConfig, error) {
+	var cfg serveConfig
+
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&cfg.dir, "dir", "./mocks", "directory of the mock definition files")
+	fs.IntVar(&cfg.port, "port", 8080, "port to listen on")
+	fs.StringVar(&cfg.host, "host", "", "resolve every request as if it was sent to host (default to the request Host header)")
+	fs.StringVar(&cfg.passthrough, "passthrough", "", "base URL of the upstream to pass the requests without mock response through (default to 404 Not Found)")
+	fs.IntVar(&cfg.grpcPort, "grpc-port", 0, "port to serve the gRPC admin API on (disabled by default)")
+	fs.StringVar(&cfg.tlsCert, "tls-cert", "", "PEM certificate file to serve HTTPS (and the gRPC admin API over TLS), along with --tls-key")
+	fs.StringVar(&cfg.tlsKey, "tls-key", "", "PEM private key file of the --tls-cert certificate")
+	fs.StringVar(&cfg.tlsClientCA, "tls-client-ca", "", "PEM CA certificates file to require and verify the client certificates with (mTLS)")
+	fs.DurationVar(&cfg.shutdownTimeout, "shutdown-timeout", 30*time.Second, "max time to wait for the in-flight requests on SIGINT / SIGTERM, before closing the connections")
+	fs.Usage = envUsage(fs, "Usage: mockhttp serve [flags]")
+	if err := setFlagsFromEnv(fs); err != nil {
+		return cfg, err
+	}
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	if fs.NArg() > 0 {
+		return cfg, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if (cfg.tlsCert == "") != (cfg.tlsKey == "") {
+		return cfg, errors.New("--tls-cert and --tls-key must be set together")
+	}
+	if cfg.tlsClientCA != "" && cfg.tlsCert == "" {
+		return cfg, errors.New("--tls-client-ca requires --tls-cert and --tls-key")
+	}
+	return cfg, nil
+}
+
+// tlsConfig load the certificate (and the client CA certificates for mTLS) of the server,
+// nil when the server is not configured to serve TLS.
+func (cfg serveConfig) tlsConfig() (*tls.Config, error) {
+	if cfg.tlsCert == "" {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(cfg.tlsCert, cfg.tlsKey)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if cfg.tlsClientCA != "" {
+		pem, err := os.ReadFile(cfg.tlsClientCA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificate found in %s", cfg.tlsClientCA)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// mockServer is the standalone mock server: the resolver loaded from the directory,
+// and the client serving every request, so the served requests are journaled.
+type mockServer struct {
+	resolver mockhttp.ResolverAdapter
+	client   *mockhttp.Client
+	handler  http.Handler
+}
+
+// newMockServer load the mock definitions of the directory, and build the handler serving them,
+// along with the admin and stub management API mounted on mockhttp.AdminPath, and the web UI on mockhttp.AdminPath/ui/.
+func newMockServer(ctx context.Context, cfg serveConfig) (*mockServer, error) {
+	resolver, err := mockhttp.NewFileResolverAdapter(cfg.dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := resolver.LoadDefinition(ctx); err != nil {
+		return nil, err
+	}
+
+	client := mockhttp.NewClient(resolver)
+	client.MockOnly = true
+	client.JournalLimit = serveJournalLimit
+
+	var opts []mockhttp.ServerOption
+	if cfg.host != "" {
+		opts = append(opts, mockhttp.WithServerHost(cfg.host))
+	}
+	if cfg.passthrough != "" {
+		target, err := url.Parse(cfg.passthrough)
+		if err != nil {
+			return nil, err
+		}
+		if !target.IsAbs() || target.Host == "" {
+			return nil, fmt.Errorf("passthrough %q must be absolute URL", cfg.passthrough)
+		}
+		proxy := httputil.NewSingleHostReverseProxy(target)
+		director := proxy.Director
+		proxy.Director = func(r *http.Request) {
+			director(r)
+			r.Host = target.Host
+		}
+		opts = append(opts, mockhttp.WithMissHandler(proxy))
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", mockhttp.ClientHandler(client, opts...))
+	mux.Handle(mockhttp.AdminPath+"/", http.StripPrefix(mockhttp.AdminPath, mockhttp.NewAdminHandler(client)))
+	mux.Handle(mockhttp.AdminPath+"/ui/", http.StripPrefix(mockhttp.AdminPath+"/ui", mockhttp.NewDashboardHandler(client)))
+	if manager, ok := resolver.(mockhttp.StubManager); ok {
+		stubs := http.StripPrefix(mockhttp.AdminPath, mockhttp.NewStubHandler(manager))
+		mux.Handle(mockhttp.AdminPath+"/stubs", stubs)
+		mux.Handle(mockhttp.AdminPath+"/stubs/", stubs)
+	}
+	return &mockServer{resolver: resolver, client: client, handler: mux}, nil
+}
+
+func runServe(args []string, stdout, stderr io.Writer) error {
+	cfg, err := parseServeFlags(args, stderr)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(cfg.port)))
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return serve(ctx, cfg, listener, stdout)
+}
+
+// serve the mock server on the listener (and the gRPC admin API on cfg.grpcPort) until the context is done,
+// then shut down gracefully: stop accepting new requests, and wait up to cfg.shutdownTimeout for the in-flight
+// requests (including the long mock delays) and the pending callbacks to be served, before closing the remaining connections.
+func serve(ctx context.Context, cfg serveConfig, listener net.Listener, stdout io.Writer) error {
+	defer listener.Close()
+
+	mock, err := newMockServer(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return err
+	}
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+
+	errCh := make(chan error, 2)
+	var grpcServer *grpc.Server
+	if cfg.grpcPort > 0 {
+		grpcListener, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(cfg.grpcPort)))
+		if err != nil {
+			return err
+		}
+		var grpcOpts []grpc.ServerOption
+		if tlsConfig != nil {
+			grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+		grpcServer = grpc.NewServer(grpcOpts...)
+		adminpb.RegisterAdminServiceServer(grpcServer, grpcadmin.NewServer(mock.resolver, mock.client))
+		fmt.Fprintf(stdout, "mockhttp: serving gRPC admin API on %s\n", grpcListener.Addr())
+		go func() { errCh <- grpcServer.Serve(grpcListener) }()
+	}
+
+	server := &http.Server{
+		Handler:           mock.handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         tlsConfig,
+	}
+	fmt.Fprintf(stdout, "mockhttp: serving mock definitions of %s on %s (%s)\n", cfg.dir, listener.Addr(), scheme)
+	fmt.Fprintf(stdout, "mockhttp: dashboard available on %s://localhost:%d%s/ui/\n", scheme, cfg.port, mockhttp.AdminPath)
+	go func() {
+		if tlsConfig != nil {
+			// the certificate is already loaded in the TLS config
+			errCh <- server.ServeTLS(listener, "", "")
+			return
+		}
+		errCh <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		// either server failed, stop the other one as well
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+		server.Close()
+		return err
+	case <-ctx.Done():
+	}
+
+	fmt.Fprintln(stdout, "mockhttp: shutting down, waiting for the in-flight requests")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.shutdownTimeout)
+	defer cancel()
+
+	if grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		defer func() {
+			select {
+			case <-stopped:
+			case <-shutdownCtx.Done():
+				grpcServer.Stop()
+			}
+		}()
+	}
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+		return fmt.Errorf("in-flight requests not served within %s: %w", cfg.shutdownTimeout, err)
+	}
+	if drainer, ok := mock.resolver.(mockhttp.CallbackDrainer); ok {
+		if err := drainer.DrainCallbacks(shutdownCtx); err != nil {
+			return fmt.Errorf("pending callbacks not delivered within %s: %w", cfg.shutdownTimeout, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	mockhttp "github.com/William9923/go-mockhttp"
+)
+
+func TestParseServeFlags(t *testing.T) {
+	cfg, err := parseServeFlags(nil, io.Discard)
+	assert.Nil(t, err)
+	assert.Equal(t, serveConfig{dir: "./mocks", port: 8080, shutdownTimeout: 30 * time.Second}, cfg)
+
+	cfg, err = parseServeFlags([]string{"--dir", "./testdata", "--port", "9090", "--host", "marketplace.com"}, io.Discard)
+	assert.Nil(t, err)
+	assert.Equal(t, serveConfig{dir: "./testdata", port: 9090, host: "marketplace.com", shutdownTimeout: 30 * time.Second}, cfg)
+
+	_, err = parseServeFlags([]string{"--port", "http"}, io.Discard)
+	assert.NotNil(t, err)
+
+	_, err = parseServeFlags([]string{"./mocks"}, io.Discard)
+	assert.NotNil(t, err)
+}
+
+func TestParseServeFlags_Env(t *testing.T) {
+	t.Setenv("MOCKHTTP_DIR", "/etc/mocks")
+	t.Setenv("MOCKHTTP_PORT", "9090")
+	t.Setenv("MOCKHTTP_PASSTHROUGH", "https://marketplace.com")
+	t.Setenv("MOCKHTTP_GRPC_PORT", "9091")
+
+	cfg, err := parseServeFlags(nil, io.Discard)
+	assert.Nil(t, err)
+	assert.Equal(t, serveConfig{dir: "/etc/mocks", port: 9090, passthrough: "https://marketplace.com", grpcPort: 9091, shutdownTimeout: 30 * time.Second}, cfg)
+
+	// the flags given explicitly take precedence
+	cfg, err = parseServeFlags([]string{"--port", "8081"}, io.Discard)
+	assert.Nil(t, err)
+	assert.Equal(t, 8081, cfg.port)
+	assert.Equal(t, "/etc/mocks", cfg.dir)
+
+	t.Setenv("MOCKHTTP_PORT", "http")
+	_, err = parseServeFlags(nil, io.Discard)
+	assert.ErrorContains(t, err, "MOCKHTTP_PORT")
+}
+
+func TestParseServeFlags_TLS(t *testing.T) {
+	cfg, err := parseServeFlags([]string{"--tls-cert", "server.pem", "--tls-key", "server-key.pem", "--tls-client-ca", "ca.pem"}, io.Discard)
+	assert.Nil(t, err)
+	assert.Equal(t, serveConfig{dir: "./mocks", port: 8080, tlsCert: "server.pem", tlsKey: "server-key.pem", tlsClientCA: "ca.pem", shutdownTimeout: 30 * time.Second}, cfg)
+
+	_, err = parseServeFlags([]string{"--tls-cert", "server.pem"}, io.Discard)
+	assert.NotNil(t, err)
+
+	_, err = parseServeFlags([]string{"--tls-client-ca", "ca.pem"}, io.Discard)
+	assert.NotNil(t, err)
+
+	tlsConfig, err := serveConfig{}.tlsConfig()
+	assert.Nil(t, err)
+	assert.Nil(t, tlsConfig)
+
+	_, err = serveConfig{tlsCert: "missing.pem", tlsKey: "missing-key.pem"}.tlsConfig()
+	assert.NotNil(t, err)
+}
+
+func TestServeHandler_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newTestCertificate(t, nil, nil)
+	serverCert, serverKey := newTestCertificate(t, ca, caKey)
+	clientCert, clientKey := newTestCertificate(t, ca, caKey)
+	cfg := serveConfig{
+		dir:         dir,
+		tlsCert:     writeTestPEM(t, dir, "server.pem", "CERTIFICATE", serverCert.Raw),
+		tlsKey:      writeTestPEM(t, dir, "server-key.pem", "EC PRIVATE KEY", marshalTestKey(t, serverKey)),
+		tlsClientCA: writeTestPEM(t, dir, "ca.pem", "CERTIFICATE", ca.Raw),
+	}
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "products.yaml"), []byte(`
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+    response_body: '[]'
+`), 0o644))
+
+	mock, err := newMockServer(context.Background(), serveConfig{dir: dir, host: "marketplace.com"})
+	assert.Nil(t, err)
+	mock.client.Logger = nil
+	tlsConfig, err := cfg.tlsConfig()
+	assert.Nil(t, err)
+
+	server := httptest.NewUnstartedServer(mock.handler)
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	newClient := func(certificates ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: certificates,
+			MinVersion:   tls.VersionTLS12,
+		}}}
+	}
+
+	// client certificate is required
+	_, err = newClient().Get(server.URL + "/products")
+	assert.NotNil(t, err)
+
+	resp, err := newClient(tls.Certificate{Certificate: [][]byte{clientCert.Raw}, PrivateKey: clientKey}).Get(server.URL + "/products")
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "[]", string(body))
+	assert.Equal(t, "https", mock.client.Journal()[0].URL.Scheme)
+}
+
+// newTestCertificate creates certificate for 127.0.0.1 signed by the parent certificate,
+// or self-signed CA certificate when parent is nil.
+func newTestCertificate(t *testing.T, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "mockhttp test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+	return cert, key
+}
+
+func marshalTestKey(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	return der
+}
+
+func writeTestPEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	assert.Nil(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	return path
+}
+
+func TestServeHandler(t *testing.T) {
+	dir := t.TempDir()
+	definition := `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+    response_body: '[]'
+`
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "products.yaml"), []byte(definition), 0o644))
+
+	mock, err := newMockServer(context.Background(), serveConfig{dir: dir, host: "marketplace.com"})
+	assert.Nil(t, err)
+	mock.client.Logger = nil
+	server := httptest.NewServer(mock.handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/products")
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "[]", string(body))
+
+	// stub the endpoint at runtime via the stub management API
+	stub := strings.Replace(definition, "'[]'", "'[{\"id\":1}]'", 1)
+	req, err := http.NewRequest(http.MethodPut, server.URL+mockhttp.AdminPath+"/stubs/products", strings.NewReader(stub))
+	assert.Nil(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/products")
+	assert.Nil(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, `[{"id":1}]`, string(body))
+	assert.Len(t, mock.client.Journal(), 2)
+
+	// unmatched requests are listed by the admin API
+	resp, err = http.Get(server.URL + "/orders")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + mockhttp.AdminPath + "/unmatched")
+	assert.Nil(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(body), "http://marketplace.com/orders")
+
+	// the web UI is served along the admin API
+	resp, err = http.Get(server.URL + mockhttp.AdminPath + "/ui/requests")
+	assert.Nil(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), "http://marketplace.com/orders")
+
+	_, err = newMockServer(context.Background(), serveConfig{dir: filepath.Join(dir, "missing")})
+	assert.NotNil(t, err)
+}
+
+func TestServeHandler_Passthrough(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("upstream " + r.URL.Path))
+	}))
+	defer upstream.Close()
+
+	mock, err := newMockServer(context.Background(), serveConfig{dir: t.TempDir(), passthrough: upstream.URL})
+	assert.Nil(t, err)
+	mock.client.Logger = nil
+	server := httptest.NewServer(mock.handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/orders")
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "upstream /orders", string(body))
+
+	_, err = newMockServer(context.Background(), serveConfig{dir: t.TempDir(), passthrough: "/orders"})
+	assert.NotNil(t, err)
+}
+
+func TestServe_GracefulShutdown(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "products.yaml"), []byte(`
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+    delay: 300
+    response_body: '[]'
+`), 0o644))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, serveConfig{dir: dir, host: "marketplace.com", shutdownTimeout: 5 * time.Second}, listener, io.Discard)
+	}()
+
+	responded := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/products")
+		if err != nil {
+			responded <- err.Error()
+			return
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		responded <- string(body)
+	}()
+
+	// shut down while the mock response is delayed, the in-flight request is still served
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	assert.Equal(t, "[]", <-responded)
+	assert.Nil(t, <-served)
+
+	_, err = http.Get("http://" + listener.Addr().String() + "/products")
+	assert.NotNil(t, err)
+}
+
+func TestServe_DrainCallbacks(t *testing.T) {
+	delivered := make(chan struct{}, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered <- struct{}{}
+	}))
+	defer webhook.Close()
+
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "orders.yaml"), []byte(`
+host: marketplace.com
+path: /orders
+method: POST
+responses:
+  - status_code: 202
+    callbacks:
+      - url: `+webhook.URL+`/webhook
+        delay: 300
+`), 0o644))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, serveConfig{dir: dir, host: "marketplace.com", shutdownTimeout: 5 * time.Second}, listener, io.Discard)
+	}()
+
+	resp, err := http.Post("http://"+listener.Addr().String()+"/orders", "application/json", nil)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	// shut down while the callback is delayed, the pending callback is still delivered
+	cancel()
+	assert.Nil(t, <-served)
+	select {
+	case <-delivered:
+	default:
+		t.Fatal("pending callback was not delivered before shutdown")
+	}
+}
+
+func TestServe_ShutdownTimeout(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "products.yaml"), []byte(`
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+    delay: 5000
+`), 0o644))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, serveConfig{dir: dir, host: "marketplace.com", shutdownTimeout: 100 * time.Millisecond}, listener, io.Discard)
+	}()
+	go func() {
+		if resp, err := http.Get("http://" + listener.Addr().String() + "/products"); err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-served, context.DeadlineExceeded)
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	assert.NotNil(t, run(nil, io.Discard, io.Discard))
+	assert.NotNil(t, run([]string{"deploy"}, io.Discard, io.Discard))
+	assert.Nil(t, run([]string{"help"}, io.Discard, io.Discard))
+}
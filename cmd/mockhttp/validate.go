@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	mockhttp "github.com/William9923/go-mockhttp"
+)
+
+func runValidate(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mockhttp validate <dir>")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("validate requires exactly one directory")
+	}
+	dir := fs.Arg(0)
+
+	resolver, err := mockhttp.NewFileResolverAdapter(dir)
+	if err != nil {
+		return err
+	}
+	validator, ok := resolver.(mockhttp.Validator)
+	if !ok {
+		return errors.New("resolver does not support validation")
+	}
+
+	problems := validator.Validate(context.Background())
+	for _, problem := range problems {
+		if problem.File != dir {
+			problem.File = filepath.Join(dir, problem.File)
+		}
+		fmt.Fprintln(stdout, problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problem(s) found in %s", len(problems), dir)
+	}
+	fmt.Fprintf(stdout, "mockhttp: all mock definitions of %s are valid\n", dir)
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunValidate(t *testing.T) {
+	dir := t.TempDir()
+	valid := "host: marketplace.com\npath: /products\nmethod: GET\nresponses:\n  - status_code: 200\n"
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "products.yaml"), []byte(valid), 0o644))
+
+	var stdout bytes.Buffer
+	assert.Nil(t, run([]string{"validate", dir}, &stdout, io.Discard))
+	assert.Contains(t, stdout.String(), "are valid")
+
+	invalid := "host: marketplace.com\npath: orders\nmethod: GET\nresponses:\n  - status_code: 200\n"
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "orders.yaml"), []byte(invalid), 0o644))
+
+	stdout.Reset()
+	err := run([]string{"validate", dir}, &stdout, io.Discard)
+	assert.EqualError(t, err, "1 problem(s) found in "+dir)
+	assert.Equal(t, filepath.Join(dir, "orders.yaml")+`:2: path: invalid mock definition: path "orders" must start with /`+"\n", stdout.String())
+
+	assert.NotNil(t, run([]string{"validate"}, io.Discard, io.Discard))
+}
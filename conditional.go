@@ -0,0 +1,59 @@
+package mockhttp
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// isNotModified check whether the incoming request conditional headers (If-None-Match / If-Modified-Since)
+// match the validators (ETag / Last-Modified) defined on the mock response,
+// following RFC 7232 evaluation order:
+//  1. If-None-Match is evaluated first, using weak comparison
+//  2. If-Modified-Since is only evaluated when If-None-Match is absent
+//
+// Only successful (2xx) response to GET and HEAD request can be replaced with 304 Not Modified.
+func isNotModified(request *incomingRequest, response *mockResponse) bool {
+	if !in[string](request.Method, []string{http.MethodGet, http.MethodHead}) {
+		return false
+	}
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return false
+	}
+
+	if ifNoneMatch, exist := request.Headers["If-None-Match"]; exist {
+		if response.ETag == "" {
+			return false
+		}
+		return matchETag(ifNoneMatch, response.ETag)
+	}
+
+	ifModifiedSince, exist := request.Headers["If-Modified-Since"]
+	if !exist || response.LastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	lastModified, err := http.ParseTime(response.LastModified)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// matchETag check whether any of the entity tags listed in If-None-Match header
+// weakly match the current entity tag.
+//
+// ex:
+// If-None-Match: "v1", W/"v2" & ETag: "v2" => true
+// If-None-Match: *            & ETag: "v2" => true
+// If-None-Match: "v1"         & ETag: "v2" => false
+func matchETag(ifNoneMatch string, etag string) bool {
+	current := strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	return some[string](strings.Split(ifNoneMatch, ","), func(candidate string) bool {
+		candidate = strings.TrimSpace(candidate)
+		return candidate == "*" || strings.TrimPrefix(candidate, "W/") == current
+	})
+}
@@ -0,0 +1,77 @@
+package mockhttp
+
+import "testing"
+
+func Test_isNotModified(t *testing.T) {
+	response := &mockResponse{
+		StatusCode:   200,
+		ETag:         `"v2"`,
+		LastModified: "Wed, 21 Oct 2015 07:28:00 GMT",
+	}
+	tests := []struct {
+		name    string
+		method  string
+		headers params
+		want    bool
+	}{
+		{
+			"matching etag",
+			"GET",
+			params{"If-None-Match": `"v1", W/"v2"`},
+			true,
+		},
+		{
+			"wildcard etag",
+			"HEAD",
+			params{"If-None-Match": `*`},
+			true,
+		},
+		{
+			"stale etag takes precedence over if-modified-since",
+			"GET",
+			params{"If-None-Match": `"v1"`, "If-Modified-Since": "Thu, 22 Oct 2015 07:28:00 GMT"},
+			false,
+		},
+		{
+			"not modified since",
+			"GET",
+			params{"If-Modified-Since": "Wed, 21 Oct 2015 07:28:00 GMT"},
+			true,
+		},
+		{
+			"modified since",
+			"GET",
+			params{"If-Modified-Since": "Tue, 20 Oct 2015 07:28:00 GMT"},
+			false,
+		},
+		{
+			"non cacheable method",
+			"POST",
+			params{"If-None-Match": `"v2"`},
+			false,
+		},
+		{
+			"no conditional header",
+			"GET",
+			params{},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &incomingRequest{Method: tt.method, Headers: tt.headers}
+			if got := isNotModified(request, response); got != tt.want {
+				t.Errorf("isNotModified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("non successful response", func(t *testing.T) {
+		request := &incomingRequest{Method: "GET", Headers: params{"If-None-Match": `"v2"`}}
+		for _, statusCode := range []int{301, 404, 500} {
+			if isNotModified(request, &mockResponse{StatusCode: statusCode, ETag: `"v2"`}) {
+				t.Errorf("isNotModified() = true for status %d, want false", statusCode)
+			}
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package mockhttp
+
+import "net/http"
+
+// ContentTypePolicy decide the Content-Type of the mock response that defines no Content-Type header
+// (nor response schema, always served as application/json).
+type ContentTypePolicy int
+
+const (
+	// ContentTypeSniff detect the content type from the response body (see http.DetectContentType),
+	// JSON body is usually detected as text/plain. It is the default policy.
+	ContentTypeSniff ContentTypePolicy = iota
+	// ContentTypeFixed always use the configured content type (see WithContentTypeFallback), ex: application/json.
+	ContentTypeFixed
+	// ContentTypeNone leave the mock response without Content-Type header.
+	ContentTypeNone
+)
+
+// fileBasedResolver fallbackContentType
+// Return the content type of the mock response body without Content-Type header, based on the resolver policy.
+// Empty content type means the header is not set.
+func (r *fileBasedResolver) fallbackContentType(body string) string {
+	switch r.contentTypePolicy {
+	case ContentTypeFixed:
+		return r.contentType
+	case ContentTypeNone:
+		return ""
+	default:
+		return http.DetectContentType([]byte(body))
+	}
+}
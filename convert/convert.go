@@ -0,0 +1,130 @@
+// Package convert turns API specs (OpenAPI / Swagger) and Postman collections into mockhttp mock definition files,
+// to bootstrap the mock catalog of an existing API without writing every definition by hand.
+package convert
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnknownFormat is returned by FromSpec when the document is neither OpenAPI / Swagger spec nor Postman collection.
+var ErrUnknownFormat = errors.New("unknown spec format, expecting OpenAPI / Swagger spec or Postman collection")
+
+// Definition is the mock definition spec written by the converter, see the Mock Definition docs for the fields.
+type Definition struct {
+	Host      string     `yaml:"host"`
+	Path      string     `yaml:"path"`
+	Method    string     `yaml:"method"`
+	Desc      string     `yaml:"desc,omitempty"`
+	Responses []Response `yaml:"responses"`
+}
+
+// Response is the mock response of the converted definition.
+type Response struct {
+	Rules           []string          `yaml:"rules,omitempty"`
+	StatusCode      int               `yaml:"status_code"`
+	ResponseHeaders map[string]string `yaml:"response_headers,omitempty"`
+	Body            string            `yaml:"response_body,omitempty"`
+}
+
+// Options configure the conversion.
+type Options struct {
+	// Host override the host of every definition, required when the spec does not define any (absolute) server URL.
+	Host string
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// FileName build the definition file name from the method, host and path.
+//
+// ex: GET api.example.com/products/:id => get_api.example.com_products_id.yaml
+func (d Definition) FileName() string {
+	name := strings.ToLower(d.Method) + "_" + d.Host + d.Path
+	return strings.Trim(unsafeFileNameChars.ReplaceAllString(name, "_"), "_") + ".yaml"
+}
+
+// FromSpec detects the format of the document (OpenAPI / Swagger spec or Postman collection, in JSON or YAML),
+// and converts it into mock definitions.
+func FromSpec(data []byte, opts Options) ([]Definition, error) {
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case document["openapi"] != nil || document["swagger"] != nil:
+		return FromOpenAPI(data, opts)
+	case document["item"] != nil:
+		return FromPostman(data, opts)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// Write writes every definition as YAML file into the directory (created when not exist), one file per definition,
+// and returns the written file names sorted. Existing files are overwritten.
+//
+// The definitions mapped to the same file name (ex: /users/{id} and /users/id, or duplicate Postman items)
+// are disambiguated with numbered suffix, ex: get_api.example.com_users_id_2.yaml.
+func Write(dir string, definitions []Definition) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(definitions))
+	// used is the written file names, compared case insensitively for case insensitive file systems
+	used := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		content, err := yaml.Marshal(definition)
+		if err != nil {
+			return nil, err
+		}
+		name := uniqueFileName(definition.FileName(), used)
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			return nil, err
+		}
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// uniqueFileName returns the file name, suffixed with the lowest number (from 2) not used yet,
+// and mark it as used.
+func uniqueFileName(name string, used map[string]bool) string {
+	base := strings.TrimSuffix(name, ".yaml")
+	for idx := 2; used[strings.ToLower(name)]; idx++ {
+		name = fmt.Sprintf("%s_%d.yaml", base, idx)
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+// pathParam match the path template parameter of OpenAPI (ex: {id}) and Postman variable (ex: {{id}}).
+var pathParam = regexp.MustCompile(`\{\{?([^{}/]+)\}?\}`)
+
+// convertPath converts the path template parameters into mockhttp path params (ex: /products/{id} => /products/:id).
+func convertPath(path string) string {
+	path = pathParam.ReplaceAllString(path, ":$1")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+// definitionHost returns the host override, or the host found in the spec.
+func definitionHost(opts Options, host string) (string, error) {
+	if opts.Host != "" {
+		return opts.Host, nil
+	}
+	if host == "" || strings.Contains(host, "{") {
+		return "", fmt.Errorf("unable to find the host of the definitions (%q), set the host explicitly", host)
+	}
+	return host, nil
+}
@@ -0,0 +1,256 @@
+package convert
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	mockhttp "github.com/William9923/go-mockhttp"
+)
+
+const openAPISpecYAML = `
+openapi: 3.0.3
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /products/{id}:
+    get:
+      summary: Get product
+      responses:
+        "404":
+          description: not found
+        "200":
+          description: product
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Product'
+    delete:
+      operationId: deleteProduct
+      responses:
+        "204":
+          description: deleted
+  /health:
+    get:
+      responses:
+        default:
+          description: health
+          content:
+            text/plain:
+              example: ok
+components:
+  schemas:
+    Product:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+          example: 1
+        name:
+          type: string
+          example: Shoes
+`
+
+func TestFromOpenAPI(t *testing.T) {
+	definitions, err := FromOpenAPI([]byte(openAPISpecYAML), Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, []Definition{
+		{
+			Host:   "api.example.com",
+			Path:   "/v1/health",
+			Method: "GET",
+			Responses: []Response{{
+				StatusCode:      200,
+				ResponseHeaders: map[string]string{"Content-Type": "text/plain"},
+				Body:            "ok",
+			}},
+		},
+		{
+			Host:   "api.example.com",
+			Path:   "/v1/products/:id",
+			Method: "GET",
+			Desc:   "Get product",
+			Responses: []Response{{
+				StatusCode:      200,
+				ResponseHeaders: map[string]string{"Content-Type": "application/json"},
+				Body:            `{"id":1,"name":"Shoes"}`,
+			}},
+		},
+		{
+			Host:      "api.example.com",
+			Path:      "/v1/products/:id",
+			Method:    "DELETE",
+			Desc:      "deleteProduct",
+			Responses: []Response{{StatusCode: 204}},
+		},
+	}, definitions)
+}
+
+func TestFromOpenAPI_Swagger(t *testing.T) {
+	spec := `{
+  "swagger": "2.0",
+  "host": "api.example.com",
+  "basePath": "/v2",
+  "paths": {
+    "/orders": {
+      "post": {
+        "responses": {
+          "201": {"schema": {"$ref": "#/definitions/Order"}}
+        }
+      }
+    }
+  },
+  "definitions": {
+    "Order": {"type": "object", "properties": {"status": {"enum": ["created"]}}}
+  }
+}`
+	definitions, err := FromSpec([]byte(spec), Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, []Definition{{
+		Host:   "api.example.com",
+		Path:   "/v2/orders",
+		Method: "POST",
+		Responses: []Response{{
+			StatusCode:      201,
+			ResponseHeaders: map[string]string{"Content-Type": "application/json"},
+			Body:            `{"status":"created"}`,
+		}},
+	}}, definitions)
+}
+
+func TestFromOpenAPI_MissingHost(t *testing.T) {
+	spec := "openapi: 3.0.3\nservers:\n  - url: /api\npaths:\n  /products:\n    get:\n      responses:\n        \"200\":\n          description: ok\n"
+
+	_, err := FromOpenAPI([]byte(spec), Options{})
+	assert.NotNil(t, err)
+
+	definitions, err := FromOpenAPI([]byte(spec), Options{Host: "localhost:8080"})
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost:8080", definitions[0].Host)
+	assert.Equal(t, "/api/products", definitions[0].Path)
+}
+
+const postmanCollectionJSON = `{
+  "info": {"name": "Marketplace", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+  "variable": [{"key": "baseUrl", "value": "https://marketplace.com"}],
+  "item": [
+    {
+      "name": "Products",
+      "item": [
+        {
+          "name": "List products",
+          "request": {"method": "GET", "url": {"raw": "{{baseUrl}}/products?page=1"}},
+          "response": [
+            {
+              "code": 200,
+              "header": [{"key": "content-type", "value": "application/json"}, {"key": "Date", "value": "Mon, 01 Jan 2024 00:00:00 GMT"}],
+              "body": "[{\"id\":1}]",
+              "originalRequest": {"method": "GET", "url": "{{baseUrl}}/products"}
+            },
+            {
+              "code": 200,
+              "body": "[{\"id\":2}]",
+              "originalRequest": {"method": "GET", "url": {"raw": "{{baseUrl}}/products?page=2"}}
+            }
+          ]
+        }
+      ]
+    },
+    {
+      "name": "Get product",
+      "request": {"method": "get", "url": "{{baseUrl}}/products/{{productId}}"}
+    }
+  ]
+}`
+
+func TestFromPostman(t *testing.T) {
+	definitions, err := FromSpec([]byte(postmanCollectionJSON), Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, []Definition{
+		{
+			Host:   "marketplace.com",
+			Path:   "/products",
+			Method: "GET",
+			Desc:   "List products",
+			Responses: []Response{
+				{Rules: []string{`rawQuery == "page=2"`}, StatusCode: 200, Body: `[{"id":2}]`},
+				{StatusCode: 200, ResponseHeaders: map[string]string{"Content-Type": "application/json"}, Body: `[{"id":1}]`},
+			},
+		},
+		{
+			Host:      "marketplace.com",
+			Path:      "/products/:productId",
+			Method:    "GET",
+			Desc:      "Get product",
+			Responses: []Response{{StatusCode: 200}},
+		},
+	}, definitions)
+}
+
+func TestFromSpec_UnknownFormat(t *testing.T) {
+	_, err := FromSpec([]byte(`{"name": "not a spec"}`), Options{})
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestWrite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mocks")
+	definitions, err := FromOpenAPI([]byte(openAPISpecYAML), Options{})
+	assert.Nil(t, err)
+
+	files, err := Write(dir, definitions)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"delete_api.example.com_v1_products_id.yaml",
+		"get_api.example.com_v1_health.yaml",
+		"get_api.example.com_v1_products_id.yaml",
+	}, files)
+
+	content, err := os.ReadFile(filepath.Join(dir, "get_api.example.com_v1_health.yaml"))
+	assert.Nil(t, err)
+	var written Definition
+	assert.Nil(t, yaml.Unmarshal(content, &written))
+	assert.Equal(t, definitions[0], written)
+
+	// the written definitions are served by the file resolver
+	resolver, err := mockhttp.NewFileResolverAdapter(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, resolver.LoadDefinition(context.Background()))
+}
+
+func TestWrite_FileNameCollision(t *testing.T) {
+	dir := t.TempDir()
+	definitions := []Definition{
+		{Host: "api.example.com", Path: "/users/:id", Method: "GET", Responses: []Response{{StatusCode: 200}}},
+		{Host: "api.example.com", Path: "/users/id", Method: "GET", Responses: []Response{{StatusCode: 200}}},
+		{Host: "api.example.com", Path: "/users/:id", Method: "GET", Responses: []Response{{StatusCode: 404}}},
+		{Host: "api.example.com", Path: "/Users/id", Method: "GET", Responses: []Response{{StatusCode: 200}}},
+	}
+
+	files, err := Write(dir, definitions)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"get_api.example.com_Users_id_4.yaml",
+		"get_api.example.com_users_id.yaml",
+		"get_api.example.com_users_id_2.yaml",
+		"get_api.example.com_users_id_3.yaml",
+	}, files)
+
+	// every definition is written into its own file
+	for idx, name := range []string{
+		"get_api.example.com_users_id.yaml",
+		"get_api.example.com_users_id_2.yaml",
+		"get_api.example.com_users_id_3.yaml",
+		"get_api.example.com_Users_id_4.yaml",
+	} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		assert.Nil(t, err)
+		var written Definition
+		assert.Nil(t, yaml.Unmarshal(content, &written))
+		assert.Equal(t, definitions[idx], written)
+	}
+}
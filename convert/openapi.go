@@ -0,0 +1,245 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/William9923/go-mockhttp/jsonschema"
+)
+
+type openAPISpec struct {
+	// OpenAPI 3.x
+	Servers    []struct{ URL string } `yaml:"servers"`
+	Components struct {
+		Schemas map[string]interface{} `yaml:"schemas"`
+	} `yaml:"components"`
+
+	// Swagger 2.0
+	Host        string                 `yaml:"host"`
+	BasePath    string                 `yaml:"basePath"`
+	Definitions map[string]interface{} `yaml:"definitions"`
+
+	Paths map[string]map[string]yaml.Node `yaml:"paths"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `yaml:"operationId"`
+	Summary     string                     `yaml:"summary"`
+	Responses   map[string]openAPIResponse `yaml:"responses"`
+}
+
+type openAPIResponse struct {
+	// OpenAPI 3.x
+	Content map[string]openAPIMediaType `yaml:"content"`
+
+	// Swagger 2.0
+	Schema   interface{}            `yaml:"schema"`
+	Examples map[string]interface{} `yaml:"examples"`
+}
+
+type openAPIMediaType struct {
+	Schema   interface{} `yaml:"schema"`
+	Example  interface{} `yaml:"example"`
+	Examples map[string]struct {
+		Value interface{} `yaml:"value"`
+	} `yaml:"examples"`
+}
+
+var openAPIMethods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+}
+
+// FromOpenAPI converts every operation of the OpenAPI 3.x (or Swagger 2.0) spec, in JSON or YAML, into mock definition.
+//
+// The host (and base path) is taken from the first server URL (or host and basePath for Swagger 2.0),
+// and the path template parameters are converted into path params (ex: /products/{id} => /products/:id).
+//
+// Each definition has a single response, using the lowest 2xx status code of the operation
+// (or default / the lowest declared status code when no 2xx). The response body is taken from the example
+// of the JSON media type (or the first media type), else generated from the response schema.
+func FromOpenAPI(data []byte, opts Options) ([]Definition, error) {
+	var spec openAPISpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+
+	host, basePath := spec.Host, spec.BasePath
+	if len(spec.Servers) > 0 {
+		if server, err := url.Parse(spec.Servers[0].URL); err == nil {
+			host, basePath = server.Host, server.Path
+		}
+	}
+	host, err := definitionHost(opts, host)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var definitions []Definition
+	for _, path := range paths {
+		for _, method := range openAPIMethods {
+			node, exist := spec.Paths[path][strings.ToLower(method)]
+			if !exist {
+				continue
+			}
+			var operation openAPIOperation
+			if err := node.Decode(&operation); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+
+			response, err := spec.convertResponse(operation)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			desc := operation.Summary
+			if desc == "" {
+				desc = operation.OperationID
+			}
+			definitions = append(definitions, Definition{
+				Host:      host,
+				Path:      convertPath(strings.TrimSuffix(basePath, "/") + path),
+				Method:    method,
+				Desc:      desc,
+				Responses: []Response{response},
+			})
+		}
+	}
+	return definitions, nil
+}
+
+func (s openAPISpec) convertResponse(operation openAPIOperation) (Response, error) {
+	statusCode, response := selectOpenAPIResponse(operation.Responses)
+	result := Response{StatusCode: statusCode}
+
+	mediaType, content := selectMediaType(response)
+	if mediaType == "" {
+		return result, nil
+	}
+	result.ResponseHeaders = map[string]string{"Content-Type": mediaType}
+
+	body := content.Example
+	if body == nil && len(content.Examples) > 0 {
+		names := make([]string, 0, len(content.Examples))
+		for name := range content.Examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		body = content.Examples[names[0]].Value
+	}
+	if body == nil && content.Schema != nil {
+		generated, err := s.generate(content.Schema)
+		if err != nil {
+			return result, err
+		}
+		body = generated
+	}
+
+	switch value := body.(type) {
+	case nil:
+	case string:
+		// string example of JSON media type is either the raw JSON document, or a JSON string
+		if strings.Contains(mediaType, "json") && !json.Valid([]byte(value)) {
+			encoded, _ := json.Marshal(value)
+			result.Body = string(encoded)
+		} else {
+			result.Body = value
+		}
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return result, err
+		}
+		result.Body = string(encoded)
+	}
+	return result, nil
+}
+
+// selectOpenAPIResponse choose the lowest 2xx response, else default response (as 200), else the lowest status code.
+func selectOpenAPIResponse(responses map[string]openAPIResponse) (int, openAPIResponse) {
+	var codes []int
+	for code := range responses {
+		if statusCode, err := strconv.Atoi(code); err == nil {
+			codes = append(codes, statusCode)
+		}
+	}
+	sort.Ints(codes)
+
+	for _, code := range codes {
+		if code >= 200 && code < 300 {
+			return code, responses[strconv.Itoa(code)]
+		}
+	}
+	if response, exist := responses["default"]; exist {
+		return http.StatusOK, response
+	}
+	if len(codes) > 0 {
+		return codes[0], responses[strconv.Itoa(codes[0])]
+	}
+	return http.StatusOK, openAPIResponse{}
+}
+
+// selectMediaType choose the JSON media type of the response, else the first media type (sorted).
+// Swagger 2.0 response is treated as application/json media type.
+func selectMediaType(response openAPIResponse) (string, openAPIMediaType) {
+	if response.Content == nil {
+		if response.Schema == nil && len(response.Examples) == 0 {
+			return "", openAPIMediaType{}
+		}
+		return "application/json", openAPIMediaType{Schema: response.Schema, Example: response.Examples["application/json"]}
+	}
+
+	mediaTypes := make([]string, 0, len(response.Content))
+	for mediaType := range response.Content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	for _, mediaType := range mediaTypes {
+		if strings.HasPrefix(mediaType, "application/json") {
+			return mediaType, response.Content[mediaType]
+		}
+	}
+	if len(mediaTypes) == 0 {
+		return "", openAPIMediaType{}
+	}
+	return mediaTypes[0], response.Content[mediaTypes[0]]
+}
+
+// generate synthesize the response body from the schema. The schemas of the spec components (or definitions)
+// are embedded as $defs, so the $ref to them can be resolved.
+func (s openAPISpec) generate(schema interface{}) (interface{}, error) {
+	keywords, ok := schema.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	document := make(map[string]interface{}, len(keywords)+2)
+	for key, value := range keywords {
+		document[key] = value
+	}
+	document["$defs"] = s.Components.Schemas
+	document["definitions"] = s.Definitions
+
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	encoded = []byte(strings.ReplaceAll(string(encoded), `"#/components/schemas/`, `"#/$defs/`))
+
+	parsed, err := jsonschema.Parse(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Generate()
+}
@@ -0,0 +1,213 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type postmanCollection struct {
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanVariable `json:"variable"`
+}
+
+type postmanVariable struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+type postmanItem struct {
+	Name     string            `json:"name"`
+	Item     []postmanItem     `json:"item"`
+	Request  *postmanRequest   `json:"request"`
+	Response []postmanResponse `json:"response"`
+}
+
+type postmanRequest struct {
+	Method string     `json:"method"`
+	URL    postmanURL `json:"url"`
+}
+
+// postmanURL is the raw URL of the request, defined either as string or as object (with raw field).
+type postmanURL string
+
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		*u = postmanURL(raw)
+		return nil
+	}
+
+	var object struct {
+		Raw string `json:"raw"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	*u = postmanURL(object.Raw)
+	return nil
+}
+
+type postmanResponse struct {
+	Code            int             `json:"code"`
+	Header          []postmanHeader `json:"header"`
+	Body            string          `json:"body"`
+	OriginalRequest *postmanRequest `json:"originalRequest"`
+}
+
+type postmanHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// skippedHeaders are the saved response headers that are not converted, as they are generated per response.
+var skippedHeaders = []string{"Content-Length", "Date", "Connection", "Transfer-Encoding", "Keep-Alive"}
+
+// FromPostman converts every request of the Postman collection (v2.0 / v2.1, including the nested folders)
+// into mock definition, with the collection variables (ex: {{baseUrl}}) substituted.
+//
+// The saved examples of the request are converted into the responses. Example saved with different query
+// has rawQuery rule, so it is only served for the same query, and the first example without query
+// is the default response. Request without saved example respond with empty 200 OK.
+func FromPostman(data []byte, opts Options) ([]Definition, error) {
+	var collection postmanCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, err
+	}
+
+	replacements := make([]string, 0, len(collection.Variable)*2)
+	for _, variable := range collection.Variable {
+		replacements = append(replacements, "{{"+variable.Key+"}}", fmt.Sprint(variable.Value))
+	}
+	replacer := strings.NewReplacer(replacements...)
+
+	var definitions []Definition
+	var walk func(items []postmanItem) error
+	walk = func(items []postmanItem) error {
+		for _, item := range items {
+			if item.Request == nil {
+				if err := walk(item.Item); err != nil {
+					return err
+				}
+				continue
+			}
+
+			definition, err := convertPostmanItem(item, replacer, opts)
+			if err != nil {
+				return fmt.Errorf("%s: %w", item.Name, err)
+			}
+			definitions = append(definitions, definition)
+		}
+		return nil
+	}
+	if err := walk(collection.Item); err != nil {
+		return nil, err
+	}
+	return definitions, nil
+}
+
+func convertPostmanItem(item postmanItem, replacer *strings.Replacer, opts Options) (Definition, error) {
+	target, err := parsePostmanURL(item.Request.URL, replacer, opts.Host)
+	if err != nil {
+		return Definition{}, err
+	}
+	host, err := definitionHost(opts, target.Host)
+	if err != nil {
+		return Definition{}, err
+	}
+
+	method := strings.ToUpper(item.Request.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	definition := Definition{
+		Host:   host,
+		Path:   convertPath(target.Path),
+		Method: method,
+		Desc:   item.Name,
+	}
+
+	var (
+		defaultResponse *Response
+		seenQueries     = make(map[string]bool)
+	)
+	for _, example := range item.Response {
+		response := convertPostmanResponse(example)
+
+		var rawQuery string
+		if example.OriginalRequest != nil {
+			if original, err := parsePostmanURL(example.OriginalRequest.URL, replacer, opts.Host); err == nil {
+				rawQuery = original.RawQuery
+			}
+		}
+		if rawQuery == "" {
+			if defaultResponse == nil {
+				defaultResponse = &response
+			}
+			continue
+		}
+		if seenQueries[rawQuery] {
+			continue
+		}
+		seenQueries[rawQuery] = true
+		response.Rules = []string{"rawQuery == " + strconv.Quote(rawQuery)}
+		definition.Responses = append(definition.Responses, response)
+	}
+
+	// keep the default response (no rules) last, or serve empty 200 OK when there is no saved example at all
+	switch {
+	case defaultResponse != nil:
+		definition.Responses = append(definition.Responses, *defaultResponse)
+	case len(definition.Responses) == 0:
+		definition.Responses = []Response{{StatusCode: http.StatusOK}}
+	}
+	return definition, nil
+}
+
+func convertPostmanResponse(example postmanResponse) Response {
+	response := Response{StatusCode: example.Code, Body: example.Body}
+	if response.StatusCode == 0 {
+		response.StatusCode = http.StatusOK
+	}
+	for _, header := range example.Header {
+		name := http.CanonicalHeaderKey(header.Key)
+		if name == "" || in(name, skippedHeaders) {
+			continue
+		}
+		if response.ResponseHeaders == nil {
+			response.ResponseHeaders = make(map[string]string)
+		}
+		response.ResponseHeaders[name] = header.Value
+	}
+	return response
+}
+
+// parsePostmanURL substitute the variables of the raw URL, and parse it (scheme is optional in Postman).
+// The host of the URL is replaced when host is not empty, so unresolved host variable is not a problem.
+func parsePostmanURL(raw postmanURL, replacer *strings.Replacer, host string) (*url.URL, error) {
+	rawURL := replacer.Replace(string(raw))
+	if idx := strings.Index(rawURL, "://"); idx >= 0 {
+		rawURL = rawURL[idx+3:]
+	}
+	if host != "" {
+		rawURL = host + rawURL[strings.IndexAny(rawURL+"/", "/?"):]
+	}
+
+	target, err := url.Parse("http://" + rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse url %q, set the host explicitly for unresolved host variable: %w", string(raw), err)
+	}
+	return target, nil
+}
+
+func in(value string, values []string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package mockhttp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CoverageReporter is implemented by resolver adapters that can report which mock definitions were used,
+// helping teams prune dead mock specs and spot gaps after a test run.
+//
+// The built-in file based resolver implements CoverageReporter:
+//
+//	fmt.Println(resolver.(mockhttp.CoverageReporter).Coverage())
+type CoverageReporter interface {
+	Coverage() Coverage
+}
+
+// Coverage describes which loaded definitions were never matched, and which responses were never selected.
+type Coverage struct {
+	Definitions []DefinitionCoverage
+}
+
+// DefinitionCoverage describes the usage of a single mock definition.
+type DefinitionCoverage struct {
+	Definition string
+	// File is the definition file, empty for definition added at runtime.
+	File string
+	// Matched is true when any request matched the definition host, method and path.
+	Matched bool
+	// Responses is the number of the responses of the definition.
+	Responses int
+	// UnselectedResponses are the indexes of the responses never selected.
+	UnselectedResponses []int
+}
+
+// Unmatched returns the definitions never matched by any request.
+func (c Coverage) Unmatched() []DefinitionCoverage {
+	return filter[DefinitionCoverage](c.Definitions, func(definition DefinitionCoverage) bool {
+		return !definition.Matched
+	})
+}
+
+// String format the coverage into human readable report.
+func (c Coverage) String() string {
+	matched, responses, selected := 0, 0, 0
+	for _, definition := range c.Definitions {
+		if definition.Matched {
+			matched++
+		}
+		responses += definition.Responses
+		selected += definition.Responses - len(definition.UnselectedResponses)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "mock coverage: %d/%d definitions matched, %d/%d responses selected\n", matched, len(c.Definitions), selected, responses)
+	for _, definition := range c.Definitions {
+		name := definition.Definition
+		if definition.File != "" {
+			name = fmt.Sprintf("%s (%s)", name, definition.File)
+		}
+		switch {
+		case !definition.Matched:
+			fmt.Fprintf(&sb, "  unmatched: %s\n", name)
+		case len(definition.UnselectedResponses) > 0:
+			fmt.Fprintf(&sb, "  unselected: %s responses %v\n", name, definition.UnselectedResponses)
+		}
+	}
+	return sb.String()
+}
+
+// fileBasedResolver Coverage
+// Report the usage of the loaded definitions, since they are loaded (the usage start over after reload).
+//
+// Definition is matched when a request match its host, method and path (even when no response is selected),
+// and response is selected when it is served (or simulate timeout) for a request.
+func (r *fileBasedResolver) Coverage() Coverage {
+	return coverageOf(r.Stats())
+}
+
+// coverageOf build the coverage from the hit counts of the definitions.
+func coverageOf(stats []DefinitionStats) Coverage {
+	var coverage Coverage
+	for _, definition := range stats {
+		definitionCoverage := DefinitionCoverage{
+			Definition: definition.Definition,
+			File:       definition.File,
+			Matched:    definition.Hits > 0,
+			Responses:  len(definition.Responses),
+		}
+		for _, response := range definition.Responses {
+			if response.Hits == 0 {
+				definitionCoverage.UnselectedResponses = append(definitionCoverage.UnselectedResponses, response.Index)
+			}
+		}
+		coverage.Definitions = append(coverage.Definitions, definitionCoverage)
+	}
+	return coverage
+}
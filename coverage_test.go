@@ -0,0 +1,62 @@
+package mockhttp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileBasedResolver_Coverage(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []FileResolverOption
+	}{
+		{name: "eager load"},
+		{name: "lazy load", opts: []FileResolverOption{WithLazyLoad()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := newTestResolverWithFiles(t, map[string]string{
+				"products.yaml": `
+host: marketplace.com
+path: /products/:id
+method: GET
+responses:
+  - status_code: 404
+    rules:
+      - routeParams.id == "0"
+  - status_code: 200
+`,
+				"orders.yaml": `
+host: marketplace.com
+path: /orders
+method: GET
+responses:
+  - status_code: 200
+`,
+			}, tt.opts...)
+
+			_, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, "http://marketplace.com/products/1", ""))
+			assert.Nil(t, err)
+
+			coverage := resolver.Coverage()
+			unmatched := coverage.Unmatched()
+			assert.Len(t, unmatched, 1)
+			assert.Equal(t, "GET marketplace.com/orders", unmatched[0].Definition)
+			assert.Equal(t, "orders.yaml", unmatched[0].File)
+			assert.Contains(t, coverage.String(), "  unselected: GET marketplace.com/products/:id (products.yaml) responses [0]\n")
+		})
+	}
+
+	t.Run("report", func(t *testing.T) {
+		coverage := Coverage{Definitions: []DefinitionCoverage{
+			{Definition: "GET marketplace.com/products/:id", File: "products.yaml", Matched: true, Responses: 2, UnselectedResponses: []int{0}},
+			{Definition: "GET marketplace.com/orders", File: "orders.yaml", Responses: 1, UnselectedResponses: []int{0}},
+		}}
+		assert.Equal(t, "mock coverage: 1/2 definitions matched, 1/3 responses selected\n"+
+			"  unselected: GET marketplace.com/products/:id (products.yaml) responses [0]\n"+
+			"  unmatched: GET marketplace.com/orders (orders.yaml)\n", coverage.String())
+	})
+}
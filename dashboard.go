@@ -0,0 +1,208 @@
+package mockhttp
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+//go:embed dashboard.html
+var dashboardPage []byte
+
+// maxDashboardRequests is the max number of the most recent requests listed by the dashboard request log.
+const maxDashboardRequests = 200
+
+// maxDashboardMatchSize is the max size of the sample request submitted to the dashboard test-match form.
+const maxDashboardMatchSize = 1 << 20
+
+type dashboardHandler struct {
+	client *Client
+	admin  *adminHandler
+	mux    *http.ServeMux
+}
+
+// NewDashboardHandler creates web UI to browse the state of the client and its resolver,
+// for QA engineers using the mock server without reading the definition files:
+//   - GET  /            : the dashboard page
+//   - GET  /definitions : loaded definitions with the hit counts (resolver must implement StatsReporter)
+//   - GET  /requests    : most recent requests served by the client (from the client journal)
+//   - POST /match       : explain how a sample request is matched (resolver must implement Explainer)
+//
+// The handler routes are relative, mount it with http.StripPrefix on path ending with slash:
+//
+//	mux.Handle(mockhttp.AdminPath+"/ui/", http.StripPrefix(mockhttp.AdminPath+"/ui", mockhttp.NewDashboardHandler(client)))
+func NewDashboardHandler(client *Client) http.Handler {
+	h := &dashboardHandler{
+		client: client,
+		admin:  &adminHandler{client: client},
+		mux:    http.NewServeMux(),
+	}
+	h.mux.HandleFunc("/", h.page)
+	h.mux.HandleFunc("/definitions", h.definitions)
+	h.mux.HandleFunc("/requests", h.requests)
+	h.mux.HandleFunc("/match", h.match)
+	return h
+}
+
+func (h *dashboardHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *dashboardHandler) page(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "mockhttp: method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(dashboardPage)
+}
+
+func (h *dashboardHandler) definitions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "mockhttp: method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	h.admin.definitions(w, r)
+}
+
+// dashboardRequest is a single request listed by the dashboard request log.
+type dashboardRequest struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	URL        string    `json:"url"`
+	Mocked     bool      `json:"mocked"`
+	Bypassed   bool      `json:"bypassed"`
+	Definition string    `json:"definition,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+	DurationMs float64   `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+func (h *dashboardHandler) requests(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "mockhttp: method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	journal := h.client.Journal()
+	if len(journal) > maxDashboardRequests {
+		journal = journal[len(journal)-maxDashboardRequests:]
+	}
+
+	// most recent first, as displayed by the live request log
+	requests := make([]dashboardRequest, 0, len(journal))
+	for idx := len(journal) - 1; idx >= 0; idx-- {
+		entry := journal[idx]
+		request := dashboardRequest{
+			Time:       entry.Time,
+			Method:     entry.Method,
+			URL:        entry.URL.String(),
+			Mocked:     entry.Info.Mocked,
+			Bypassed:   entry.Info.Bypassed,
+			Definition: entry.Info.Definition,
+			StatusCode: entry.StatusCode,
+			DurationMs: float64(entry.Duration) / float64(time.Millisecond),
+		}
+		if entry.Err != nil {
+			request.Error = entry.Err.Error()
+		}
+		requests = append(requests, request)
+	}
+	writeJSON(w, requests)
+}
+
+// dashboardMatchRequest is the sample request submitted to the dashboard test-match form.
+type dashboardMatchRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Headers are the request headers, one "Name: value" per line.
+	Headers string `json:"headers"`
+	Body    string `json:"body"`
+}
+
+// dashboardMatchResult is how the sample request is matched against the loaded definitions.
+type dashboardMatchResult struct {
+	Matched     bool   `json:"matched"`
+	Definition  string `json:"definition,omitempty"`
+	Explanation string `json:"explanation"`
+}
+
+func (h *dashboardHandler) match(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "mockhttp: method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	explainer, ok := h.client.snapshot().resolver.(Explainer)
+	if !ok {
+		http.Error(w, "mockhttp: resolver does not explain the request matching", http.StatusNotImplemented)
+		return
+	}
+
+	var sample dashboardMatchRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxDashboardMatchSize)).Decode(&sample); err != nil {
+		http.Error(w, "mockhttp: invalid sample request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req, err := sample.request(r)
+	if err != nil {
+		http.Error(w, "mockhttp: invalid sample request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	explanation, err := explainer.Explain(r.Context(), req)
+	if err != nil {
+		http.Error(w, "mockhttp: unable to explain the sample request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	result := dashboardMatchResult{Explanation: explanation.String()}
+	if matched := explanation.Matched; matched != nil {
+		result.Matched = true
+		result.Definition = matched.Method + " " + matched.Host + matched.Path
+	}
+	writeJSON(w, result)
+}
+
+// request build the sample request, with the same context as the dashboard request.
+func (s dashboardMatchRequest) request(r *http.Request) (*Request, error) {
+	method := strings.ToUpper(strings.TrimSpace(s.Method))
+	if method == "" {
+		method = http.MethodGet
+	}
+	if !strings.Contains(s.URL, "://") {
+		return nil, errors.New("url must be absolute, ex: http://marketplace.com/products")
+	}
+
+	var body io.Reader
+	if s.Body != "" {
+		body = strings.NewReader(s.Body)
+	}
+	httpReq, err := http.NewRequestWithContext(r.Context(), method, s.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(s.Headers, "\n") {
+		name, value, found := strings.Cut(line, ":")
+		if name = textproto.TrimString(name); !found || name == "" {
+			continue
+		}
+		httpReq.Header.Add(name, textproto.TrimString(value))
+	}
+
+	req, err := FromRequest(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.rewindBody(); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
@@ -0,0 +1,112 @@
+package mockhttp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashboardHandler(t *testing.T) {
+	client := newTestClient(newTestResolver(t, `
+host: marketplace.com
+path: /products
+method: POST
+responses:
+  - status_code: 201
+    rules:
+      - body.name == "Shoes"
+  - status_code: 400
+`))
+	client.MockOnly = true
+	_, err := client.Post("http://marketplace.com/products", "application/json", nil)
+	assert.Nil(t, err)
+	_, err = client.Get("http://marketplace.com/orders")
+	assert.NotNil(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle(AdminPath+"/ui/", http.StripPrefix(AdminPath+"/ui", NewDashboardHandler(client)))
+	dashboard := httptest.NewServer(mux)
+	defer dashboard.Close()
+
+	t.Run("page", func(t *testing.T) {
+		resp, err := http.Get(dashboard.URL + AdminPath + "/ui/")
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+		assert.Contains(t, string(body), "mockhttp dashboard")
+
+		resp, err = http.Get(dashboard.URL + AdminPath + "/ui/unknown")
+		assert.Nil(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("definitions", func(t *testing.T) {
+		resp, err := http.Get(dashboard.URL + AdminPath + "/ui/definitions")
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+
+		var stats []DefinitionStats
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(&stats))
+		assert.Len(t, stats, 1)
+		assert.Equal(t, uint64(1), stats[0].Hits)
+	})
+
+	t.Run("requests", func(t *testing.T) {
+		resp, err := http.Get(dashboard.URL + AdminPath + "/ui/requests")
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+
+		var requests []dashboardRequest
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(&requests))
+		assert.Len(t, requests, 2)
+		assert.Equal(t, "http://marketplace.com/orders", requests[0].URL)
+		assert.False(t, requests[0].Mocked)
+		assert.NotEmpty(t, requests[0].Error)
+		assert.Equal(t, "http://marketplace.com/products", requests[1].URL)
+		assert.True(t, requests[1].Mocked)
+		assert.Equal(t, http.StatusBadRequest, requests[1].StatusCode)
+	})
+
+	t.Run("match", func(t *testing.T) {
+		sample := `{"method": "post", "url": "http://marketplace.com/products", "headers": "Content-Type: application/json", "body": "{\"name\": \"Shoes\"}"}`
+		resp, err := http.Post(dashboard.URL+AdminPath+"/ui/match", "application/json", strings.NewReader(sample))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+
+		var result dashboardMatchResult
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.True(t, result.Matched)
+		assert.Equal(t, "POST marketplace.com/products", result.Definition)
+		assert.Contains(t, result.Explanation, "* response #0 status 201")
+
+		sample = `{"method": "GET", "url": "http://marketplace.com/products"}`
+		resp, err = http.Post(dashboard.URL+AdminPath+"/ui/match", "application/json", strings.NewReader(sample))
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+
+		result = dashboardMatchResult{}
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.False(t, result.Matched)
+		assert.Contains(t, result.Explanation, "no definition registered for the host and method")
+	})
+
+	t.Run("invalid sample request", func(t *testing.T) {
+		resp, err := http.Post(dashboard.URL+AdminPath+"/ui/match", "application/json", strings.NewReader(`{"url": "/products"}`))
+		assert.Nil(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, err = http.Get(dashboard.URL + AdminPath + "/ui/match")
+		assert.Nil(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
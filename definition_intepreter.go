@@ -1,9 +1,12 @@
 package mockhttp
 
 import (
+	"errors"
+	"math/rand"
 	"net/http"
+	"time"
 
-	"github.com/expr-lang/expr"
+	"github.com/William9923/go-mockhttp/jsonschema"
 )
 
 var parsedXMLBodyMimeTypes = []string{
@@ -25,20 +28,20 @@ var parsedBodyMimeTypes = merge(parsedXMLBodyMimeTypes, parsedJSONBodyMimeTypes,
 
 func (r *fileBasedResolver) validateTarget(req *incomingRequest) error {
 
-	if in[string](req.Method, []string{http.MethodGet, http.MethodHead, http.MethodDelete}) {
+	if !req.hasBody() || in[string](req.Method, []string{http.MethodGet, http.MethodHead, http.MethodDelete}) {
 		return nil
 	}
 
 	headers := req.Headers
 	contentType, exist := headers["Content-Type"]
 	if !exist {
-		return ErrNoContentType
+		return &ContentTypeError{Err: ErrNoContentType}
 	}
 
 	if !some[string](parsedBodyMimeTypes, func(supportedContentType string) bool {
 		return supportedContentType == contentType
 	}) {
-		return ErrUnsupportedContentType
+		return &ContentTypeError{ContentType: contentType, Err: ErrUnsupportedContentType}
 	}
 
 	return nil
@@ -49,47 +52,261 @@ func (r *fileBasedResolver) findResponse(request *incomingRequest, selectedDefin
 	if err := r.validateTarget(request); err != nil {
 		return nil, err
 	}
-	return r.chooseResponse(request, selectedDefinition), nil
+	return r.chooseResponse(request, selectedDefinition)
 }
 
-func (r *fileBasedResolver) chooseResponse(request *incomingRequest, definition fileBasedMockDefinition) *mockResponse {
+func (r *fileBasedResolver) chooseResponse(request *incomingRequest, definition fileBasedMockDefinition) (*mockResponse, error) {
 
+	var evalErr error
 	correctResponse, _ := findFirst[mockResponse](definition.Responses, func(data mockResponse) bool {
 		// lower the priotization of non-rules / default affected response
-		if data.isDefault() {
+		if data.isDefault() || !r.isServable(data) || evalErr != nil {
 			return false
 		}
 
-		return all[string](data.Rules, func(rule string) bool {
-			return r.isRuleFulfilled(request, rule)
-		})
+		fulfilled, err := r.isResponseFulfilled(request, data)
+		if err != nil {
+			evalErr = err
+		}
+		return fulfilled && data.use()
 	})
+	if evalErr != nil {
+		return nil, evalErr
+	}
 	if !correctResponse.isNil() {
-		return &correctResponse
+		return &correctResponse, nil
 	}
 
 	// if no mock response found, can use default one response (with no rule)
-	defaultResponse, _ := findFirst[mockResponse](definition.Responses, func(data mockResponse) bool {
-		return data.isDefault()
+	defaultResponses := filter[mockResponse](definition.Responses, func(data mockResponse) bool {
+		return data.isDefault() && r.isServable(data)
 	})
-	if !defaultResponse.isNil() {
-		return &defaultResponse
+	selected := r.selectDefaultResponse(definition, defaultResponses)
+	if selected == nil || !selected.use() {
+		return nil, nil
 	}
+	return selected, nil
+}
 
-	return nil
+// fileBasedResolver isServable
+// Check if the response can still be served, as it is not exhausted (max_uses) nor stale.
+// Recorded response (with recorded_at) older than the max recording age is stale, so the request is sent upstream
+// and re-recorded in record mode.
+func (r *fileBasedResolver) isServable(response mockResponse) bool {
+	if response.isExhausted() {
+		return false
+	}
+	if r.maxRecordingAge > 0 && !response.RecordedAt.IsZero() {
+		return r.clock().Sub(response.RecordedAt) <= r.maxRecordingAge
+	}
+	return true
+}
+
+// fileBasedResolver isResponseFulfilled
+// Check if all the rules of the response are fulfilled by the incoming request.
+//
+// Rule evaluation error (ex: comparing string with number) is treated as unfulfilled rule,
+// and reported to the rule error handler (if any). On strict rules mode, the error is returned instead.
+func (r *fileBasedResolver) isResponseFulfilled(request *incomingRequest, response mockResponse) (bool, error) {
+	return r.isNodeFulfilled(request, ruleNode{AllOf: response.Rules})
+}
+
+// fileBasedResolver isNodeFulfilled
+// Evaluate the rule node recursively:
+//   - expression : fulfilled when the compiled expression evaluated to true (via rule engine)
+//   - schema     : fulfilled when the request body satisfy the JSON Schema
+//   - all_of     : fulfilled when all of the nested rules are fulfilled
+//   - any_of     : fulfilled when any of the nested rules is fulfilled
+func (r *fileBasedResolver) isNodeFulfilled(request *incomingRequest, node ruleNode) (bool, error) {
+	if node.schema != nil {
+		return isSchemaFulfilled(request, node.schema) == nil, nil
+	}
+
+	if node.compiled != nil {
+		fulfilled, err := r.isRuleFulfilled(request, node.compiled)
+		if err != nil {
+			err = &RuleError{Definition: request.Definition, Rule: node.Expr, Err: ErrRuleEvaluation, Cause: err}
+			request.RuleErrors++
+			r.emit(Event{Type: EventRuleError, Method: request.Method, URL: request.URL, Definition: request.Definition, Err: err})
+			if r.strictRules {
+				return false, err
+			}
+			if r.ruleErrorHandler != nil {
+				r.ruleErrorHandler(node.Expr, err)
+			}
+		}
+		return fulfilled, nil
+	}
+
+	if len(node.AnyOf) > 0 {
+		for _, child := range node.AnyOf {
+			fulfilled, err := r.isNodeFulfilled(request, child)
+			if err != nil || fulfilled {
+				return fulfilled, err
+			}
+		}
+		return false, nil
+	}
+
+	for _, child := range node.AllOf {
+		fulfilled, err := r.isNodeFulfilled(request, child)
+		if err != nil || !fulfilled {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// fileBasedResolver selectDefaultResponse
+// Choose one of the default responses (response with no rule) based on definition strategy:
+//   - first       : always use the first default response (default behavior)
+//   - round_robin : rotate the default responses in order, for each request
+//   - random      : pick random default response, for each request
+func (r *fileBasedResolver) selectDefaultResponse(definition fileBasedMockDefinition, responses []mockResponse) *mockResponse {
+	if len(responses) == 0 {
+		return nil
+	}
+
+	var selected mockResponse
+	switch definition.Strategy {
+	case strategyRoundRobin:
+		idx := (definition.selectCounter.Add(1) - 1) % uint64(len(responses))
+		selected = responses[idx]
+	case strategyRandom:
+		selected = responses[rand.Intn(len(responses))]
+	default:
+		selected = responses[0]
+	}
+
+	if selected.isNil() {
+		return nil
+	}
+	return &selected
 }
 
-func (r *fileBasedResolver) isRuleFulfilled(request *incomingRequest, rule string) bool {
-	evalRes, err := expr.Eval(rule, map[string]interface{}{
+func (r *fileBasedResolver) isRuleFulfilled(request *incomingRequest, rule CompiledRule) (bool, error) {
+	return r.ruleEngine.Eval(rule, r.ruleEnv(request))
+}
+
+// isSchemaFulfilled validate the request body against the JSON Schema,
+// return the reason why the request body is invalid.
+func isSchemaFulfilled(request *incomingRequest, schema *jsonschema.Schema) error {
+	var body interface{}
+	if request.Body != nil {
+		body = request.Body
+	}
+	return schema.Validate(body)
+}
+
+// fileBasedResolver ruleEnv
+// Build the variables (and custom functions) that can be accessed by the rules, based on incoming request
+// and the current time (from resolver clock).
+func (r *fileBasedResolver) ruleEnv(request *incomingRequest) map[string]interface{} {
+	now := r.clock()
+	env := map[string]interface{}{
+		"currentTime": now,
+		"weekday":     now.Weekday().String(),
+		"hour":        now.Hour(),
+		"minute":      now.Minute(),
+		"timeBetween": timeBetween,
+		"method":      request.Method,
+		"host":        request.Host,
+		"path":        request.Endpoint,
+		"rawQuery":    request.RawQuery,
+		"url":         request.URL,
 		"raw":         request.RawBody,
 		"body":        request.Body,
 		"routeParams": request.RouteParams.export(),
 		"headers":     request.Headers.export(),
 		"cookies":     request.Cookies.export(),
 		"queryParams": request.QueryParams.export(),
-	})
+		"callCount":   request.CallCount,
+		"state":       r.state.export(),
+	}
+	for name, fn := range r.ruleFunctions {
+		env[name] = fn
+	}
+	return env
+}
+
+// timeBetween check whether the time of day of t is within the window [start, end), using "15:04" layout.
+// Window that pass midnight (ex: 22:00 - 06:00) is supported, to simulate overnight maintenance window.
+//
+// ex:
+// timeBetween(currentTime, "09:00", "17:00") => true during business hours
+func timeBetween(t time.Time, start, end string) (bool, error) {
+	startTime, err := time.Parse("15:04", start)
 	if err != nil {
-		return false
+		return false, err
+	}
+	endTime, err := time.Parse("15:04", end)
+	if err != nil {
+		return false, err
+	}
+
+	minuteOfDay := func(t time.Time) int { return t.Hour()*60 + t.Minute() }
+	current, from, to := minuteOfDay(t), minuteOfDay(startTime), minuteOfDay(endTime)
+	if from <= to {
+		return current >= from && current < to, nil
 	}
-	return evalRes.(bool)
+	return current >= from || current < to, nil
+}
+
+// compileRules compile all the rules once (during LoadDefinition),
+// so the syntax is validated up front and the rules does not need to be re-compiled for every request.
+//
+// The compiled rule (via rule engine) is stored on each (nested) rule node.
+func (r *fileBasedResolver) compileRules(rules []ruleNode) error {
+	return r.compileRuleNodes(rules, r.ruleEnv(&incomingRequest{}))
+}
+
+// bodyVariables are the rule variables filled from the request body.
+var bodyVariables = []string{"body", "raw"}
+
+// accessBody check whether the rule expression reference the request body,
+// assuming it does when the rule engine can't tell (see RuleInspector).
+func (r *fileBasedResolver) accessBody(rule string, env map[string]interface{}) bool {
+	inspector, ok := r.ruleEngine.(RuleInspector)
+	if !ok {
+		return true
+	}
+	variables, err := inspector.Variables(rule, env)
+	if err != nil {
+		return true
+	}
+	return some[string](variables, func(variable string) bool {
+		return in[string](variable, bodyVariables)
+	})
+}
+
+func (r *fileBasedResolver) compileRuleNodes(rules []ruleNode, env map[string]interface{}) error {
+	for idx := range rules {
+		node := &rules[idx]
+		switch {
+		case node.Expr != "":
+			compiled, err := r.ruleEngine.Compile(node.Expr, env)
+			if err != nil {
+				return &RuleError{Rule: node.Expr, Err: ErrInvalidRule, Cause: err}
+			}
+			node.compiled = compiled
+			node.bodyFree = !r.accessBody(node.Expr, env)
+		case node.Schema != "":
+			schema, err := r.loadSchema(node.Schema)
+			if err != nil {
+				return &RuleError{Rule: "schema: " + node.Schema, Err: ErrInvalidRule, Cause: err}
+			}
+			node.schema = schema
+		case len(node.AnyOf) > 0:
+			if err := r.compileRuleNodes(node.AnyOf, env); err != nil {
+				return err
+			}
+		case len(node.AllOf) > 0:
+			if err := r.compileRuleNodes(node.AllOf, env); err != nil {
+				return err
+			}
+		default:
+			return &RuleError{Err: ErrInvalidRule, Cause: errors.New("empty rule")}
+		}
+	}
+	return nil
 }
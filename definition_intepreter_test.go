@@ -0,0 +1,63 @@
+package mockhttp
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_timeBetween(t *testing.T) {
+	type args struct {
+		t     time.Time
+		start string
+		end   string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    bool
+		wantErr bool
+	}{
+		{
+			"within business hours",
+			args{time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), "09:00", "17:00"},
+			true,
+			false,
+		},
+		{
+			"end of window is exclusive",
+			args{time.Date(2024, 1, 1, 17, 0, 0, 0, time.UTC), "09:00", "17:00"},
+			false,
+			false,
+		},
+		{
+			"within overnight window",
+			args{time.Date(2024, 1, 1, 2, 30, 0, 0, time.UTC), "22:00", "06:00"},
+			true,
+			false,
+		},
+		{
+			"outside overnight window",
+			args{time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), "22:00", "06:00"},
+			false,
+			false,
+		},
+		{
+			"invalid layout",
+			args{time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), "9am", "06:00"},
+			false,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := timeBetween(tt.args.t, tt.args.start, tt.args.end)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("timeBetween() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("timeBetween() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package mockhttp
+
+import (
+	"context"
+	"time"
+)
+
+// DelayPolicy decide how the mock delay behave when it exceeds the remaining request context deadline.
+type DelayPolicy int
+
+const (
+	// DelayUntilDeadline sleep until the delay passed or the context is done, whichever happen first,
+	// and fail with the context error (ex: context.DeadlineExceeded) when the context is done first. It is the default policy.
+	DelayUntilDeadline DelayPolicy = iota
+	// DelayFull always sleep for the full delay (ignoring the context), then respond with the mock response.
+	DelayFull
+	// DelayTruncate sleep until the delay passed or the context deadline, whichever happen first,
+	// then respond with the mock response anyway.
+	DelayTruncate
+	// DelayFailFast fail immediately with context.DeadlineExceeded when the delay exceeds the remaining deadline,
+	// without sleeping.
+	DelayFailFast
+)
+
+// fileBasedResolver delay
+// Pause for the mock delay, based on the resolver delay policy.
+func (r *fileBasedResolver) delay(ctx context.Context, d time.Duration) error {
+	switch r.delayPolicy {
+	case DelayFull:
+		time.Sleep(d)
+		return nil
+	case DelayTruncate:
+		if deadline, ok := ctx.Deadline(); ok {
+			if remaining := time.Until(deadline); remaining < d {
+				d = remaining
+			}
+		}
+		time.Sleep(d)
+		return nil
+	case DelayFailFast:
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+			return context.DeadlineExceeded
+		}
+	}
+	return sleepContext(ctx, d)
+}
+
+// sleepContext pause for the duration d, or until the context is done (cancelled / deadline exceeded),
+// whichever happen first, just like a real transport call. Return the context error when the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,212 @@
+package mockhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-cleanhttp"
+)
+
+// DriftDetector is implemented by resolver adapters that can verify the mock definitions against the live upstreams,
+// so stale mocks can be flagged in CI.
+//
+// The built-in file based resolver implements DriftDetector:
+//
+//	reports, err := resolver.(mockhttp.DriftDetector).DetectDrift(ctx, mockhttp.DriftOptions{})
+type DriftDetector interface {
+	DetectDrift(ctx context.Context, opts DriftOptions) ([]DriftReport, error)
+}
+
+// DriftOptions configure how the definition requests are replayed against the live upstreams.
+type DriftOptions struct {
+	// Client sends the replayed requests, defaults to cleanhttp pooled client.
+	Client *http.Client
+	// Scheme of the upstream URL, defaults to https.
+	Scheme string
+}
+
+// DriftReport describes the structural differences between the mocked response of a definition
+// and the actual upstream response.
+type DriftReport struct {
+	Definition string
+	// Skipped is the reason the definition can't be verified (ex: path with parameters), empty when verified.
+	Skipped string
+	// Error is the reason the upstream can't be called.
+	Error error
+	// Differences are the structural differences (status code, headers, JSON shape), empty when no drift found.
+	Differences []string
+}
+
+// HasDrift report whether the mocked response differ from the upstream response.
+func (d DriftReport) HasDrift() bool {
+	return len(d.Differences) > 0
+}
+
+// fileBasedResolver DetectDrift
+// Replay the request of each definition (without request body) against the live upstream,
+// and compare the upstream response with the default response (response with no rules) of the definition:
+//   - status code
+//   - headers defined in response_headers (Content-Type media type is compared by value, the others by presence)
+//   - JSON shape of the response body (or validated against the response_schema)
+//
+// Definitions with path parameters / wildcard, or without default response can't be replayed, and are skipped.
+func (r *fileBasedResolver) DetectDrift(ctx context.Context, opts DriftOptions) ([]DriftReport, error) {
+	if opts.Client == nil {
+		opts.Client = cleanhttp.DefaultPooledClient()
+	}
+	if opts.Scheme == "" {
+		opts.Scheme = "https"
+	}
+
+	definitions := r.loadedDefinitions()
+	reports := make([]DriftReport, 0, len(definitions))
+	for _, definition := range definitions {
+		report := DriftReport{Definition: definition.name()}
+		if definition, report.Error = r.parseLazy(definition); report.Error != nil {
+			reports = append(reports, report)
+			continue
+		}
+
+		response, _ := findFirst[mockResponse](definition.Responses, func(data mockResponse) bool {
+			return data.isDefault() && !data.Timeout
+		})
+		switch {
+		case definition.containParams || definition.containsWildcard:
+			report.Skipped = "path contains parameters"
+		case response.isNil():
+			report.Skipped = "no default response"
+		default:
+			report.Differences, report.Error = r.detectDrift(ctx, opts, definition, response)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func (r *fileBasedResolver) detectDrift(ctx context.Context, opts DriftOptions, definition fileBasedMockDefinition, response mockResponse) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, definition.Method, opts.Scheme+"://"+definition.Host+definition.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := opts.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var differences []string
+	statusCode := response.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	if statusCode != resp.StatusCode {
+		differences = append(differences, fmt.Sprintf("status code: mocked %d, upstream %d", statusCode, resp.StatusCode))
+	}
+
+	for name, value := range response.ResponseHeaders {
+		upstream := resp.Header.Get(name)
+		switch {
+		case upstream == "":
+			differences = append(differences, fmt.Sprintf("header %s: missing in upstream", name))
+		case http.CanonicalHeaderKey(name) == "Content-Type" && !sameMediaType(value, upstream):
+			differences = append(differences, fmt.Sprintf("header %s: mocked %q, upstream %q", name, value, upstream))
+		}
+	}
+
+	var upstreamBody interface{}
+	if err := json.Unmarshal(body, &upstreamBody); err != nil {
+		// only JSON body shape can be compared
+		return differences, nil
+	}
+	if response.Body == "" && response.schema != nil {
+		if err := response.schema.Validate(upstreamBody); err != nil {
+			differences = append(differences, fmt.Sprintf("body does not satisfy response schema: %s", err))
+		}
+		return differences, nil
+	}
+
+	var mockedBody interface{}
+	if response.EnableTemplate || json.Unmarshal([]byte(response.Body), &mockedBody) != nil {
+		return differences, nil
+	}
+	return append(differences, compareJSONShape("$", mockedBody, upstreamBody)...), nil
+}
+
+func sameMediaType(a, b string) bool {
+	mediaTypeA, _, errA := mime.ParseMediaType(a)
+	mediaTypeB, _, errB := mime.ParseMediaType(b)
+	if errA != nil || errB != nil {
+		return strings.EqualFold(a, b)
+	}
+	return mediaTypeA == mediaTypeB
+}
+
+// compareJSONShape compare the structure (types and object keys, not the values) of the decoded JSON values.
+// Arrays are compared by their first element.
+func compareJSONShape(path string, mocked, upstream interface{}) []string {
+	if jsonType(mocked) != jsonType(upstream) {
+		return []string{fmt.Sprintf("body %s: mocked %s, upstream %s", path, jsonType(mocked), jsonType(upstream))}
+	}
+
+	var differences []string
+	switch mockedValue := mocked.(type) {
+	case map[string]interface{}:
+		upstreamValue := upstream.(map[string]interface{})
+		keys := make([]string, 0, len(mockedValue)+len(upstreamValue))
+		for key := range mockedValue {
+			keys = append(keys, key)
+		}
+		for key := range upstreamValue {
+			if _, ok := mockedValue[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			mockedChild, inMocked := mockedValue[key]
+			upstreamChild, inUpstream := upstreamValue[key]
+			switch {
+			case !inUpstream:
+				differences = append(differences, fmt.Sprintf("body %s.%s: missing in upstream", path, key))
+			case !inMocked:
+				differences = append(differences, fmt.Sprintf("body %s.%s: missing in mock", path, key))
+			default:
+				differences = append(differences, compareJSONShape(path+"."+key, mockedChild, upstreamChild)...)
+			}
+		}
+	case []interface{}:
+		upstreamValue := upstream.([]interface{})
+		if len(mockedValue) > 0 && len(upstreamValue) > 0 {
+			differences = append(differences, compareJSONShape(path+"[]", mockedValue[0], upstreamValue[0])...)
+		}
+	}
+	return differences
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
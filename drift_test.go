@@ -0,0 +1,75 @@
+package mockhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileBasedResolver_DetectDrift(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		switch r.URL.Path {
+		case "/products":
+			_, _ = w.Write([]byte(`{"items":[{"id":"1","price":100}],"total":1}`))
+		case "/stocks":
+			_, _ = w.Write([]byte(`{"items":[{"id":1,"stock":5}],"page":1}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	resolver := newTestResolver(t, fmt.Sprintf(`
+host: %s
+path: /products
+method: GET
+responses:
+  - status_code: 200
+    response_headers:
+      Content-Type: application/json
+    response_body: '{"items":[{"id":"2","price":50}],"total":3}'
+`, host), fmt.Sprintf(`
+host: %s
+path: /stocks
+method: GET
+responses:
+  - status_code: 200
+    response_body: '{"items":[{"id":"1","stock":5}],"total":1}'
+`, host), fmt.Sprintf(`
+host: %s
+path: /stocks/:id
+method: GET
+responses:
+  - status_code: 200
+`, host), fmt.Sprintf(`
+host: %s
+path: /orders
+method: GET
+responses:
+  - status_code: 200
+`, host))
+
+	reports, err := resolver.DetectDrift(context.Background(), DriftOptions{Scheme: "http"})
+	assert.Nil(t, err)
+
+	byDefinition := make(map[string]DriftReport)
+	for _, report := range reports {
+		byDefinition[strings.TrimPrefix(report.Definition, "GET "+host)] = report
+	}
+
+	assert.False(t, byDefinition["/products"].HasDrift())
+	assert.Equal(t, []string{
+		"body $.items[].id: mocked string, upstream number",
+		"body $.page: missing in mock",
+		"body $.total: missing in upstream",
+	}, byDefinition["/stocks"].Differences)
+	assert.Equal(t, "path contains parameters", byDefinition["/stocks/:id"].Skipped)
+	assert.Equal(t, []string{"status code: mocked 200, upstream 404"}, byDefinition["/orders"].Differences)
+}
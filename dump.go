@@ -0,0 +1,64 @@
+package mockhttp
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// DumpMiddleware returns Middleware that writes every intercepted request and the served (mocked or upstream) response
+// into numbered files in dir, ex: to diagnose the template output in CI where the logs are truncated.
+//
+// ex:
+//
+//	client.Use(mockhttp.DumpMiddleware("./debug"))
+//	// ./debug/0001_post_marketplace.com_check-price.txt
+//
+// Failing to write the dump never fails the request.
+func DumpMiddleware(dir string) Middleware {
+	var counter atomic.Uint64
+	return func(next DoFunc) DoFunc {
+		return func(req *Request) (*http.Response, error) {
+			dump := getBuffer()
+			defer putBuffer(dump)
+			if err := req.rewindBody(); err == nil {
+				body := req.Body
+				writeDump(dump, func() ([]byte, error) { return httputil.DumpRequestOut(req.Request, true) })
+				// the dump replace the consumed body (even empty body), keep the bodyless request as is.
+				if body == nil {
+					req.Body = nil
+				}
+			}
+
+			resp, err := next(req)
+
+			dump.WriteString("\n\n--- response ---\n")
+			switch {
+			case err != nil:
+				fmt.Fprintf(dump, "error: %s\n", err)
+			case resp != nil:
+				writeDump(dump, func() ([]byte, error) { return httputil.DumpResponse(resp, true) })
+			}
+
+			name := fmt.Sprintf("%04d_%s.txt", counter.Add(1), strings.TrimSuffix(recordFileName(req), ".yaml"))
+			if mkErr := os.MkdirAll(dir, 0o755); mkErr == nil {
+				_ = os.WriteFile(filepath.Join(dir, name), dump.Bytes(), 0o644)
+			}
+			return resp, err
+		}
+	}
+}
+
+func writeDump(buf *bytes.Buffer, dump func() ([]byte, error)) {
+	content, err := dump()
+	if err != nil {
+		fmt.Fprintf(buf, "unable to dump: %s\n", err)
+		return
+	}
+	buf.Write(content)
+}
@@ -0,0 +1,42 @@
+package mockhttp
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpMiddleware(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "debug")
+	client := newTestClient(newTestResolver(t, `
+host: marketplace.com
+path: /check-price
+method: GET
+responses:
+  - status_code: 200
+    enable_template: true
+    response_body: '{"sku": "{{ .sku }}"}'
+`))
+	client.Use(DumpMiddleware(dir))
+
+	req, err := NewRequest(http.MethodGet, "http://marketplace.com/check-price?sku=A1", nil)
+	assert.Nil(t, err)
+	req.Header.Set("X-Request-Id", "abc")
+	resp, err := client.Do(req)
+	assert.Nil(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"sku": "A1"}`, string(body))
+
+	dump, err := os.ReadFile(filepath.Join(dir, "0001_get_marketplace.com_check-price.txt"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(dump), "GET /check-price?sku=A1 HTTP/1.1")
+	assert.Contains(t, string(dump), "X-Request-Id: abc")
+	assert.Contains(t, string(dump), "--- response ---\nHTTP/1.1 200 OK")
+	assert.Contains(t, string(dump), `{"sku": "A1"}`)
+}
@@ -0,0 +1,167 @@
+package mockhttp
+
+import (
+	"context"
+	"net/http"
+)
+
+// Engine is the matching engine of the file based resolver, exposed as building blocks
+// for custom ResolverAdapter (ex: definitions stored in a database or served by a remote service),
+// so the requests are matched with the same semantics as the definition files:
+//
+//	engine := mockhttp.NewEngine()
+//
+//	func (a *dbResolver) Resolve(ctx context.Context, req *mockhttp.Request) (*http.Response, error) {
+//		definitions := a.definitionsOf(req.Host) // compiled once via engine.ParseDefinition
+//		return engine.Resolve(ctx, req, definitions)
+//	}
+//
+// The engine accepts the same options as the file based resolver (rule engine, rule functions, clock, body limit, etc),
+// the state store is shared by all the definitions matched by the engine.
+type Engine struct {
+	resolver *fileBasedResolver
+}
+
+// NewEngine returns new matching engine, with the same behavior as the file based resolver.
+// Relative schema file (rules `schema` / `response_schema`) is resolved from the working directory.
+//
+// param: opts (FileResolverOption) -> optional behavior of the engine.
+func NewEngine(opts ...FileResolverOption) *Engine {
+	return &Engine{resolver: NewMemoryResolverAdapter(opts...).(*fileBasedResolver)}
+}
+
+// Definition is a compiled mock definition (see Engine.ParseDefinition), safe for concurrent use.
+type Definition struct {
+	definition fileBasedMockDefinition
+}
+
+// ParseDefinition decode the mock definition spec (.yaml, same as the definition file)
+// and compile its path pattern and response rules, so the definition can be matched by the engine.
+func (e *Engine) ParseDefinition(spec []byte) (*Definition, error) {
+	definition, err := e.resolver.parseDefinition(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &Definition{definition: definition}, nil
+}
+
+// Name identify the definition by its http method, host and path, ex: GET marketplace.com/products/:id.
+func (d *Definition) Name() string {
+	return d.definition.name()
+}
+
+// Host returns the host matched by the definition.
+func (d *Definition) Host() string {
+	return d.definition.Host
+}
+
+// Method returns the http method matched by the definition.
+func (d *Definition) Method() string {
+	return d.definition.Method
+}
+
+// Path returns the path pattern matched by the definition, ex: /products/:id.
+func (d *Definition) Path() string {
+	return d.definition.Path
+}
+
+// MatchPath check whether the (cleaned) request path match the definition path pattern,
+// and returns the path params (ex: {"id": "1"} for /products/:id).
+func (d *Definition) MatchPath(path string) (map[string]string, bool) {
+	pattern := d.definition.pattern()
+	if !pattern.Match(path) {
+		return nil, false
+	}
+	return pattern.Params(path), true
+}
+
+// Match returns the definition matching the request host, http method and path, false when no definition matched.
+// The priorities are the same as the file based resolver: exact path, with path parameters, then with wildcard,
+// and the first definition (in order) for the same priority.
+func (e *Engine) Match(req *Request, definitions []*Definition) (*Definition, bool) {
+	request, err := buildIncomingRequest(req)
+	if err != nil {
+		return nil, false
+	}
+
+	var matched *Definition
+	for _, definition := range definitions {
+		if definition.definition.Host != request.Host || definition.definition.Method != request.Method {
+			continue
+		}
+		if matched != nil && rankOf(matched.definition) <= rankOf(definition.definition) {
+			continue
+		}
+		if definition.definition.pattern().Match(request.Endpoint) {
+			matched = definition
+		}
+	}
+	return matched, matched != nil
+}
+
+// EvalRule evaluate the rule expression (same as the definition file rules) against the request.
+// The request body is only read when the rule access it (body / raw).
+func (e *Engine) EvalRule(req *Request, rule string) (bool, error) {
+	r := e.resolver
+	request, err := buildIncomingRequest(req)
+	if err != nil {
+		return false, err
+	}
+
+	env := r.ruleEnv(&incomingRequest{})
+	compiled, err := r.ruleEngine.Compile(rule, env)
+	if err != nil {
+		return false, &RuleError{Rule: rule, Err: ErrInvalidRule, Cause: err}
+	}
+	if r.accessBody(rule, env) {
+		if err := req.rewindBody(); err != nil {
+			return false, err
+		}
+		if err := request.loadBody(r.maxBodySize, r.bodyLimitPolicy); err != nil {
+			return false, err
+		}
+	}
+
+	fulfilled, err := r.isRuleFulfilled(request, compiled)
+	if err != nil {
+		return false, &RuleError{Rule: rule, Err: ErrRuleEvaluation, Cause: err}
+	}
+	return fulfilled, nil
+}
+
+// Respond select the response of the definition fulfilled by the request (same as the file based resolver),
+// and generate the mock response: templating, delay, `set_state` and callbacks included.
+// Return nil with ErrNoMockResponse when no response of the definition can be served.
+func (e *Engine) Respond(ctx context.Context, req *Request, definition *Definition) (*http.Response, error) {
+	r := e.resolver
+	request, err := buildIncomingRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	mockResp, err := r.useDefinition(request, definition.definition, nil)
+	if request.Definition != "" {
+		SetMatchedDefinition(ctx, request.Definition)
+	}
+	if request.Response >= 0 {
+		SetMatchedResponse(ctx, request.Response)
+	}
+	reportRuleErrors(ctx, request.RuleErrors)
+	if err != nil {
+		return nil, err
+	}
+	if mockResp == nil {
+		return nil, ErrNoMockResponse
+	}
+	return r.serveResponse(ctx, req, request, mockResp)
+}
+
+// Resolve match the request against the definitions (see Match) and generate the mock response (see Respond),
+// the same as the file based resolver Resolve. Return nil with ErrNoMockResponse when no definition matched.
+func (e *Engine) Resolve(ctx context.Context, req *Request, definitions []*Definition) (*http.Response, error) {
+	definition, found := e.Match(req, definitions)
+	if !found {
+		return nil, ErrNoMockResponse
+	}
+	return e.Respond(ctx, req, definition)
+}
@@ -0,0 +1,113 @@
+package mockhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngine(t *testing.T) {
+	engine := NewEngine()
+
+	var definitions []*Definition
+	for _, spec := range []string{`
+host: marketplace.com
+path: /products/*
+method: GET
+responses:
+  - status_code: 404
+`, `
+host: marketplace.com
+path: /products/:id
+method: GET
+responses:
+  - status_code: 200
+    enable_template: true
+    response_body: '{"id": "{{ .id }}"}'
+`, `
+host: marketplace.com
+path: /products
+method: POST
+responses:
+  - status_code: 201
+    rules:
+      - body.price > 100
+`} {
+		definition, err := engine.ParseDefinition([]byte(spec))
+		assert.Nil(t, err)
+		definitions = append(definitions, definition)
+	}
+
+	t.Run("match path", func(t *testing.T) {
+		params, ok := definitions[1].MatchPath("/products/1")
+		assert.True(t, ok)
+		assert.Equal(t, map[string]string{"id": "1"}, params)
+
+		_, ok = definitions[1].MatchPath("/orders/1")
+		assert.False(t, ok)
+	})
+
+	t.Run("match by priority", func(t *testing.T) {
+		req, err := NewRequest(http.MethodGet, "http://marketplace.com/products/1", nil)
+		assert.Nil(t, err)
+		definition, found := engine.Match(req, definitions)
+		assert.True(t, found)
+		assert.Equal(t, "GET marketplace.com/products/:id", definition.Name())
+
+		req, err = NewRequest(http.MethodGet, "http://seller.com/products/1", nil)
+		assert.Nil(t, err)
+		_, found = engine.Match(req, definitions)
+		assert.False(t, found)
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		req, err := NewRequest(http.MethodGet, "http://marketplace.com/products/1", nil)
+		assert.Nil(t, err)
+		resp, err := engine.Resolve(context.Background(), req, definitions)
+		assert.Nil(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `{"id": "1"}`, string(body))
+	})
+
+	t.Run("rules", func(t *testing.T) {
+		newPost := func(body string) *Request {
+			req := newTestRequest(t, http.MethodPost, "http://marketplace.com/products", body)
+			req.Header.Set("Content-Type", "application/json")
+			return req
+		}
+
+		resp, err := engine.Resolve(context.Background(), newPost(`{"price": 200}`), definitions)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		_, err = engine.Resolve(context.Background(), newPost(`{"price": 50}`), definitions)
+		assert.True(t, errors.Is(err, ErrNoMockResponse))
+
+		req := newPost(`{"price": 200}`)
+		fulfilled, err := engine.EvalRule(req, `body.price > 100 && method == "POST"`)
+		assert.Nil(t, err)
+		assert.True(t, fulfilled)
+		// the request body can be evaluated again
+		fulfilled, err = engine.EvalRule(req, `body.price > 300`)
+		assert.Nil(t, err)
+		assert.False(t, fulfilled)
+
+		_, err = engine.EvalRule(req, `body.price >`)
+		assert.True(t, errors.Is(err, ErrInvalidRule))
+	})
+
+	t.Run("invalid definition", func(t *testing.T) {
+		_, err := engine.ParseDefinition([]byte(`
+host: marketplace.com
+path: /products
+method: GET
+strategy: unknown
+`))
+		assert.True(t, errors.Is(err, ErrUnknownStrategy))
+	})
+}
@@ -1,6 +1,10 @@
 package mockhttp
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var (
 	ErrDefinitionLoaded       = fmt.Errorf("mock definition had been loaded")
@@ -9,4 +13,132 @@ var (
 	ErrUnsupportedContentType = fmt.Errorf("unsupported content type")
 	ErrCommon                 = fmt.Errorf("common error")
 	ErrNoContentType          = fmt.Errorf("unable to find content type")
+	ErrUnknownStrategy        = fmt.Errorf("unknown response selection strategy")
+	ErrUnknownReplay          = fmt.Errorf("unknown replay policy")
+	ErrInvalidRule            = fmt.Errorf("invalid rule")
+	ErrRuleEvaluation         = fmt.Errorf("unable to evaluate rule")
+	ErrCircuitOpen            = fmt.Errorf("circuit breaker is open")
+	ErrMockBypassed           = fmt.Errorf("mock is bypassed for request")
+	ErrUnsupportedTransport   = fmt.Errorf("unsupported http transport")
+	ErrUnmatchedRequest       = fmt.Errorf("no mock response found for request in mock-only mode")
+	ErrInvalidDefinition      = fmt.Errorf("invalid mock definition")
+	ErrStubNotFound           = fmt.Errorf("stub not found")
+	ErrBodyTooLarge           = fmt.Errorf("request body too large")
+	ErrUnrecordablePath       = fmt.Errorf("path contains mock definition pattern characters (: * { })")
 )
+
+// DefinitionFileError describes why a mock definition file can't be loaded.
+type DefinitionFileError struct {
+	File string
+	Err  error
+}
+
+func (e *DefinitionFileError) Error() string {
+	return fmt.Sprintf("mock definition %s: %s", e.File, e.Err)
+}
+
+func (e *DefinitionFileError) Unwrap() error {
+	return e.Err
+}
+
+// LoadErrors lists every mock definition file skipped on partial load (WithPartialLoad).
+type LoadErrors []*DefinitionFileError
+
+func (e LoadErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("%d mock definition(s) failed to load: %s", len(e), strings.Join(messages, "; "))
+}
+
+// Is report whether any of the file errors matches target, so errors.Is(err, ErrUnknownStrategy) works on LoadErrors.
+func (e LoadErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentTypeError describes the request content type that can't be matched,
+// either missing (ErrNoContentType) or unsupported (ErrUnsupportedContentType).
+type ContentTypeError struct {
+	ContentType string
+	Err         error
+}
+
+func (e *ContentTypeError) Error() string {
+	if e.ContentType == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.Err, e.ContentType)
+}
+
+func (e *ContentTypeError) Unwrap() error {
+	return e.Err
+}
+
+// RuleError describes the rule that failed to compile (ErrInvalidRule) or to be evaluated (ErrRuleEvaluation),
+// so errors.Is(err, ErrInvalidRule) works along with errors.As(err, &ruleErr).
+type RuleError struct {
+	// Definition is the definition owning the rule (ex: POST marketplace.com/check-price), empty when unknown.
+	Definition string
+	// Rule is the rule expression, or the schema file of the schema rule.
+	Rule string
+	// Err is either ErrInvalidRule or ErrRuleEvaluation.
+	Err error
+	// Cause is the error reported by the rule engine (or schema parser), if any.
+	Cause error
+}
+
+func (e *RuleError) Error() string {
+	message := e.Err.Error()
+	if e.Rule != "" {
+		message += fmt.Sprintf(": %q", e.Rule)
+	}
+	if e.Cause != nil {
+		message += ": " + e.Cause.Error()
+	}
+	if e.Definition != "" {
+		message += " (" + e.Definition + ")"
+	}
+	return message
+}
+
+// Is report whether target is the kind of the rule error (ErrInvalidRule or ErrRuleEvaluation).
+func (e *RuleError) Is(target error) bool {
+	return target == e.Err
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Cause
+}
+
+// UnmatchedRequestError describes the request with no mock response in mock-only mode (ErrUnmatchedRequest).
+type UnmatchedRequestError struct {
+	Method string
+	URL    string
+}
+
+func (e *UnmatchedRequestError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrUnmatchedRequest, e.Method, e.URL)
+}
+
+func (e *UnmatchedRequestError) Unwrap() error {
+	return ErrUnmatchedRequest
+}
+
+// BodyTooLargeError describes the request body exceeding the max body size (ErrBodyTooLarge), see WithMaxBodySize.
+type BodyTooLargeError struct {
+	Limit int64
+}
+
+func (e *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("%s: exceeds %d bytes", ErrBodyTooLarge, e.Limit)
+}
+
+func (e *BodyTooLargeError) Unwrap() error {
+	return ErrBodyTooLarge
+}
@@ -0,0 +1,35 @@
+package mockhttp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors(t *testing.T) {
+	cause := errors.New("unexpected token EOF")
+
+	tests := []struct {
+		name    string
+		err     error
+		message string
+		is      error
+	}{
+		{"missing content type", &ContentTypeError{Err: ErrNoContentType}, "unable to find content type", ErrNoContentType},
+		{"unsupported content type", &ContentTypeError{ContentType: "text/csv", Err: ErrUnsupportedContentType}, "unsupported content type: text/csv", ErrUnsupportedContentType},
+		{"invalid rule", &RuleError{Definition: "POST marketplace.com/check-price", Rule: "body.name ==", Err: ErrInvalidRule, Cause: cause}, `invalid rule: "body.name ==": unexpected token EOF (POST marketplace.com/check-price)`, ErrInvalidRule},
+		{"rule evaluation", &RuleError{Rule: "body.price > 100", Err: ErrRuleEvaluation, Cause: cause}, `unable to evaluate rule: "body.price > 100": unexpected token EOF`, cause},
+		{"unmatched request", &UnmatchedRequestError{Method: "GET", URL: "http://marketplace.com/products"}, "no mock response found for request in mock-only mode: GET http://marketplace.com/products", ErrUnmatchedRequest},
+		{"body too large", &BodyTooLargeError{Limit: 32}, "request body too large: exceeds 32 bytes", ErrBodyTooLarge},
+		{"definition file", &DefinitionFileError{File: "products.yaml", Err: &RuleError{Err: ErrInvalidRule}}, "mock definition products.yaml: invalid rule", ErrInvalidRule},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, tt.err, tt.message)
+			assert.ErrorIs(t, tt.err, tt.is)
+		})
+	}
+
+	assert.NotErrorIs(t, &RuleError{Err: ErrInvalidRule}, ErrRuleEvaluation)
+}
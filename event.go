@@ -0,0 +1,99 @@
+package mockhttp
+
+import (
+	"time"
+)
+
+// EventType is the kind of the mock lifecycle event.
+type EventType string
+
+const (
+	// EventDefinitionLoaded is emitted by the resolver when the definitions are loaded (or reloaded).
+	EventDefinitionLoaded EventType = "definition_loaded"
+	// EventRuleError is emitted by the resolver when a rule failed to be evaluated.
+	EventRuleError EventType = "rule_error"
+	// EventCallbackError is emitted by the resolver when a mock response callback (webhook) failed to be delivered.
+	EventCallbackError EventType = "callback_error"
+	// EventMockHit is emitted by the client when the request is served from mock response.
+	EventMockHit EventType = "mock_hit"
+	// EventMockMiss is emitted by the client when no mock response is found for the request.
+	EventMockMiss EventType = "mock_miss"
+	// EventPassthrough is emitted by the client when the request is sent to the actual upstream service.
+	EventPassthrough EventType = "passthrough"
+	// EventFaultInjected is emitted by the client when the mock simulate a failure (ex: timeout).
+	EventFaultInjected EventType = "fault_injected"
+)
+
+// Event describes what mockhttp is doing, streamed to the registered event listeners.
+type Event struct {
+	Type EventType
+	Time time.Time
+
+	// Method and URL of the request, empty for definition loaded event.
+	Method string
+	URL    string
+
+	// Definition is the matched definition.
+	Definition string
+
+	// Definitions is the number of the loaded definitions, for definition loaded event.
+	Definitions int
+
+	// StatusCode of the upstream response for passthrough event.
+	StatusCode int
+
+	// Err is the miss reason, rule evaluation error, callback error, injected fault or upstream error.
+	Err error
+}
+
+// EventListener receive the mock lifecycle events. The listener is called synchronously, it should not block.
+type EventListener func(event Event)
+
+// ChannelListener returns EventListener that send the events into ch, so external tooling can stream them.
+// Events are dropped when ch is full, so a slow consumer never blocks the requests.
+func ChannelListener(ch chan<- Event) EventListener {
+	return func(event Event) {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// AddEventListener register the listener to receive the request events (mock hit, mock miss, passthrough and fault injected).
+// Register the listener into the resolver (ex: WithEventListener) to also receive the definition loaded, rule error and callback error events.
+//
+// AddEventListener is safe for concurrent use with Do.
+func (c *Client) AddEventListener(fn EventListener) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.eventListeners = append(c.eventListeners[:len(c.eventListeners):len(c.eventListeners)], fn)
+}
+
+// emit send the request event to all the client event listeners.
+func (s clientSnapshot) emit(eventType EventType, req *Request, info ResolveInfo, statusCode int, err error) {
+	if len(s.eventListeners) == 0 {
+		return
+	}
+
+	event := Event{
+		Type:       eventType,
+		Time:       time.Now(),
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Definition: info.Definition,
+		StatusCode: statusCode,
+		Err:        err,
+	}
+	for _, fn := range s.eventListeners {
+		fn(event)
+	}
+}
+
+// fileBasedResolver emit send the event to all the resolver event listeners.
+func (r *fileBasedResolver) emit(event Event) {
+	event.Time = time.Now()
+	for _, fn := range r.eventListeners {
+		fn(event)
+	}
+}
@@ -0,0 +1,73 @@
+package mockhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_EventListener(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer upstream.Close()
+
+	events := make(chan Event, 10)
+	resolver := newTestResolverWithFiles(t, map[string]string{
+		"products.yaml": `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 400
+    rules:
+      - queryParams.page > 1
+  - status_code: 200
+`,
+		"orders.yaml": `
+host: marketplace.com
+path: /orders
+method: GET
+responses:
+  - timeout: true
+`,
+	}, WithEventListener(ChannelListener(events)))
+	client := newTestClient(resolver)
+	client.AddEventListener(ChannelListener(events))
+
+	_, err := client.Get("http://marketplace.com/products?page=2")
+	assert.Nil(t, err)
+	_, err = client.Get("http://marketplace.com/orders")
+	assert.NotNil(t, err)
+	_, err = client.Get(upstream.URL)
+	assert.Nil(t, err)
+	close(events)
+
+	var types []EventType
+	for event := range events {
+		types = append(types, event.Type)
+		switch event.Type {
+		case EventRuleError:
+			assert.ErrorIs(t, event.Err, ErrRuleEvaluation)
+			assert.Equal(t, "GET marketplace.com/products", event.Definition)
+		case EventDefinitionLoaded:
+			assert.Equal(t, 2, event.Definitions)
+		case EventMockHit:
+			assert.NotEmpty(t, event.Definition)
+		case EventFaultInjected:
+			assert.Equal(t, "GET marketplace.com/orders", event.Definition)
+		case EventMockMiss:
+			assert.ErrorIs(t, event.Err, ErrNoMockResponse)
+		case EventPassthrough:
+			assert.Equal(t, http.StatusAccepted, event.StatusCode)
+		}
+	}
+	assert.Equal(t, []EventType{
+		EventDefinitionLoaded,
+		EventRuleError, EventMockHit,
+		EventMockHit, EventFaultInjected,
+		EventMockMiss, EventPassthrough,
+	}, types)
+}
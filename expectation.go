@@ -0,0 +1,34 @@
+package mockhttp
+
+// TestingT is the subset of testing.TB used to report the failed expectations.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertExpectations asserts that every required mock definition (`required: true`) was used
+// to serve at least one request, mirroring gomock-style strict expectations.
+//
+// ex:
+//
+//	defer client.AssertExpectations(t)
+//
+// The resolver must implement StatsReporter (the built-in file based resolver does).
+func (c *Client) AssertExpectations(t TestingT) bool {
+	t.Helper()
+
+	reporter, ok := c.snapshot().resolver.(StatsReporter)
+	if !ok {
+		t.Errorf("mockhttp: resolver does not report definition stats, expectations can't be asserted")
+		return false
+	}
+
+	passed := true
+	for _, stats := range reporter.Stats() {
+		if stats.Required && stats.Served() == 0 {
+			t.Errorf("mockhttp: required mock definition %s was never used", stats.Definition)
+			passed = false
+		}
+	}
+	return passed
+}
@@ -0,0 +1,40 @@
+package mockhttp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingT capture the failure messages, instead of failing the test.
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestClient_AssertExpectations(t *testing.T) {
+	for _, opts := range [][]FileResolverOption{nil, {WithLazyLoad()}} {
+		client := newTestClient(newTestResolverWithFiles(t, map[string]string{
+			"products.yaml": "host: marketplace.com\npath: /products\nmethod: GET\nrequired: true\nresponses:\n  - status_code: 200\n",
+			"orders.yaml":   "host: marketplace.com\npath: /orders\nmethod: GET\nrequired: true\nresponses:\n  - status_code: 200\n",
+			"health.yaml":   "host: marketplace.com\npath: /health\nmethod: GET\nresponses:\n  - status_code: 200\n",
+		}, opts...))
+
+		_, err := client.Get("http://marketplace.com/products")
+		assert.Nil(t, err)
+
+		rt := &recordingT{}
+		assert.False(t, client.AssertExpectations(rt))
+		assert.Equal(t, []string{"mockhttp: required mock definition GET marketplace.com/orders was never used"}, rt.errors)
+
+		_, err = client.Get("http://marketplace.com/orders")
+		assert.Nil(t, err)
+		assert.True(t, client.AssertExpectations(t))
+	}
+}
@@ -0,0 +1,257 @@
+package mockhttp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Explainer is implemented by resolver adapters that can explain (dry-run) how a request is resolved,
+// to debug "why did my request hit the wrong mock" without serving any mock response.
+//
+// The built-in file based resolver implements Explainer:
+//
+//	explanation, err := resolver.(mockhttp.Explainer).Explain(ctx, req)
+//	fmt.Println(explanation)
+type Explainer interface {
+	Explain(ctx context.Context, req *Request) (*Explanation, error)
+}
+
+// Explanation describes how a request is (or isn't) matched against the loaded mock definitions.
+type Explanation struct {
+	Method string
+	Host   string
+	Path   string
+
+	// Candidates are all the definitions with matching host and http method,
+	// ordered by the matching priorities (exact path, with path parameters, with wildcard).
+	Candidates []DefinitionExplanation
+
+	// Matched is the definition used to resolve the request, nil if no definition matched the request path.
+	Matched *DefinitionExplanation
+}
+
+// DefinitionExplanation describes how a single mock definition is evaluated against the request.
+type DefinitionExplanation struct {
+	Host     string
+	Method   string
+	Path     string
+	Desc     string
+	Strategy string
+
+	// PathMatched is true when the request path match the definition path pattern.
+	PathMatched bool
+
+	// Error is the reason the definition can't be used for the request (ex: unsupported content type).
+	Error error
+
+	// Responses are only evaluated for the matched definition.
+	Responses []ResponseExplanation
+}
+
+// ResponseExplanation describes how the rules of a single mock response are evaluated against the request.
+type ResponseExplanation struct {
+	Index      int
+	StatusCode int
+	Default    bool
+	// Exhausted is true when the response had been used for max_uses times, and can't be selected anymore.
+	Exhausted bool
+	// Stale is true when the recorded response is older than the max recording age (see WithMaxRecordingAge),
+	// and is skipped as if it is not defined.
+	Stale     bool
+	Fulfilled bool
+	Selected  bool
+	Rules     []RuleExplanation
+}
+
+// RuleExplanation describes the evaluation result of a single rule (or group of rules).
+type RuleExplanation struct {
+	// Rule is the rule expression, `schema: <path>` for schema rule, or `any_of` / `all_of` for group of rules.
+	Rule      string
+	Fulfilled bool
+	// Error is the rule evaluation error, or the reason the request body does not satisfy the schema rule.
+	Error  error
+	Nested []RuleExplanation
+}
+
+// fileBasedResolver Explain dry-run the resolve process for the request,
+// and return the explanation of every candidate definitions, responses and rules.
+//
+// Unlike Resolve, Explain does not generate mock response, trigger callbacks, advance round robin strategy,
+// nor report rule evaluation errors to the rule error handler.
+func (r *fileBasedResolver) Explain(ctx context.Context, req *Request) (*Explanation, error) {
+	request, err := buildIncomingRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &Explanation{
+		Method: request.Method,
+		Host:   request.Host,
+		Path:   request.Endpoint,
+	}
+
+	matched := -1
+	for _, fn := range r.definitionStores() {
+		for _, definition := range fn(request.Host, request.Method) {
+			candidate := DefinitionExplanation{
+				Host:        definition.Host,
+				Method:      definition.Method,
+				Path:        definition.Path,
+				Desc:        definition.Desc,
+				Strategy:    definition.Strategy,
+				PathMatched: definition.pattern().Match(request.Endpoint),
+			}
+
+			if candidate.PathMatched && matched < 0 {
+				matched = len(explanation.Candidates)
+				request.RouteParams = definition.pattern().Params(request.Endpoint)
+				// preview the call count without counting the dry-run as invocation
+				request.CallCount = int(definition.callCounter.Load()) + 1
+				definition, candidate.Error = r.parseLazy(definition)
+				candidate.Desc, candidate.Strategy = definition.Desc, definition.Strategy
+				if candidate.Error == nil && definition.needsBody() {
+					candidate.Error = request.loadBody(r.maxBodySize, r.bodyLimitPolicy)
+				}
+				if candidate.Error == nil {
+					candidate.Error = r.validateTarget(request)
+				}
+				if candidate.Error == nil {
+					candidate.Responses = r.explainResponses(request, definition)
+				}
+			}
+
+			explanation.Candidates = append(explanation.Candidates, candidate)
+		}
+	}
+
+	if matched >= 0 {
+		explanation.Matched = &explanation.Candidates[matched]
+	}
+	return explanation, nil
+}
+
+// fileBasedResolver explainResponses evaluate all the responses of the definition,
+// and mark the response that would be selected, following the same priorities as chooseResponse.
+func (r *fileBasedResolver) explainResponses(request *incomingRequest, definition fileBasedMockDefinition) []ResponseExplanation {
+	responses := make([]ResponseExplanation, 0, len(definition.Responses))
+	isSelected := false
+
+	for idx, response := range definition.Responses {
+		explained := ResponseExplanation{
+			Index:      idx,
+			StatusCode: response.StatusCode,
+			Default:    response.isDefault(),
+			Exhausted:  response.isExhausted(),
+			Stale:      !response.isExhausted() && !r.isServable(response),
+		}
+		if !explained.Default {
+			group := r.explainNode(request, ruleNode{AllOf: response.Rules})
+			explained.Rules = group.Nested
+			explained.Fulfilled = group.Fulfilled
+			explained.Selected = explained.Fulfilled && !explained.Exhausted && !explained.Stale && !isSelected
+			isSelected = isSelected || explained.Selected
+		}
+		responses = append(responses, explained)
+	}
+
+	// default response is only deterministic with `first` strategy
+	if !isSelected && (definition.Strategy == "" || definition.Strategy == strategyFirst) {
+		for idx := range responses {
+			if responses[idx].Default && !responses[idx].Exhausted && !responses[idx].Stale {
+				responses[idx].Selected = true
+				break
+			}
+		}
+	}
+	return responses
+}
+
+// fileBasedResolver explainNode evaluate the rule node recursively, similar to isNodeFulfilled,
+// but keep every evaluation result (and error) for the explanation.
+func (r *fileBasedResolver) explainNode(request *incomingRequest, node ruleNode) RuleExplanation {
+	if node.compiled != nil {
+		fulfilled, err := r.isRuleFulfilled(request, node.compiled)
+		return RuleExplanation{Rule: node.Expr, Fulfilled: fulfilled, Error: err}
+	}
+	if node.schema != nil {
+		err := isSchemaFulfilled(request, node.schema)
+		return RuleExplanation{Rule: "schema: " + node.Schema, Fulfilled: err == nil, Error: err}
+	}
+
+	explained := RuleExplanation{Rule: "all_of", Fulfilled: true}
+	children := node.AllOf
+	if len(node.AnyOf) > 0 {
+		explained = RuleExplanation{Rule: "any_of", Fulfilled: false}
+		children = node.AnyOf
+	}
+
+	for _, child := range children {
+		nested := r.explainNode(request, child)
+		explained.Nested = append(explained.Nested, nested)
+		if len(node.AnyOf) > 0 {
+			explained.Fulfilled = explained.Fulfilled || nested.Fulfilled
+		} else {
+			explained.Fulfilled = explained.Fulfilled && nested.Fulfilled
+		}
+	}
+	return explained
+}
+
+// String format the explanation into human readable text.
+func (e *Explanation) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "request: %s %s%s\n", e.Method, e.Host, e.Path)
+	if len(e.Candidates) == 0 {
+		sb.WriteString("no definition registered for the host and method\n")
+	}
+
+	for idx := range e.Candidates {
+		candidate := &e.Candidates[idx]
+		status := "path not matched"
+		if candidate.PathMatched {
+			status = "path matched"
+		}
+		if candidate == e.Matched {
+			status = "selected"
+		}
+		fmt.Fprintf(&sb, "- definition %s %s%s (%s)\n", candidate.Method, candidate.Host, candidate.Path, status)
+		if candidate.Error != nil {
+			fmt.Fprintf(&sb, "    error: %s\n", candidate.Error)
+		}
+
+		for _, response := range candidate.Responses {
+			marker := " "
+			if response.Selected {
+				marker = "*"
+			}
+			kind := "rules"
+			if response.Default {
+				kind = "default"
+			}
+			if response.Exhausted {
+				kind += ", exhausted"
+			}
+			if response.Stale {
+				kind += ", stale"
+			}
+			fmt.Fprintf(&sb, "  %s response #%d status %d (%s, fulfilled: %t)\n", marker, response.Index, response.StatusCode, kind, response.Fulfilled)
+			for _, rule := range response.Rules {
+				writeRuleExplanation(&sb, rule, 3)
+			}
+		}
+	}
+	return sb.String()
+}
+
+func writeRuleExplanation(sb *strings.Builder, rule RuleExplanation, depth int) {
+	indent := strings.Repeat("  ", depth)
+	fmt.Fprintf(sb, "%s%s => %t", indent, rule.Rule, rule.Fulfilled)
+	if rule.Error != nil {
+		fmt.Fprintf(sb, " (error: %s)", rule.Error)
+	}
+	sb.WriteString("\n")
+	for _, nested := range rule.Nested {
+		writeRuleExplanation(sb, nested, depth+1)
+	}
+}
@@ -0,0 +1,101 @@
+package mockhttp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileBasedResolver_Explain(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /orders/history
+method: GET
+responses:
+  - status_code: 200
+`, `
+host: marketplace.com
+path: /orders/:id
+method: GET
+responses:
+  - status_code: 200
+  - status_code: 404
+    rules:
+      - routeParams.id == "0"
+  - status_code: 403
+    rules:
+      - any_of:
+          - headers["X-Role"] == "guest"
+          - routeParams.id startsWith "internal-"
+`)
+
+	req := newTestRequest(t, http.MethodGet, "http://marketplace.com/orders/internal-1", "")
+
+	explanation, err := resolver.Explain(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Len(t, explanation.Candidates, 2)
+	assert.False(t, explanation.Candidates[0].PathMatched)
+	assert.True(t, explanation.Candidates[1].PathMatched)
+	assert.Equal(t, "/orders/:id", explanation.Matched.Path)
+
+	responses := explanation.Matched.Responses
+	assert.Len(t, responses, 3)
+	assert.False(t, responses[0].Selected)
+	assert.False(t, responses[1].Fulfilled)
+	assert.True(t, responses[2].Fulfilled)
+	assert.True(t, responses[2].Selected)
+	assert.Equal(t, "any_of", responses[2].Rules[0].Rule)
+	assert.False(t, responses[2].Rules[0].Nested[0].Fulfilled)
+	assert.True(t, responses[2].Rules[0].Nested[1].Fulfilled)
+
+	assert.Contains(t, explanation.String(), "definition GET marketplace.com/orders/:id (selected)")
+}
+
+func TestFileBasedResolver_ExplainMaxRecordingAge(t *testing.T) {
+	files := map[string]string{"products.yaml": `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - recorded_at: 2024-01-01T00:00:00Z
+    status_code: 200
+  - recorded_at: 2024-01-07T00:00:00Z
+    status_code: 201
+`}
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		maxAge     time.Duration
+		wantStale  []bool
+		wantStatus int
+	}{
+		{name: "without max recording age", wantStale: []bool{false, false}, wantStatus: http.StatusOK},
+		{name: "skip stale recording", maxAge: 7 * 24 * time.Hour, wantStale: []bool{true, false}, wantStatus: http.StatusCreated},
+		{name: "all recordings stale", maxAge: 24 * time.Hour, wantStale: []bool{true, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := newTestResolverWithFiles(t, files,
+				WithClock(func() time.Time { return now }),
+				WithMaxRecordingAge(tt.maxAge),
+			)
+
+			req := newTestRequest(t, http.MethodGet, "http://marketplace.com/products", "")
+			explanation, err := resolver.Explain(context.Background(), req)
+			assert.Nil(t, err)
+
+			selected := 0
+			for idx, response := range explanation.Matched.Responses {
+				assert.Equal(t, tt.wantStale[idx], response.Stale)
+				if response.Selected {
+					selected = response.StatusCode
+				}
+			}
+			assert.Equal(t, tt.wantStatus, selected)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package mockhttp
+
+import (
+	"io"
+	"net/http"
+)
+
+// hopByHopHeaders are the headers meaningful only for a single connection, that must not be forwarded by proxies.
+var hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+type forwardProxy struct {
+	client *Client
+}
+
+// NewForwardProxy creates HTTP forward proxy handler that send all the proxied requests via the client,
+// so the traffic of any (non-Go) service configured to use the proxy (ex: HTTP_PROXY) is mocked,
+// or recorded into mock definitions (with Client.Recorder) then served later (with the loaded resolver).
+//
+// ex:
+//
+//	client := mockhttp.NewClient(resolver)
+//	client.Recorder = mockhttp.NewFileRecorder("./mocks")
+//	http.ListenAndServe(":8080", mockhttp.NewForwardProxy(client))
+//
+// Only plain HTTP is supported, HTTPS tunneling (CONNECT) can't be intercepted and is rejected.
+func NewForwardProxy(client *Client) http.Handler {
+	return &forwardProxy{client: client}
+}
+
+func (p *forwardProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		http.Error(w, "mockhttp: CONNECT is not supported", http.StatusNotImplemented)
+		return
+	}
+	if !r.URL.IsAbs() {
+		http.Error(w, "mockhttp: proxied request must use absolute URL", http.StatusBadRequest)
+		return
+	}
+
+	outReq := r.Clone(r.Context())
+	outReq.RequestURI = ""
+	removeHopByHopHeaders(outReq.Header)
+	if r.ContentLength == 0 {
+		outReq.Body = nil
+	}
+
+	req, err := FromRequest(outReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	removeHopByHopHeaders(resp.Header)
+	for name, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+func removeHopByHopHeaders(header http.Header) {
+	for _, name := range hopByHopHeaders {
+		header.Del(name)
+	}
+}
@@ -0,0 +1,63 @@
+package mockhttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForwardProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	upstreamURL := upstream.URL
+
+	newProxiedClient := func(client *Client) (*http.Client, func()) {
+		proxy := httptest.NewServer(NewForwardProxy(client))
+		proxyURL, err := url.Parse(proxy.URL)
+		assert.Nil(t, err)
+		return &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}, proxy.Close
+	}
+
+	// record the traffic passing through the proxy
+	dir := t.TempDir()
+	recording := newTestClient(noMockResolver{})
+	recording.Recorder = NewFileRecorder(dir)
+	httpClient, closeProxy := newProxiedClient(recording)
+
+	resp, err := httpClient.Get(upstreamURL + "/products/1")
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, `{"id":1}`, string(body))
+	closeProxy()
+	upstream.Close()
+
+	// serve the recorded definitions, after the upstream is gone
+	resolver, err := NewFileResolverAdapter(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, resolver.LoadDefinition(context.Background()))
+	serving := newTestClient(resolver)
+	serving.MockOnly = true
+	httpClient, closeProxy = newProxiedClient(serving)
+	defer closeProxy()
+
+	resp, err = httpClient.Get(upstreamURL + "/products/1")
+	assert.Nil(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `{"id":1}`, string(body))
+
+	resp, err = httpClient.Get(upstreamURL + "/unknown")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+}
@@ -0,0 +1,228 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.23.4
+// source: admin.proto
+
+package adminpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	AdminService_LoadDefinitions_FullMethodName = "/mockhttp.admin.v1.AdminService/LoadDefinitions"
+	AdminService_AddStub_FullMethodName         = "/mockhttp.admin.v1.AdminService/AddStub"
+	AdminService_GetStats_FullMethodName        = "/mockhttp.admin.v1.AdminService/GetStats"
+	AdminService_ResetJournal_FullMethodName    = "/mockhttp.admin.v1.AdminService/ResetJournal"
+)
+
+// AdminServiceClient is the client API for AdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AdminServiceClient interface {
+	// LoadDefinitions reload the mock definitions from the source, the stubs are dropped.
+	LoadDefinitions(ctx context.Context, in *LoadDefinitionsRequest, opts ...grpc.CallOption) (*LoadDefinitionsResponse, error)
+	// AddStub create (or update) the stub from the mock definition spec, taking priority over the loaded definitions.
+	AddStub(ctx context.Context, in *AddStubRequest, opts ...grpc.CallOption) (*AddStubResponse, error)
+	// GetStats returns the hit counts of the loaded definitions.
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	// ResetJournal forget all the requests served by the mock server.
+	ResetJournal(ctx context.Context, in *ResetJournalRequest, opts ...grpc.CallOption) (*ResetJournalResponse, error)
+}
+
+type adminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminServiceClient(cc grpc.ClientConnInterface) AdminServiceClient {
+	return &adminServiceClient{cc}
+}
+
+func (c *adminServiceClient) LoadDefinitions(ctx context.Context, in *LoadDefinitionsRequest, opts ...grpc.CallOption) (*LoadDefinitionsResponse, error) {
+	out := new(LoadDefinitionsResponse)
+	err := c.cc.Invoke(ctx, AdminService_LoadDefinitions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) AddStub(ctx context.Context, in *AddStubRequest, opts ...grpc.CallOption) (*AddStubResponse, error) {
+	out := new(AddStubResponse)
+	err := c.cc.Invoke(ctx, AdminService_AddStub_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, AdminService_GetStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ResetJournal(ctx context.Context, in *ResetJournalRequest, opts ...grpc.CallOption) (*ResetJournalResponse, error) {
+	out := new(ResetJournalResponse)
+	err := c.cc.Invoke(ctx, AdminService_ResetJournal_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServiceServer is the server API for AdminService service.
+// All implementations must embed UnimplementedAdminServiceServer
+// for forward compatibility
+type AdminServiceServer interface {
+	// LoadDefinitions reload the mock definitions from the source, the stubs are dropped.
+	LoadDefinitions(context.Context, *LoadDefinitionsRequest) (*LoadDefinitionsResponse, error)
+	// AddStub create (or update) the stub from the mock definition spec, taking priority over the loaded definitions.
+	AddStub(context.Context, *AddStubRequest) (*AddStubResponse, error)
+	// GetStats returns the hit counts of the loaded definitions.
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	// ResetJournal forget all the requests served by the mock server.
+	ResetJournal(context.Context, *ResetJournalRequest) (*ResetJournalResponse, error)
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+// UnimplementedAdminServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedAdminServiceServer struct {
+}
+
+func (UnimplementedAdminServiceServer) LoadDefinitions(context.Context, *LoadDefinitionsRequest) (*LoadDefinitionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LoadDefinitions not implemented")
+}
+func (UnimplementedAdminServiceServer) AddStub(context.Context, *AddStubRequest) (*AddStubResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddStub not implemented")
+}
+func (UnimplementedAdminServiceServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedAdminServiceServer) ResetJournal(context.Context, *ResetJournalRequest) (*ResetJournalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResetJournal not implemented")
+}
+func (UnimplementedAdminServiceServer) mustEmbedUnimplementedAdminServiceServer() {}
+
+// UnsafeAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServiceServer will
+// result in compilation errors.
+type UnsafeAdminServiceServer interface {
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+func RegisterAdminServiceServer(s grpc.ServiceRegistrar, srv AdminServiceServer) {
+	s.RegisterService(&AdminService_ServiceDesc, srv)
+}
+
+func _AdminService_LoadDefinitions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoadDefinitionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).LoadDefinitions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_LoadDefinitions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).LoadDefinitions(ctx, req.(*LoadDefinitionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_AddStub_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddStubRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).AddStub(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_AddStub_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).AddStub(ctx, req.(*AddStubRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ResetJournal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetJournalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ResetJournal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ResetJournal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ResetJournal(ctx, req.(*ResetJournalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AdminService_ServiceDesc is the grpc.ServiceDesc for AdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mockhttp.admin.v1.AdminService",
+	HandlerType: (*AdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "LoadDefinitions",
+			Handler:    _AdminService_LoadDefinitions_Handler,
+		},
+		{
+			MethodName: "AddStub",
+			Handler:    _AdminService_AddStub_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _AdminService_GetStats_Handler,
+		},
+		{
+			MethodName: "ResetJournal",
+			Handler:    _AdminService_ResetJournal_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "admin.proto",
+}
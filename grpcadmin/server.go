@@ -0,0 +1,113 @@
+// Package grpcadmin provides gRPC control surface (adminpb.AdminService) for the standalone mock server,
+// so orchestration tooling can manage the mock state with strong typing instead of ad-hoc JSON.
+//
+// ex:
+//
+//	grpcServer := grpc.NewServer()
+//	adminpb.RegisterAdminServiceServer(grpcServer, grpcadmin.NewServer(resolver, client))
+//	grpcServer.Serve(listener)
+package grpcadmin
+
+//go:generate protoc -I adminpb --go_out=adminpb --go_opt=paths=source_relative --go-grpc_out=adminpb --go-grpc_opt=paths=source_relative admin.proto
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mockhttp "github.com/William9923/go-mockhttp"
+	"github.com/William9923/go-mockhttp/grpcadmin/adminpb"
+)
+
+// Server implements adminpb.AdminServiceServer on top of the resolver (and the client serving the requests).
+// RPC requiring capability that the resolver does not implement (ex: mockhttp.StubManager) fails with Unimplemented.
+type Server struct {
+	adminpb.UnimplementedAdminServiceServer
+
+	resolver mockhttp.ResolverAdapter
+	client   *mockhttp.Client
+}
+
+// NewServer creates the admin service of the resolver. The client is the one serving the requests
+// (ex: via mockhttp.ClientHandler), used to reset the journal, it can be nil.
+func NewServer(resolver mockhttp.ResolverAdapter, client *mockhttp.Client) *Server {
+	return &Server{resolver: resolver, client: client}
+}
+
+// LoadDefinitions reload the mock definitions of the resolver (mockhttp.Reloader),
+// or load them when the resolver can't be reloaded.
+func (s *Server) LoadDefinitions(ctx context.Context, req *adminpb.LoadDefinitionsRequest) (*adminpb.LoadDefinitionsResponse, error) {
+	var err error
+	if reloader, ok := s.resolver.(mockhttp.Reloader); ok {
+		err = reloader.Reload(ctx)
+	} else {
+		err = s.resolver.LoadDefinition(ctx)
+	}
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	resp := &adminpb.LoadDefinitionsResponse{}
+	if reporter, ok := s.resolver.(mockhttp.StatsReporter); ok {
+		resp.Definitions = int32(len(reporter.Stats()))
+	}
+	return resp, nil
+}
+
+// AddStub create (or update) the stub with the mock definition spec (mockhttp.StubManager).
+func (s *Server) AddStub(ctx context.Context, req *adminpb.AddStubRequest) (*adminpb.AddStubResponse, error) {
+	manager, ok := s.resolver.(mockhttp.StubManager)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "resolver does not support stubs")
+	}
+
+	id := req.GetId()
+	if id == "" {
+		generated, err := mockhttp.NewStubID()
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		id = generated
+	}
+	if _, err := manager.PutStub(ctx, id, []byte(req.GetSpec())); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &adminpb.AddStubResponse{Id: id}, nil
+}
+
+// GetStats returns the hit counts of the loaded definitions (mockhttp.StatsReporter).
+func (s *Server) GetStats(ctx context.Context, req *adminpb.GetStatsRequest) (*adminpb.GetStatsResponse, error) {
+	reporter, ok := s.resolver.(mockhttp.StatsReporter)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "resolver does not report definition stats")
+	}
+
+	resp := &adminpb.GetStatsResponse{}
+	for _, stats := range reporter.Stats() {
+		definition := &adminpb.DefinitionStats{
+			Definition: stats.Definition,
+			File:       stats.File,
+			Required:   stats.Required,
+			Hits:       stats.Hits,
+		}
+		for _, response := range stats.Responses {
+			definition.Responses = append(definition.Responses, &adminpb.ResponseStats{
+				Index:      int32(response.Index),
+				StatusCode: int32(response.StatusCode),
+				Hits:       response.Hits,
+			})
+		}
+		resp.Definitions = append(resp.Definitions, definition)
+	}
+	return resp, nil
+}
+
+// ResetJournal forget all the requests served by the client.
+func (s *Server) ResetJournal(ctx context.Context, req *adminpb.ResetJournalRequest) (*adminpb.ResetJournalResponse, error) {
+	if s.client == nil {
+		return nil, status.Error(codes.Unimplemented, "no client to reset the journal of")
+	}
+	s.client.ResetJournal()
+	return &adminpb.ResetJournalResponse{}, nil
+}
@@ -0,0 +1,104 @@
+package grpcadmin
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	mockhttp "github.com/William9923/go-mockhttp"
+	"github.com/William9923/go-mockhttp/grpcadmin/adminpb"
+)
+
+func newTestAdminClient(t *testing.T, server *Server) adminpb.AdminServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	adminpb.RegisterAdminServiceServer(grpcServer, server)
+	go func() { _ = grpcServer.Serve(listener) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.Nil(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return adminpb.NewAdminServiceClient(conn)
+}
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	definition := "host: marketplace.com\npath: /products\nmethod: GET\nresponses:\n  - status_code: 200\n"
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "products.yaml"), []byte(definition), 0o644))
+
+	resolver, err := mockhttp.NewFileResolverAdapter(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, resolver.LoadDefinition(context.Background()))
+	client := mockhttp.NewClient(resolver)
+	client.Logger = nil
+
+	admin := newTestAdminClient(t, NewServer(resolver, client))
+	ctx := context.Background()
+
+	stub, err := admin.AddStub(ctx, &adminpb.AddStubRequest{Id: "checkout", Spec: "host: marketplace.com\npath: /checkout\nmethod: GET\nresponses:\n  - status_code: 503\n"})
+	assert.Nil(t, err)
+	assert.Equal(t, "checkout", stub.GetId())
+
+	_, err = admin.AddStub(ctx, &adminpb.AddStubRequest{Spec: "host: [marketplace.com"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	resp, err := client.Get("http://marketplace.com/checkout")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Len(t, client.Journal(), 1)
+
+	stats, err := admin.GetStats(ctx, &adminpb.GetStatsRequest{})
+	assert.Nil(t, err)
+	assert.Len(t, stats.GetDefinitions(), 2)
+	assert.Equal(t, "GET marketplace.com/checkout", stats.GetDefinitions()[0].GetDefinition())
+	assert.Equal(t, uint64(1), stats.GetDefinitions()[0].GetHits())
+	assert.Equal(t, int32(503), stats.GetDefinitions()[0].GetResponses()[0].GetStatusCode())
+
+	_, err = admin.ResetJournal(ctx, &adminpb.ResetJournalRequest{})
+	assert.Nil(t, err)
+	assert.Empty(t, client.Journal())
+
+	// reload drop the stubs
+	loaded, err := admin.LoadDefinitions(ctx, &adminpb.LoadDefinitionsRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), loaded.GetDefinitions())
+}
+
+func TestServer_Unimplemented(t *testing.T) {
+	admin := newTestAdminClient(t, NewServer(noopResolver{}, nil))
+	ctx := context.Background()
+
+	_, err := admin.AddStub(ctx, &adminpb.AddStubRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+	_, err = admin.GetStats(ctx, &adminpb.GetStatsRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+	_, err = admin.ResetJournal(ctx, &adminpb.ResetJournalRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+	_, err = admin.LoadDefinitions(ctx, &adminpb.LoadDefinitionsRequest{})
+	assert.Nil(t, err)
+}
+
+type noopResolver struct{}
+
+func (noopResolver) LoadDefinition(ctx context.Context) error { return nil }
+
+func (noopResolver) Resolve(ctx context.Context, req *mockhttp.Request) (*http.Response, error) {
+	return nil, mockhttp.ErrNoMockResponse
+}
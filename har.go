@@ -0,0 +1,163 @@
+package mockhttp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// HAR (HTTP Archive) 1.2 format, see http://www.softwareishard.com/blog/har-12-spec/
+type harLog struct {
+	Log harContent `json:"log"`
+}
+
+type harContent struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harBody        `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harBody struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// ExportHAR write the journal as HAR (HTTP Archive) 1.2, so the captured mock / real traffic can be inspected
+// in browser devtools or shared with the API owners.
+//
+// The request / response bodies are not kept by the journal, so the body sizes are reported as unknown (-1).
+// Mocked entries are commented with the matched definition, and failed requests are commented with the error.
+func (j Journal) ExportHAR(w io.Writer) error {
+	entries := make([]harEntry, 0, len(j))
+	for _, entry := range j {
+		entries = append(entries, entry.har())
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(harLog{Log: harContent{
+		Version: "1.2",
+		Creator: harCreator{Name: "mockhttp", Version: "1.0"},
+		Entries: entries,
+	}})
+}
+
+func (e JournalEntry) har() harEntry {
+	millis := float64(e.Duration) / float64(time.Millisecond)
+
+	comment := ""
+	switch {
+	case e.Err != nil:
+		comment = "error: " + e.Err.Error()
+	case e.Info.Mocked:
+		comment = "mocked: " + e.Info.Definition
+	case e.Info.Bypassed:
+		comment = "bypassed"
+	}
+
+	req := &http.Request{Header: e.Header}
+	resp := &http.Response{Header: e.ResponseHeader}
+	return harEntry{
+		StartedDateTime: e.Time.Format(time.RFC3339Nano),
+		Time:            millis,
+		Request: harRequest{
+			Method:      e.Method,
+			URL:         e.URL.String(),
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     harCookies(req.Cookies()),
+			Headers:     harHeaders(e.Header),
+			QueryString: harHeaders(http.Header(e.URL.Query())),
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Response: harResponse{
+			Status:      e.StatusCode,
+			StatusText:  http.StatusText(e.StatusCode),
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     harCookies(resp.Cookies()),
+			Headers:     harHeaders(e.ResponseHeader),
+			Content:     harBody{Size: -1, MimeType: e.ResponseHeader.Get("Content-Type")},
+			RedirectURL: e.ResponseHeader.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: harTimings{Send: 0, Wait: millis, Receive: 0},
+		Comment: comment,
+	}
+}
+
+// harHeaders flatten the headers (or query params) sorted by name, as HAR name-value pairs.
+func harHeaders(header http.Header) []harNameValue {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := []harNameValue{}
+	for _, name := range names {
+		for _, value := range header[name] {
+			pairs = append(pairs, harNameValue{Name: name, Value: value})
+		}
+	}
+	return pairs
+}
+
+func harCookies(cookies []*http.Cookie) []harNameValue {
+	pairs := []harNameValue{}
+	for _, cookie := range cookies {
+		pairs = append(pairs, harNameValue{Name: cookie.Name, Value: cookie.Value})
+	}
+	return pairs
+}
@@ -0,0 +1,63 @@
+package mockhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournal_ExportHAR(t *testing.T) {
+	client := newTestClient(newTestResolver(t, `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+    response_headers:
+      Content-Type: application/json
+    response_body: '[]'
+`))
+
+	req, err := NewRequest(http.MethodGet, "http://marketplace.com/products?page=2", nil)
+	assert.Nil(t, err)
+	req.Header.Set("X-Request-Id", "abc")
+	_, err = client.Do(req)
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	assert.Nil(t, client.Journal().ExportHAR(&buf))
+
+	var har struct {
+		Log struct {
+			Version string `json:"version"`
+			Entries []struct {
+				Request struct {
+					Method      string         `json:"method"`
+					URL         string         `json:"url"`
+					Headers     []harNameValue `json:"headers"`
+					QueryString []harNameValue `json:"queryString"`
+				} `json:"request"`
+				Response struct {
+					Status  int     `json:"status"`
+					Content harBody `json:"content"`
+				} `json:"response"`
+				Comment string `json:"comment"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &har))
+	assert.Equal(t, "1.2", har.Log.Version)
+	assert.Len(t, har.Log.Entries, 1)
+
+	entry := har.Log.Entries[0]
+	assert.Equal(t, http.MethodGet, entry.Request.Method)
+	assert.Equal(t, "http://marketplace.com/products?page=2", entry.Request.URL)
+	assert.Contains(t, entry.Request.Headers, harNameValue{Name: "X-Request-Id", Value: "abc"})
+	assert.Equal(t, []harNameValue{{Name: "page", Value: "2"}}, entry.Request.QueryString)
+	assert.Equal(t, http.StatusOK, entry.Response.Status)
+	assert.Equal(t, harBody{Size: -1, MimeType: "application/json"}, entry.Response.Content)
+	assert.Equal(t, "mocked: GET marketplace.com/products", entry.Comment)
+}
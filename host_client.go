@@ -0,0 +1,42 @@
+package mockhttp
+
+import (
+	"net/http"
+	"strings"
+)
+
+// SetHostClient register the HTTP client used for the passthrough (non-mocked) requests to the upstream host,
+// instead of HTTPClient. Useful when some upstreams need different timeouts, transports (ex: mTLS) or proxies.
+//
+// The host is matched against the request host with port first (ex: "api.example.com:8443"), then without port.
+// Passing nil client unregister the host client. It is safe for concurrent use with Do.
+func (c *Client) SetHostClient(host string, client *http.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hostClients := make(map[string]*http.Client, len(c.hostClients)+1)
+	for h, hc := range c.hostClients {
+		hostClients[h] = hc
+	}
+	host = strings.ToLower(host)
+	if client == nil {
+		delete(hostClients, host)
+	} else {
+		hostClients[host] = client
+	}
+	c.hostClients = hostClients
+}
+
+// httpClient returns the HTTP client registered for the request host, or the default HTTPClient.
+func (s clientSnapshot) httpClient(req *Request, fallback *http.Client) *http.Client {
+	if len(s.hostClients) == 0 {
+		return fallback
+	}
+	if client, ok := s.hostClients[strings.ToLower(req.URL.Host)]; ok {
+		return client
+	}
+	if client, ok := s.hostClients[strings.ToLower(req.URL.Hostname())]; ok {
+		return client
+	}
+	return fallback
+}
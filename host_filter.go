@@ -0,0 +1,38 @@
+package mockhttp
+
+import (
+	"net/url"
+	"strings"
+)
+
+// HostFilter decide which upstream hosts are intercepted (mocked / recorded) by the client,
+// the requests to the other hosts bypass the mock resolver and the recorder.
+//
+// Host pattern is either exact host (ex: "api.example.com", "api.example.com:8443")
+// or wildcard subdomain (ex: "*.example.com"), case insensitive.
+type HostFilter struct {
+	// Allow is the allowlist of intercepted hosts, empty allowlist allow all hosts.
+	Allow []string
+	// Deny is the denylist of hosts that are never intercepted, it takes precedence over the allowlist.
+	Deny []string
+}
+
+// Match check whether the request URL host should be intercepted. Nil filter match all hosts.
+func (f *HostFilter) Match(u *url.URL) bool {
+	if f == nil {
+		return true
+	}
+	if some[string](f.Deny, func(pattern string) bool { return matchHost(pattern, u) }) {
+		return false
+	}
+	return len(f.Allow) == 0 || some[string](f.Allow, func(pattern string) bool { return matchHost(pattern, u) })
+}
+
+func matchHost(pattern string, u *url.URL) bool {
+	pattern = strings.ToLower(pattern)
+	host, hostname := strings.ToLower(u.Host), strings.ToLower(u.Hostname())
+	if suffix := strings.TrimPrefix(pattern, "*"); suffix != pattern {
+		return strings.HasSuffix(hostname, suffix) || strings.HasSuffix(host, suffix)
+	}
+	return pattern == host || pattern == hostname
+}
@@ -0,0 +1,60 @@
+package mockhttp
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostFilter_Match(t *testing.T) {
+	filter := &HostFilter{
+		Allow: []string{"*.marketplace.com", "payment.com"},
+		Deny:  []string{"auth.marketplace.com"},
+	}
+
+	tests := []struct {
+		rawURL string
+		want   bool
+	}{
+		{rawURL: "http://api.marketplace.com/products", want: true},
+		{rawURL: "http://API.Marketplace.com:8080/products", want: true},
+		{rawURL: "https://payment.com/charges", want: true},
+		{rawURL: "http://auth.marketplace.com/login", want: false},
+		{rawURL: "http://analytics.com/events", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rawURL, func(t *testing.T) {
+			u, err := url.Parse(tt.rawURL)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, filter.Match(u))
+		})
+	}
+
+	var nilFilter *HostFilter
+	u, _ := url.Parse("http://analytics.com/events")
+	assert.True(t, nilFilter.Match(u))
+}
+
+func TestClient_Do_HostFilter(t *testing.T) {
+	client := newTestClient(staticResolver{statusCode: http.StatusTeapot})
+	client.HostFilter = &HostFilter{Deny: []string{"analytics.com"}}
+
+	var infos []ResolveInfo
+	client.ResolveHook = func(req *Request, info ResolveInfo) {
+		infos = append(infos, info)
+	}
+	client.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+
+	resp, err := client.Get("http://marketplace.com/products")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+
+	resp, err = client.Get("http://analytics.com/events")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, infos[1].Bypassed)
+}
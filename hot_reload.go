@@ -0,0 +1,186 @@
+package mockhttp
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce is the quiet period after the last file change before the definitions are reloaded,
+// so editors writing a file in multiple steps (truncate, write, rename) trigger a single reload.
+const reloadDebounce = 100 * time.Millisecond
+
+// Reloader is implemented by resolver adapters that can re-read the mock definitions from the source after being loaded.
+//
+// The built-in file based resolver implements Reloader:
+//
+//	err := resolver.(mockhttp.Reloader).Reload(ctx)
+type Reloader interface {
+	Reload(ctx context.Context) error
+}
+
+// fileBasedResolver Reload
+// Re-read all the mock definition specs file (.yaml) from the dir, and swap the registered definitions at once,
+// so in-flight Resolve calls use either the previous or the new definitions.
+//
+// On error, the previously loaded definitions are kept (except on partial load mode, where the valid definitions are swapped in).
+// Unlike LoadDefinition, Reload can be called multiple times (also before LoadDefinition).
+func (r *fileBasedResolver) Reload(ctx context.Context) error {
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+
+	definitions, err := r.readDefinitions()
+	if definitions == nil {
+		return err
+	}
+	r.setDefinitions(definitions)
+
+	r.isLoaded.Store(true)
+	return err
+}
+
+// Watcher is implemented by resolver adapters that can reload the mock definitions when the underlying source changed,
+// so developers iterating on mock bodies during manual testing don't need to restart the service.
+//
+// The built-in file based resolver implements Watcher:
+//
+//	err := resolver.(mockhttp.Watcher).Watch(ctx, func(err error) { log.Println(err) })
+type Watcher interface {
+	Watch(ctx context.Context, onError func(error)) error
+}
+
+// fileBasedResolver Watch
+// Watch the mock definition directory, and reload all the definitions when any file is changed, added or removed,
+// until ctx is done. The JSON Schema files referenced by the registered definitions (response_schema and schema rules)
+// are watched as well, even when located in other directories (ex: schemas/order.json).
+// On lazy load mode (WithLazyLoad), only the schema files of the definitions already parsed on reload are watched.
+//
+// Invalid definitions are reported to onError (if any), and the previously loaded definitions are kept,
+// so a half-edited file does not break the running service.
+func (r *fileBasedResolver) Watch(ctx context.Context, onError func(error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(r.dir); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	report := func(err error) {
+		if onError != nil {
+			onError(err)
+		}
+	}
+
+	// schemaDirs are the watched directories (other than the definition directory) of the referenced schema files.
+	dir := filepath.Clean(r.dir)
+	schemas := r.schemaFiles()
+	schemaDirs := make(map[string]bool)
+	watchSchemas := func() {
+		schemas = r.schemaFiles()
+		dirs := make(map[string]bool)
+		for file := range schemas {
+			if schemaDir := filepath.Dir(file); schemaDir != dir {
+				dirs[schemaDir] = true
+			}
+		}
+		for schemaDir := range dirs {
+			if schemaDirs[schemaDir] {
+				continue
+			}
+			if err := watcher.Add(schemaDir); err != nil {
+				report(err)
+				continue
+			}
+			schemaDirs[schemaDir] = true
+		}
+		for schemaDir := range schemaDirs {
+			if !dirs[schemaDir] {
+				_ = watcher.Remove(schemaDir)
+				delete(schemaDirs, schemaDir)
+			}
+		}
+	}
+	watchSchemas()
+
+	go func() {
+		defer watcher.Close()
+
+		debounce := time.NewTimer(reloadDebounce)
+		debounce.Stop()
+		defer debounce.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Chmod) || filepath.Base(event.Name)[0] == '.' {
+					continue
+				}
+				// only the referenced schema files matter outside of the definition directory
+				if filepath.Dir(event.Name) != dir && !schemas[filepath.Clean(event.Name)] {
+					continue
+				}
+				debounce.Reset(reloadDebounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				report(err)
+			case <-debounce.C:
+				if err := r.Reload(ctx); err != nil {
+					report(err)
+				}
+				watchSchemas()
+			}
+		}
+	}()
+	return nil
+}
+
+// fileBasedResolver schemaFiles
+// Returns the (cleaned) path of every JSON Schema file referenced by the registered definitions,
+// resolved the same way as loadSchema.
+func (r *fileBasedResolver) schemaFiles() map[string]bool {
+	files := make(map[string]bool)
+	add := func(path string) {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(r.dir, path)
+		}
+		files[filepath.Clean(path)] = true
+	}
+
+	var addRules func(rules []ruleNode)
+	addRules = func(rules []ruleNode) {
+		for _, node := range rules {
+			if node.Schema != "" {
+				add(node.Schema)
+			}
+			addRules(node.AnyOf)
+			addRules(node.AllOf)
+		}
+	}
+
+	for _, definition := range r.loadedDefinitions() {
+		if lazy := definition.lazy; lazy != nil {
+			if !lazy.parsed.Load() {
+				continue
+			}
+			definition = lazy.definition
+		}
+		for _, response := range definition.Responses {
+			if response.SchemaFile != "" {
+				add(response.SchemaFile)
+			}
+			addRules(response.Rules)
+		}
+	}
+	return files
+}
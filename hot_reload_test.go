@@ -0,0 +1,172 @@
+package mockhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileBasedResolver_Watch(t *testing.T) {
+	definition := `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: %d
+`
+	resolver := newTestResolverWithFiles(t, map[string]string{"products.yaml": fmt.Sprintf(definition, http.StatusOK)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloadErrs := make(chan error, 10)
+	assert.Nil(t, resolver.Watch(ctx, func(err error) { reloadErrs <- err }))
+
+	statusCode := func(url string) int {
+		resp, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, url, ""))
+		if errors.Is(err, ErrNoMockResponse) {
+			return 0
+		}
+		assert.Nil(t, err)
+		return resp.StatusCode
+	}
+	writeFile := func(name, content string) {
+		assert.Nil(t, os.WriteFile(filepath.Join(resolver.dir, name), []byte(content), 0o644))
+	}
+
+	t.Run("changed file", func(t *testing.T) {
+		writeFile("products.yaml", fmt.Sprintf(definition, http.StatusCreated))
+		assert.Eventually(t, func() bool {
+			return statusCode("http://marketplace.com/products") == http.StatusCreated
+		}, 2*time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("added file", func(t *testing.T) {
+		writeFile("orders.yaml", "host: marketplace.com\npath: /orders\nmethod: GET\nresponses:\n  - status_code: 202\n")
+		assert.Eventually(t, func() bool {
+			return statusCode("http://marketplace.com/orders") == http.StatusAccepted
+		}, 2*time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("removed file", func(t *testing.T) {
+		assert.Nil(t, os.Remove(filepath.Join(resolver.dir, "orders.yaml")))
+		assert.Eventually(t, func() bool {
+			return statusCode("http://marketplace.com/orders") == 0
+		}, 2*time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("invalid file keep previous definitions", func(t *testing.T) {
+		writeFile("products.yaml", "host: [marketplace.com")
+		select {
+		case err := <-reloadErrs:
+			assert.NotNil(t, err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("reload error is not reported")
+		}
+		assert.Equal(t, http.StatusCreated, statusCode("http://marketplace.com/products"))
+	})
+}
+
+func TestFileBasedResolver_Watch_SchemaSubdirectory(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "schemas"), 0o755))
+	schemaFile := filepath.Join(dir, "schemas", "order.json")
+	writeSchema := func(required string) {
+		schema := `{"type": "object", "required": ["` + required + `"]}`
+		assert.Nil(t, os.WriteFile(schemaFile, []byte(schema), 0o644))
+	}
+	writeSchema("id")
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "orders.yaml"), []byte(`
+host: marketplace.com
+path: /orders
+method: POST
+responses:
+  - status_code: 201
+    rules:
+      - schema: schemas/order.json
+  - status_code: 400
+`), 0o644))
+
+	adapter, err := NewFileResolverAdapter(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, adapter.LoadDefinition(context.Background()))
+	resolver := adapter.(*fileBasedResolver)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.Nil(t, resolver.Watch(ctx, nil))
+
+	statusCode := func() int {
+		req := newTestRequest(t, http.MethodPost, "http://marketplace.com/orders", `{"id": 1}`)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := resolver.Resolve(context.Background(), req)
+		assert.Nil(t, err)
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusCreated, statusCode())
+
+	writeSchema("name")
+	assert.Eventually(t, func() bool {
+		return statusCode() == http.StatusBadRequest
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestFileBasedResolver_Reload(t *testing.T) {
+	resolver := newTestResolverWithFiles(t, map[string]string{"products.yaml": `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+`})
+	assert.ErrorIs(t, resolver.LoadDefinition(context.Background()), ErrDefinitionLoaded)
+
+	statusCode := func() int {
+		resp, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, "http://marketplace.com/products", ""))
+		assert.Nil(t, err)
+		return resp.StatusCode
+	}
+
+	path := filepath.Join(resolver.dir, "products.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte("host: marketplace.com\npath: /products\nmethod: GET\nresponses:\n  - status_code: 201\n"), 0o644))
+	assert.Nil(t, resolver.Reload(context.Background()))
+	assert.Equal(t, http.StatusCreated, statusCode())
+
+	assert.Nil(t, os.WriteFile(path, []byte("host: [marketplace.com"), 0o644))
+	assert.NotNil(t, resolver.Reload(context.Background()))
+	assert.Equal(t, http.StatusCreated, statusCode())
+}
+
+func TestFileBasedResolver_ConcurrentReload(t *testing.T) {
+	resolver := newTestResolverWithFiles(t, map[string]string{
+		"products.yaml": "host: marketplace.com\npath: /products\nmethod: GET\nresponses:\n  - status_code: 200\n",
+		"orders.yaml":   "host: marketplace.com\npath: /orders/*\nmethod: GET\nresponses:\n  - status_code: 200\n",
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				assert.Nil(t, resolver.Reload(context.Background()))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				resp, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, "http://marketplace.com/orders/1", ""))
+				assert.Nil(t, err)
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+}
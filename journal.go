@@ -0,0 +1,151 @@
+package mockhttp
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/William9923/go-mockhttp/pathregex"
+)
+
+// defaultJournalLimit is the max number of the intercepted requests kept in the journal, unless set by Client.JournalLimit.
+const defaultJournalLimit = 1000
+
+// journalSensitiveHeaders are the headers never kept by the journal, their values are redacted.
+var journalSensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// JournalEntry is a single request intercepted by the client, and how it was resolved.
+type JournalEntry struct {
+	Time   time.Time
+	Method string
+	URL    *url.URL
+	// Header of the request, with the credentials (Authorization, Proxy-Authorization and Cookie) redacted.
+	Header http.Header
+	// Info describes whether the request was mocked (and by which definition and response) or bypassed.
+	Info ResolveInfo
+	// StatusCode of the response returned to the caller (mocked or upstream), 0 when the request failed.
+	StatusCode int
+	// ResponseHeader of the response returned to the caller (Set-Cookie redacted), nil when the request failed.
+	ResponseHeader http.Header
+	// Duration is the time taken to resolve and respond the request.
+	Duration time.Duration
+	// Err is the error returned to the caller.
+	Err error
+}
+
+// Journal is the list of intercepted requests, in order.
+type Journal []JournalEntry
+
+// Journal returns all the requests intercepted by the client so far (mocked, passthrough and bypassed requests),
+// for post-hoc debugging and assertions.
+func (c *Client) Journal() Journal {
+	return c.journal.list()
+}
+
+// ResetJournal forget all the intercepted requests, ex: between test cases sharing the same client.
+func (c *Client) ResetJournal() {
+	c.journal.reset()
+}
+
+// Filter returns the entries that satisfy fn.
+func (j Journal) Filter(fn func(entry JournalEntry) bool) Journal {
+	return filter[JournalEntry](j, fn)
+}
+
+// Mocked returns the entries served from mock response.
+func (j Journal) Mocked() Journal {
+	return j.Filter(func(entry JournalEntry) bool { return entry.Info.Mocked })
+}
+
+// Unmocked returns the entries that are not served from mock response (sent upstream, or failed).
+func (j Journal) Unmocked() Journal {
+	return j.Filter(func(entry JournalEntry) bool { return !entry.Info.Mocked })
+}
+
+// Matching returns the entries with the http method, host and path.
+// Path support the same pattern as the mock definition path (ex: /products/:id, /products/*).
+func (j Journal) Matching(method, host, path string) Journal {
+	pattern := pathregex.Compile(path)
+	return j.Filter(func(entry JournalEntry) bool {
+		return strings.EqualFold(entry.Method, method) &&
+			(strings.EqualFold(entry.URL.Host, host) || strings.EqualFold(entry.URL.Hostname(), host)) &&
+			pattern.Match(entry.URL.Path)
+	})
+}
+
+// journal keep the most recent requests intercepted by the client, in order.
+type journal struct {
+	mu      sync.RWMutex
+	entries []*JournalEntry
+}
+
+// record add the resolved request into the journal, the returned entry is completed with the response via complete.
+func (j *journal) record(req *Request, info ResolveInfo, limit int) *JournalEntry {
+	u := *req.URL
+	entry := &JournalEntry{
+		Time:   time.Now(),
+		Method: req.Method,
+		URL:    &u,
+		Header: redactJournalHeader(req.Header),
+		Info:   info,
+	}
+	if limit == 0 {
+		limit = defaultJournalLimit
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = append(j.entries, entry)
+	if limit > 0 && len(j.entries) > limit {
+		// drop the oldest entries in place, releasing the dropped entries
+		dropped := len(j.entries) - limit
+		copy(j.entries, j.entries[dropped:])
+		for idx := limit; idx < len(j.entries); idx++ {
+			j.entries[idx] = nil
+		}
+		j.entries = j.entries[:limit]
+	}
+	return entry
+}
+
+// redactJournalHeader returns a copy of the header, with the values of the sensitive headers redacted.
+func redactJournalHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range journalSensitiveHeaders {
+		if _, exist := redacted[name]; exist {
+			redacted[name] = []string{redactedValue}
+		}
+	}
+	return redacted
+}
+
+func (j *journal) complete(entry *JournalEntry, resp *http.Response, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if resp != nil {
+		entry.StatusCode = resp.StatusCode
+		entry.ResponseHeader = redactJournalHeader(resp.Header)
+	}
+	entry.Err = err
+	entry.Duration = time.Since(entry.Time)
+}
+
+// list returns a copy of all the entries.
+func (j *journal) list() Journal {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	entries := make(Journal, 0, len(j.entries))
+	for _, entry := range j.entries {
+		entries = append(entries, *entry)
+	}
+	return entries
+}
+
+func (j *journal) reset() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = nil
+}
@@ -0,0 +1,112 @@
+package mockhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Journal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := newTestClient(newTestResolver(t, `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+`))
+
+	_, err := client.Get("http://marketplace.com/products")
+	assert.Nil(t, err)
+	_, err = client.Get(server.URL + "/orders")
+	assert.Nil(t, err)
+
+	journal := client.Journal()
+	assert.Len(t, journal, 2)
+
+	mocked := journal.Mocked()
+	assert.Len(t, mocked, 1)
+	assert.Equal(t, "GET marketplace.com/products", mocked[0].Info.Definition)
+	assert.Equal(t, http.StatusOK, mocked[0].StatusCode)
+
+	unmocked := journal.Unmocked()
+	assert.Len(t, unmocked, 1)
+	assert.Equal(t, "/orders", unmocked[0].URL.Path)
+	assert.Equal(t, http.StatusAccepted, unmocked[0].StatusCode)
+
+	assert.Len(t, journal.Matching(http.MethodGet, "marketplace.com", "/products"), 1)
+
+	t.Run("limit", func(t *testing.T) {
+		client.ResetJournal()
+		client.JournalLimit = 1
+		defer func() { client.JournalLimit = 0 }()
+
+		_, _ = client.Get(server.URL + "/orders")
+		_, _ = client.Get("http://marketplace.com/products")
+
+		journal := client.Journal()
+		assert.Len(t, journal, 1)
+		assert.Equal(t, "/products", journal[0].URL.Path)
+	})
+
+	t.Run("default limit", func(t *testing.T) {
+		client.ResetJournal()
+		for i := 0; i < defaultJournalLimit+1; i++ {
+			_, _ = client.Get("http://marketplace.com/products")
+		}
+		assert.Len(t, client.Journal(), defaultJournalLimit)
+
+		client.JournalLimit = -1
+		defer func() { client.JournalLimit = 0 }()
+		_, _ = client.Get("http://marketplace.com/products")
+		assert.Len(t, client.Journal(), defaultJournalLimit+1)
+	})
+
+	t.Run("redact credentials", func(t *testing.T) {
+		client.ResetJournal()
+		req, err := NewRequest(http.MethodGet, "http://marketplace.com/products", nil)
+		assert.Nil(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("X-Request-Id", "req-1")
+		_, err = client.Do(req)
+		assert.Nil(t, err)
+
+		entry := client.Journal()[0]
+		assert.Equal(t, redactedValue, entry.Header.Get("Authorization"))
+		assert.Equal(t, "req-1", entry.Header.Get("X-Request-Id"))
+		// the request itself is not modified
+		assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+	})
+}
+
+func TestClient_Journal_SelectedResponse(t *testing.T) {
+	client := newTestClient(newTestResolver(t, `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 404
+    rules:
+      - queryParams["id"] == "0"
+  - status_code: 200
+`))
+	client.MockOnly = true
+
+	_, err := client.Get("http://marketplace.com/products?id=0")
+	assert.Nil(t, err)
+	_, err = client.Get("http://marketplace.com/products?id=1")
+	assert.Nil(t, err)
+	_, err = client.Get("http://marketplace.com/orders")
+	assert.NotNil(t, err)
+
+	journal := client.Journal()
+	assert.Equal(t, 0, journal[0].Info.Response)
+	assert.Equal(t, 1, journal[1].Info.Response)
+	assert.Equal(t, -1, journal[2].Info.Response)
+}
@@ -0,0 +1,46 @@
+package mockhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// StubReply is a response added to the stub, see StubBuilder.ReplyWith.
+type StubReply func(b *StubBuilder)
+
+// ReplyJSON reply the status code with the payload encoded as JSON body, ex:
+//
+//	mockhttp.NewStub().Host("api.example.com").Get("/orders/1").ReplyWith(mockhttp.ReplyJSON(200, Order{ID: 1}))
+func ReplyJSON[T any](statusCode int, payload T) StubReply {
+	return func(b *StubBuilder) {
+		b.Reply(statusCode).JSON(payload)
+	}
+}
+
+// ReplyWith add the response to the stub, with the rules declared since the previous Reply (just like Reply).
+func (b *StubBuilder) ReplyWith(reply StubReply) *StubBuilder {
+	reply(b)
+	return b
+}
+
+// DecodeJSON read and close the response body, and decode it as JSON into T, ex:
+//
+//	order, err := mockhttp.DecodeJSON[Order](resp)
+func DecodeJSON[T any](resp *http.Response) (T, error) {
+	var payload T
+	if resp == nil || resp.Body == nil {
+		return payload, fmt.Errorf("decode json: response has no body")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return payload, err
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return payload, fmt.Errorf("decode json: %w", err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,46 @@
+package mockhttp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONHelpers(t *testing.T) {
+	type order struct {
+		ID     int      `json:"id"`
+		Status string   `json:"status"`
+		Items  []string `json:"items"`
+	}
+
+	resolver := NewMemoryResolverAdapter()
+	err := NewStub().
+		Host("api.example.com").
+		Get("/orders/:id").
+		When(`routeParams.id == "404"`).ReplyWith(ReplyJSON(http.StatusNotFound, map[string]string{"error": "not found"})).
+		ReplyWith(ReplyJSON(http.StatusOK, order{ID: 1, Status: "paid", Items: []string{"shoes"}})).
+		Register(context.Background(), resolver)
+	assert.Nil(t, err)
+	client := newTestClient(resolver)
+
+	resp, err := client.Get("http://api.example.com/orders/1")
+	assert.Nil(t, err)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	got, err := DecodeJSON[order](resp)
+	assert.Nil(t, err)
+	assert.Equal(t, order{ID: 1, Status: "paid", Items: []string{"shoes"}}, got)
+
+	resp, err = client.Get("http://api.example.com/orders/404")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	notFound, err := DecodeJSON[map[string]string](resp)
+	assert.Nil(t, err)
+	assert.Equal(t, "not found", notFound["error"])
+
+	resp, err = client.Get("http://api.example.com/orders/404")
+	assert.Nil(t, err)
+	_, err = DecodeJSON[[]order](resp)
+	assert.NotNil(t, err)
+}
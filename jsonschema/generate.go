@@ -0,0 +1,279 @@
+package jsonschema
+
+import (
+	"regexp/syntax"
+	"sort"
+	"strings"
+)
+
+// Generate synthesize a value (ready to be marshalled as JSON) that satisfy the schema,
+// using sensible fake values.
+//
+// The generated value is deterministic, the priorities to choose the value as below:
+//  1. const, example, first of examples, default, or first of enum
+//  2. all schemas of allOf (merged with the schema own properties), first schema of oneOf or anyOf
+//  3. fake value based on the schema type (and format, length and pattern for string)
+//
+// The generated value satisfy the schema (see Validate), except for conflicting keywords
+// (ex: pattern that can't match within maxLength).
+func (s *Schema) Generate() (interface{}, error) {
+	return s.generate(0)
+}
+
+func (s *Schema) generate(depth int) (interface{}, error) {
+	if depth > maxRefDepth {
+		return nil, nil
+	}
+
+	schema, err := s.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case schema.Const != nil:
+		return schema.Const, nil
+	case schema.Example != nil:
+		return schema.Example, nil
+	case len(schema.Examples) > 0:
+		return schema.Examples[0], nil
+	case schema.Default != nil:
+		return schema.Default, nil
+	case len(schema.Enum) > 0:
+		return schema.Enum[0], nil
+	case len(schema.AllOf) > 0:
+		return schema.generateAllOf(depth)
+	case len(schema.OneOf) > 0:
+		return schema.OneOf[0].generate(depth + 1)
+	case len(schema.AnyOf) > 0:
+		return schema.AnyOf[0].generate(depth + 1)
+	}
+
+	types := schema.types()
+	if len(types) == 0 {
+		if len(schema.Properties) > 0 {
+			types = []string{"object"}
+		} else if schema.Items != nil {
+			types = []string{"array"}
+		}
+	}
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	switch types[0] {
+	case "object":
+		return schema.generateObject(depth)
+	case "array":
+		return schema.generateArray(depth)
+	case "string":
+		return schema.generateString(), nil
+	case "integer":
+		return int64(schema.generateNumber()), nil
+	case "number":
+		return schema.generateNumber(), nil
+	case "boolean":
+		return true, nil
+	}
+	return nil, nil
+}
+
+func (s *Schema) generateAllOf(depth int) (interface{}, error) {
+	merged := make(map[string]interface{})
+	for _, sub := range s.AllOf {
+		value, err := sub.generate(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return value, nil
+		}
+		for key, field := range object {
+			merged[key] = field
+		}
+	}
+
+	// the schema own properties are generated as well, and take precedence over the allOf schemas
+	if len(s.Properties) > 0 {
+		value, err := s.generateObject(depth)
+		if err != nil {
+			return nil, err
+		}
+		for key, field := range value.(map[string]interface{}) {
+			merged[key] = field
+		}
+	}
+	return merged, nil
+}
+
+func (s *Schema) generateObject(depth int) (interface{}, error) {
+	object := make(map[string]interface{})
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value, err := s.Properties[name].generate(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		object[name] = value
+	}
+	return object, nil
+}
+
+func (s *Schema) generateArray(depth int) (interface{}, error) {
+	length := 1
+	if s.MinItems != nil && *s.MinItems > length {
+		length = *s.MinItems
+	}
+	if s.MaxItems != nil && *s.MaxItems < length {
+		length = *s.MaxItems
+	}
+
+	items := make([]interface{}, 0, length)
+	if s.Items == nil {
+		return items, nil
+	}
+	for i := 0; i < length; i++ {
+		value, err := s.Items.generate(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+	}
+	return items, nil
+}
+
+func (s *Schema) generateString() string {
+	value := "string"
+	switch s.Format {
+	case "date-time":
+		value = "2006-01-02T15:04:05Z"
+	case "date":
+		value = "2006-01-02"
+	case "time":
+		value = "15:04:05Z"
+	case "email":
+		value = "user@example.com"
+	case "hostname":
+		value = "example.com"
+	case "ipv4":
+		value = "127.0.0.1"
+	case "ipv6":
+		value = "::1"
+	case "uri", "url":
+		value = "https://example.com"
+	case "uuid":
+		value = "00000000-0000-4000-8000-000000000000"
+	}
+
+	if s.pattern != nil && !s.pattern.MatchString(value) {
+		if generated, ok := generatePattern(s.Pattern); ok {
+			value = generated
+		}
+	}
+
+	// keep the pattern satisfied when fitting the length (ex: the pattern is anchored at the end)
+	fitted := value
+	if s.MinLength != nil && len(fitted) < *s.MinLength {
+		fitted += strings.Repeat("x", *s.MinLength-len(fitted))
+	}
+	if s.MaxLength != nil && len(fitted) > *s.MaxLength {
+		fitted = fitted[:*s.MaxLength]
+	}
+	if s.pattern != nil && !s.pattern.MatchString(fitted) {
+		return value
+	}
+	return fitted
+}
+
+// generatePattern synthesize the shortest string matching the regular expression, ex: ^[A-Z]{3}-\d+$ => AAA-0.
+// Return false when the pattern can't be synthesized.
+func generatePattern(pattern string) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+
+	var sb strings.Builder
+	if !writePattern(&sb, re.Simplify()) {
+		return "", false
+	}
+	return sb.String(), true
+}
+
+func writePattern(sb *strings.Builder, re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return false
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			sb.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		r, ok := pickRune(re.Rune)
+		if !ok {
+			return false
+		}
+		sb.WriteRune(r)
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteRune('x')
+	case syntax.OpCapture, syntax.OpPlus:
+		return writePattern(sb, re.Sub[0])
+	case syntax.OpRepeat:
+		for i := 0; i < re.Min; i++ {
+			if !writePattern(sb, re.Sub[0]) {
+				return false
+			}
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !writePattern(sb, sub) {
+				return false
+			}
+		}
+	case syntax.OpAlternate:
+		return writePattern(sb, re.Sub[0])
+	}
+	// empty match, anchors, word boundaries, star and quest are satisfied by writing nothing
+	return true
+}
+
+// pickRune choose a readable rune (letter or digit when possible) from the char class ranges (pairs of lo, hi).
+func pickRune(ranges []rune) (rune, bool) {
+	if len(ranges) == 0 {
+		return 0, false
+	}
+	for _, candidate := range []rune{'A', 'a', '0'} {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if ranges[i] <= candidate && candidate <= ranges[i+1] {
+				return candidate, true
+			}
+		}
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if ranges[i+1] >= ' ' {
+			if ranges[i] < ' ' {
+				return ' ', true
+			}
+			return ranges[i], true
+		}
+	}
+	return ranges[0], true
+}
+
+func (s *Schema) generateNumber() float64 {
+	var value float64
+	if s.Minimum != nil {
+		value = *s.Minimum
+	}
+	if s.Maximum != nil && value > *s.Maximum {
+		value = *s.Maximum
+	}
+	return value
+}
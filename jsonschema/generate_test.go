@@ -0,0 +1,125 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const orderSchemaStr = `
+  {
+		"type": "object",
+		"required": ["id", "status"],
+		"properties": {
+			"id": { "type": "string", "format": "uuid" },
+			"status": { "type": "string", "enum": ["PAID", "PENDING"] },
+			"amount": { "type": "number", "minimum": 100 },
+			"quantity": { "type": "integer", "example": 3 },
+			"paid": { "type": "boolean" },
+			"buyer": { "$ref": "#/definitions/buyer" },
+			"tags": { "type": "array", "items": { "type": "string", "minLength": 8 }, "minItems": 2 }
+		},
+		"definitions": {
+			"buyer": {
+				"type": "object",
+				"properties": {
+					"email": { "type": "string", "format": "email" }
+				}
+			}
+		}
+	}`
+
+func Test_Generate(t *testing.T) {
+	t.Run("generate fake value from schema", func(t *testing.T) {
+		schema, err := Parse([]byte(orderSchemaStr))
+		assert.Nil(t, err, "should not error")
+
+		res, err := schema.Generate()
+		expected := map[string]interface{}{
+			"id":       "00000000-0000-4000-8000-000000000000",
+			"status":   "PAID",
+			"amount":   float64(100),
+			"quantity": float64(3),
+			"paid":     true,
+			"buyer": map[string]interface{}{
+				"email": "user@example.com",
+			},
+			"tags": []interface{}{"stringxx", "stringxx"},
+		}
+		assert.Nil(t, err, "should not error")
+		assert.Equal(t, expected, res)
+	})
+
+	t.Run("generate with unresolvable ref", func(t *testing.T) {
+		schema, err := Parse([]byte(`{"$ref": "#/definitions/missing"}`))
+		assert.Nil(t, err, "should not error")
+
+		_, err = schema.Generate()
+		assert.NotNil(t, err, "should err")
+	})
+
+	t.Run("parse invalid schema", func(t *testing.T) {
+		_, err := Parse([]byte(`{"type": `))
+		assert.NotNil(t, err, "should err")
+	})
+}
+
+func Test_GenerateValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"order schema", orderSchemaStr},
+		{"pattern", `{"type": "string", "pattern": "^[A-Z]{3}-\\d{4}$"}`},
+		{"pattern with alternation", `{"type": "string", "pattern": "^(PAID|PENDING)_[a-z0-9]+$"}`},
+		{"pattern with negated class", `{"type": "string", "pattern": "^[^a-z]+$"}`},
+		{"pattern with length", `{"type": "string", "pattern": "^ord-", "minLength": 8}`},
+		{"allOf with own properties", `{
+			"type": "object",
+			"required": ["id", "code"],
+			"properties": {
+				"code": { "type": "string", "pattern": "^INV-[0-9]+$" }
+			},
+			"allOf": [
+				{ "required": ["id"], "properties": { "id": { "type": "integer", "minimum": 1 } } },
+				{ "properties": { "paid": { "type": "boolean" } } }
+			]
+		}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := Parse([]byte(tt.schema))
+			assert.Nil(t, err)
+
+			res, err := schema.Generate()
+			assert.Nil(t, err)
+
+			// validate the value as served (decoded from JSON)
+			data, err := json.Marshal(res)
+			assert.Nil(t, err)
+			var value interface{}
+			assert.Nil(t, json.Unmarshal(data, &value))
+			assert.Nil(t, schema.Validate(value), "generated %s", data)
+		})
+	}
+}
+
+func Test_generatePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`^[A-Z]{3}-\d+$`, "AAA-0"},
+		{`^(foo|bar)?baz$`, "baz"},
+		{`^[a-f0-9]{2,}$`, "aa"},
+		{`^\w+@example\.com$`, "A@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, ok := generatePattern(tt.pattern)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
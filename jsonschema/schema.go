@@ -0,0 +1,146 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Schema represents the subset of JSON Schema (draft-07 / 2020-12) keywords
+// that is supported by mockhttp to generate and validate JSON payload.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Const                interface{}        `json:"const,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Example              interface{}        `json:"example,omitempty"`
+	Examples             []interface{}      `json:"examples,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+
+	// root is the top level schema, used to resolve local $ref
+	root *Schema
+	// pattern is the compiled pattern keyword
+	pattern *regexp.Regexp
+}
+
+// maxRefDepth limit how deep $ref can be followed, to avoid infinite recursion on recursive schema.
+const maxRefDepth = 16
+
+// Parse parse JSON Schema document into Schema.
+func Parse(data []byte) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	if err := schema.prepare(&schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// prepare set the root schema on every sub schema, so local $ref can be resolved from anywhere,
+// and compile the pattern keyword of every sub schema.
+func (s *Schema) prepare(root *Schema) error {
+	if s == nil {
+		return nil
+	}
+	s.root = root
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = pattern
+	}
+
+	var subs []*Schema
+	for _, sub := range s.Properties {
+		subs = append(subs, sub)
+	}
+	for _, sub := range s.Definitions {
+		subs = append(subs, sub)
+	}
+	for _, sub := range s.Defs {
+		subs = append(subs, sub)
+	}
+	subs = append(subs, s.AllOf...)
+	subs = append(subs, s.AnyOf...)
+	subs = append(subs, s.OneOf...)
+	subs = append(subs, s.Items)
+
+	for _, sub := range subs {
+		if err := sub.prepare(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve follow local $ref (ex: #/definitions/user or #/$defs/user) until reaching concrete schema.
+func (s *Schema) resolve() (*Schema, error) {
+	current := s
+	for depth := 0; current.Ref != ""; depth++ {
+		if depth >= maxRefDepth {
+			return nil, fmt.Errorf("$ref %q exceed max depth", s.Ref)
+		}
+
+		var (
+			store map[string]*Schema
+			name  string
+		)
+		switch {
+		case strings.HasPrefix(current.Ref, "#/definitions/"):
+			store, name = current.root.Definitions, strings.TrimPrefix(current.Ref, "#/definitions/")
+		case strings.HasPrefix(current.Ref, "#/$defs/"):
+			store, name = current.root.Defs, strings.TrimPrefix(current.Ref, "#/$defs/")
+		case current.Ref == "#":
+			current = current.root
+			continue
+		default:
+			return nil, fmt.Errorf("unsupported $ref %q", current.Ref)
+		}
+
+		next, exist := store[name]
+		if !exist {
+			return nil, fmt.Errorf("unable to resolve $ref %q", current.Ref)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// types return all the allowed types of the schema.
+// type keyword can be either a single string or an array of string.
+func (s *Schema) types() []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		var types []string
+		for _, v := range t {
+			if name, ok := v.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"unicode/utf8"
+)
+
+// ValidationError describes why a value failed to be validated against the schema.
+type ValidationError struct {
+	// Path is the JSON pointer of the invalid value (ex: /buyer/email)
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s", path, e.Message)
+}
+
+// Validate check whether the value (decoded JSON, ex: via encoding/json into interface{}) satisfy the schema.
+//
+// It return the first *ValidationError found, or error when the schema itself can't be resolved.
+func (s *Schema) Validate(value interface{}) error {
+	return s.validate("", value, 0)
+}
+
+func (s *Schema) validate(path string, value interface{}, depth int) error {
+	if depth > maxRefDepth*4 {
+		return &ValidationError{Path: path, Message: "value is nested too deep"}
+	}
+
+	schema, err := s.resolve()
+	if err != nil {
+		return err
+	}
+
+	invalid := func(format string, args ...interface{}) error {
+		return &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)}
+	}
+
+	if types := schema.types(); len(types) > 0 && !matchAnyType(types, value) {
+		return invalid("expected %v, got %s", types, typeOf(value))
+	}
+	if schema.Const != nil && !equal(schema.Const, value) {
+		return invalid("expected constant %v", schema.Const)
+	}
+	if len(schema.Enum) > 0 && !containValue(schema.Enum, value) {
+		return invalid("expected one of %v", schema.Enum)
+	}
+
+	for _, sub := range schema.AllOf {
+		if err := sub.validate(path, value, depth+1); err != nil {
+			return err
+		}
+	}
+	if len(schema.AnyOf) > 0 && countValid(schema.AnyOf, path, value, depth) == 0 {
+		return invalid("value does not match any of the schemas")
+	}
+	if len(schema.OneOf) > 0 && countValid(schema.OneOf, path, value, depth) != 1 {
+		return invalid("value must match exactly one of the schemas")
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return schema.validateObject(path, v, depth)
+	case []interface{}:
+		return schema.validateArray(path, v, depth)
+	case string:
+		length := utf8.RuneCountInString(v)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			return invalid("length must be >= %d", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			return invalid("length must be <= %d", *schema.MaxLength)
+		}
+		if schema.pattern != nil && !schema.pattern.MatchString(v) {
+			return invalid("does not match pattern %q", schema.Pattern)
+		}
+	case float64:
+		if schema.Minimum != nil && v < *schema.Minimum {
+			return invalid("must be >= %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && v > *schema.Maximum {
+			return invalid("must be <= %v", *schema.Maximum)
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateObject(path string, object map[string]interface{}, depth int) error {
+	for _, name := range s.Required {
+		if _, exist := object[name]; !exist {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("missing required property %q", name)}
+		}
+	}
+
+	for name, field := range object {
+		sub, exist := s.Properties[name]
+		if !exist {
+			if additional, ok := s.AdditionalProperties.(bool); ok && !additional {
+				return &ValidationError{Path: path, Message: fmt.Sprintf("additional property %q is not allowed", name)}
+			}
+			continue
+		}
+		if err := sub.validate(path+"/"+name, field, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateArray(path string, items []interface{}, depth int) error {
+	if s.MinItems != nil && len(items) < *s.MinItems {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must have >= %d items", *s.MinItems)}
+	}
+	if s.MaxItems != nil && len(items) > *s.MaxItems {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("must have <= %d items", *s.MaxItems)}
+	}
+	if s.Items == nil {
+		return nil
+	}
+	for idx, item := range items {
+		if err := s.Items.validate(fmt.Sprintf("%s/%d", path, idx), item, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func countValid(schemas []*Schema, path string, value interface{}, depth int) int {
+	count := 0
+	for _, sub := range schemas {
+		if sub.validate(path, value, depth+1) == nil {
+			count++
+		}
+	}
+	return count
+}
+
+func matchAnyType(types []string, value interface{}) bool {
+	actual := typeOf(value)
+	for _, expected := range types {
+		if expected == actual {
+			return true
+		}
+		// integer is a number without fractional part
+		if expected == "integer" && actual == "number" && value.(float64) == math.Trunc(value.(float64)) {
+			return true
+		}
+	}
+	return false
+}
+
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func containValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if equal(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,49 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Validate(t *testing.T) {
+	schema, err := Parse([]byte(orderSchemaStr))
+	assert.Nil(t, err, "should not error")
+
+	tests := []struct {
+		name    string
+		payload string
+		path    string
+	}{
+		{"valid payload", `{"id": "a1", "status": "PAID", "amount": 150, "quantity": 2, "tags": ["promotion", "discount"]}`, ""},
+		{"missing required property", `{"id": "a1"}`, "/"},
+		{"invalid enum", `{"id": "a1", "status": "REFUNDED"}`, "/status"},
+		{"invalid type", `{"id": 1, "status": "PAID"}`, "/id"},
+		{"invalid integer", `{"id": "a1", "status": "PAID", "quantity": 1.5}`, "/quantity"},
+		{"below minimum", `{"id": "a1", "status": "PAID", "amount": 10}`, "/amount"},
+		{"invalid nested ref", `{"id": "a1", "status": "PAID", "buyer": {"email": 1}}`, "/buyer/email"},
+		{"invalid array item", `{"id": "a1", "status": "PAID", "tags": ["promo", "discount"]}`, "/tags/0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload interface{}
+			assert.Nil(t, json.Unmarshal([]byte(tt.payload), &payload))
+
+			err := schema.Validate(payload)
+			if tt.path == "" {
+				assert.Nil(t, err, "should not error")
+				return
+			}
+
+			var validationErr *ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+			assert.Contains(t, validationErr.Error(), tt.path+": ")
+		})
+	}
+}
+
+func Test_ParseInvalidPattern(t *testing.T) {
+	_, err := Parse([]byte(`{"type": "string", "pattern": "(["}`))
+	assert.NotNil(t, err, "should err")
+}
@@ -0,0 +1,76 @@
+package mockhttp
+
+import (
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+// lazyDefinition hold the whole definition of an indexed definition file, parsed once on the first matching request.
+type lazyDefinition struct {
+	file   string
+	once   sync.Once
+	parsed atomic.Bool
+
+	definition fileBasedMockDefinition
+	err        error
+}
+
+// fileBasedResolver indexDefinition
+// Read only the host, method, path (and required flag) of the mock definition spec file, so it can be matched against the requests.
+// The responses (including rules and schema files) are parsed lazily via parseLazy.
+func (r *fileBasedResolver) indexDefinition(name string) (fileBasedMockDefinition, error) {
+	var definition fileBasedMockDefinition
+
+	var index struct {
+		Host     string `yaml:"host"`
+		Path     string `yaml:"path"`
+		Method   string `yaml:"method"`
+		Required bool   `yaml:"required"`
+	}
+	if err := decodeYAMLFile(filepath.Join(r.dir, name), &index); err != nil {
+		return definition, err
+	}
+
+	definition.Host = index.Host
+	definition.Path = index.Path
+	definition.Method = index.Method
+	definition.Required = index.Required
+	definition.compilePath()
+	definition.lazy = &lazyDefinition{file: name}
+	definition.file = name
+	return definition, nil
+}
+
+// fileBasedResolver parseLazy
+// Returns the whole definition of the indexed definition, parsing the definition file on the first call.
+// Definition that is not indexed (lazy load mode disabled) is returned as is.
+func (r *fileBasedResolver) parseLazy(definition fileBasedMockDefinition) (fileBasedMockDefinition, error) {
+	lazy := definition.lazy
+	if lazy == nil {
+		return definition, nil
+	}
+
+	lazy.once.Do(func() {
+		lazy.definition, lazy.err = r.readDefinition(lazy.file)
+		if lazy.err != nil {
+			lazy.err = &DefinitionFileError{File: lazy.file, Err: lazy.err}
+		}
+		lazy.parsed.Store(true)
+	})
+	return lazy.definition, lazy.err
+}
+
+// fileBasedResolver parsedDefinition
+// Returns the whole definition without parsing the indexed definition, false when it has not been parsed yet
+// (never matched by any request), or failed to be parsed.
+func (r *fileBasedResolver) parsedDefinition(definition fileBasedMockDefinition) (fileBasedMockDefinition, bool) {
+	lazy := definition.lazy
+	if lazy == nil {
+		return definition, true
+	}
+	if !lazy.parsed.Load() || lazy.err != nil {
+		return definition, false
+	}
+	return lazy.definition, true
+}
@@ -43,3 +43,8 @@ type RequestLogHook func(Logger, *http.Request)
 // needs to be performed or not. If the response body is read or closed
 // from this method, this will affect the response returned from Do().
 type ResponseLogHook func(Logger, *http.Response)
+
+// ResolvedResponseLogHook is like ResponseLogHook, but invoked for both mocked and actual upstream responses,
+// with the ResolveInfo telling whether the response came from a mock (ResolveInfo.Mocked)
+// and which definition produced it (ResolveInfo.Definition).
+type ResolvedResponseLogHook func(Logger, *http.Response, ResolveInfo)
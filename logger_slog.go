@@ -0,0 +1,9 @@
+//go:build go1.21
+
+package mockhttp
+
+import "log/slog"
+
+// *slog.Logger can be used directly as Client.Logger, logging the structured fields
+// (method, url, mocked, definition, ...) as slog attributes.
+var _ LeveledLogger = (*slog.Logger)(nil)
@@ -0,0 +1,24 @@
+//go:build go1.21
+
+package mockhttp
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_SlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	client := newTestClient(staticResolver{statusCode: http.StatusTeapot})
+	client.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	resp, err := client.Get("http://marketplace.com/products")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+
+	assert.Contains(t, buf.String(), `msg="resolved request" method=GET url=http://marketplace.com/products mocked=true`)
+}
@@ -0,0 +1,18 @@
+package mockhttp
+
+import (
+	"net/http"
+	"time"
+)
+
+// MetricsRecorder receives the mock activity of the client, ex: to export it as Prometheus metrics
+// (mock hits, misses, rule failures, resolve latency and upstream passthrough per host / path).
+//
+// MetricsRecorder must be safe for concurrent use.
+type MetricsRecorder interface {
+	// ObserveResolve is called after each request is resolved (or bypassed), before the request is sent upstream.
+	ObserveResolve(req *Request, info ResolveInfo)
+
+	// ObservePassthrough is called after each attempt of request sent to the actual upstream service.
+	ObservePassthrough(req *Request, resp *http.Response, err error, latency time.Duration)
+}
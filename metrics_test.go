@@ -0,0 +1,64 @@
+package mockhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeMetricsRecorder struct {
+	mu           sync.Mutex
+	resolves     []ResolveInfo
+	passthroughs []int
+}
+
+func (f *fakeMetricsRecorder) ObserveResolve(req *Request, info ResolveInfo) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resolves = append(f.resolves, info)
+}
+
+func (f *fakeMetricsRecorder) ObservePassthrough(req *Request, resp *http.Response, err error, latency time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.passthroughs = append(f.passthroughs, resp.StatusCode)
+}
+
+func TestClient_Metrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+  - status_code: 404
+    rules:
+      - queryParams.id > 0
+`)
+	recorder := &fakeMetricsRecorder{}
+	client := newTestClient(resolver)
+	client.Metrics = recorder
+
+	resp, err := client.Get("http://marketplace.com/products?id=abc")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = client.Get(server.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	assert.Len(t, recorder.resolves, 2)
+	assert.True(t, recorder.resolves[0].Mocked)
+	assert.Equal(t, 1, recorder.resolves[0].RuleErrors)
+	assert.False(t, recorder.resolves[1].Mocked)
+	assert.Equal(t, []int{http.StatusAccepted}, recorder.passthroughs)
+}
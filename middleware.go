@@ -0,0 +1,37 @@
+package mockhttp
+
+import "net/http"
+
+// DoFunc is the function that performs the request, and returns either the mocked or actual upstream response.
+type DoFunc func(req *Request) (*http.Response, error)
+
+// Middleware wraps the Do path of the client (for both mocked and actual upstream responses),
+// ex: to inject auth header, log or capture the request / response for assertion.
+//
+// ex:
+//
+//	client.Use(func(next mockhttp.DoFunc) mockhttp.DoFunc {
+//		return func(req *mockhttp.Request) (*http.Response, error) {
+//			req.Header.Set("Authorization", "Bearer token")
+//			return next(req)
+//		}
+//	})
+type Middleware func(next DoFunc) DoFunc
+
+// Use appends the middlewares into the client middleware chain. The first middleware is the outermost one,
+// which called first for the request and last for the response.
+//
+// Use is safe for concurrent use with Do, in-flight requests keep using the previous middleware chain.
+func (c *Client) Use(middlewares ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.middlewares = append(c.middlewares[:len(c.middlewares):len(c.middlewares)], middlewares...)
+}
+
+// chain build the DoFunc that calls all the middlewares in order, before calling do.
+func chain(middlewares []Middleware, do DoFunc) DoFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		do = middlewares[i](do)
+	}
+	return do
+}
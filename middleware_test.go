@@ -0,0 +1,40 @@
+package mockhttp
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Use(t *testing.T) {
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next DoFunc) DoFunc {
+			return func(req *Request) (*http.Response, error) {
+				calls = append(calls, "before "+name)
+				resp, err := next(req)
+				calls = append(calls, "after "+name)
+				return resp, err
+			}
+		}
+	}
+
+	var authorization string
+	client := newTestClient(resolverFunc(func(req *Request) (*http.Response, error) {
+		authorization = req.Header.Get("Authorization")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req.Request}, nil
+	}))
+	client.Use(trace("outer"), trace("inner"), func(next DoFunc) DoFunc {
+		return func(req *Request) (*http.Response, error) {
+			req.Header.Set("Authorization", "Bearer token")
+			return next(req)
+		}
+	})
+
+	resp, err := client.Get("http://marketplace.com/products")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Bearer token", authorization)
+	assert.Equal(t, []string{"before outer", "before inner", "after inner", "after outer"}, calls)
+}
@@ -0,0 +1,57 @@
+package mockhttp
+
+// MockHitHandler is called when the request is served from a mock response, with the matched definition.
+type MockHitHandler func(definition string, req *Request)
+
+// MockMissHandler is called when the request is not served from a mock response, with the reason:
+// ErrNoMockResponse when no definition matched, ErrMockBypassed when the request bypass the mock,
+// or the error returned by the resolver.
+type MockMissHandler func(req *Request, reason error)
+
+// OnMockHit register the handler to be called for every mocked request,
+// ex: for test frameworks to assert expectations.
+//
+// OnMockHit is safe for concurrent use with Do.
+func (c *Client) OnMockHit(fn MockHitHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mockHitHandlers = append(c.mockHitHandlers[:len(c.mockHitHandlers):len(c.mockHitHandlers)], fn)
+}
+
+// OnMockMiss register the handler to be called for every request that is not mocked,
+// ex: for CI to flag unexpected passthrough to real services.
+//
+// OnMockMiss is safe for concurrent use with Do.
+func (c *Client) OnMockMiss(fn MockMissHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mockMissHandlers = append(c.mockMissHandlers[:len(c.mockMissHandlers):len(c.mockMissHandlers)], fn)
+}
+
+// notifyMock call the mock hit / miss handlers, based on how the request is resolved.
+func (s clientSnapshot) notifyMock(req *Request, info ResolveInfo) {
+	if info.Mocked {
+		s.emit(EventMockHit, req, info, 0, nil)
+		for _, fn := range s.mockHitHandlers {
+			fn(info.Definition, req)
+		}
+		return
+	}
+
+	if len(s.mockMissHandlers) == 0 && len(s.eventListeners) == 0 {
+		return
+	}
+	reason := info.Err
+	switch {
+	case info.Bypassed:
+		reason = ErrMockBypassed
+	case reason == nil:
+		reason = ErrNoMockResponse
+	}
+	if !info.Bypassed {
+		s.emit(EventMockMiss, req, info, 0, reason)
+	}
+	for _, fn := range s.mockMissHandlers {
+		fn(req, reason)
+	}
+}
@@ -0,0 +1,49 @@
+package mockhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_OnMockHitAndMiss(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+`)
+	client := newTestClient(resolver)
+
+	var hits []string
+	var misses []error
+	client.OnMockHit(func(definition string, req *Request) {
+		hits = append(hits, definition)
+	})
+	client.OnMockMiss(func(req *Request, reason error) {
+		misses = append(misses, reason)
+	})
+
+	_, err := client.Get("http://marketplace.com/products")
+	assert.Nil(t, err)
+	_, err = client.Get(server.URL)
+	assert.Nil(t, err)
+	req, err := NewRequestWithContext(WithBypass(context.Background()), http.MethodGet, server.URL, nil)
+	assert.Nil(t, err)
+	_, err = client.Do(req)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"GET marketplace.com/products"}, hits)
+	assert.Len(t, misses, 2)
+	assert.ErrorIs(t, misses[0], ErrNoMockResponse)
+	assert.ErrorIs(t, misses[1], ErrMockBypassed)
+}
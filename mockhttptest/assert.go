@@ -0,0 +1,101 @@
+// Package mockhttptest provides testing helpers for mockhttp.Client,
+// asserting how the mocked upstreams were called with readable failure messages,
+// and building a mock-only client cleaned up with the test (NewClient).
+//
+// ex:
+//
+//	func TestCheckout(t *testing.T) {
+//		client := mockhttp.NewClient(resolver)
+//		...
+//		mockhttptest.AssertMockUsed(t, client, "POST marketplace.com/check-price")
+//		mockhttptest.AssertNoPassthrough(t, client)
+//	}
+package mockhttptest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	mockhttp "github.com/William9923/go-mockhttp"
+)
+
+// AssertMockUsed asserts that at least one request was served by the mock definition,
+// named by its http method, host and path (ex: GET marketplace.com/products/:id).
+func AssertMockUsed(t testing.TB, client *mockhttp.Client, definition string) bool {
+	t.Helper()
+
+	journal := client.Journal()
+	if len(usedBy(journal, definition)) > 0 {
+		return true
+	}
+	t.Errorf("mock definition %q was not used\n%s", definition, describe(journal.Mocked(), "mocked requests"))
+	return false
+}
+
+// AssertMockNotUsed asserts that no request was served by the mock definition.
+func AssertMockNotUsed(t testing.TB, client *mockhttp.Client, definition string) bool {
+	t.Helper()
+
+	used := usedBy(client.Journal(), definition)
+	if len(used) == 0 {
+		return true
+	}
+	t.Errorf("mock definition %q was used %d time(s)\n%s", definition, len(used), describe(used, "requests"))
+	return false
+}
+
+// AssertNoPassthrough asserts that every request was served from mock response,
+// none was sent to the actual upstream service (bypassed requests included).
+func AssertNoPassthrough(t testing.TB, client *mockhttp.Client) bool {
+	t.Helper()
+
+	unmocked := client.Journal().Unmocked()
+	if len(unmocked) == 0 {
+		return true
+	}
+	t.Errorf("%d request(s) were not mocked\n%s", len(unmocked), describe(unmocked, "unmocked requests"))
+	return false
+}
+
+// AssertCalled asserts that the requests with the http method, host and path were called exactly n times.
+// Path support the same pattern as the mock definition path (ex: /products/:id, /products/*).
+func AssertCalled(t testing.TB, client *mockhttp.Client, method, host, path string, n int) bool {
+	t.Helper()
+
+	if err := client.Verify().Called(method, host, path).Times(n); err != nil {
+		t.Errorf("%s\n%s", err, describe(client.Journal(), "intercepted requests"))
+		return false
+	}
+	return true
+}
+
+func usedBy(journal mockhttp.Journal, definition string) mockhttp.Journal {
+	return journal.Filter(func(entry mockhttp.JournalEntry) bool {
+		return entry.Info.Mocked && entry.Info.Definition == definition
+	})
+}
+
+// describe list the journal entries, one request per line.
+func describe(journal mockhttp.Journal, title string) string {
+	if len(journal) == 0 {
+		return fmt.Sprintf("no %s", title)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s:", title)
+	for _, entry := range journal {
+		fmt.Fprintf(&sb, "\n  %s %s", entry.Method, entry.URL)
+		switch {
+		case entry.Info.Mocked:
+			fmt.Fprintf(&sb, " (mocked by %s)", entry.Info.Definition)
+		case entry.Info.Bypassed:
+			sb.WriteString(" (bypassed)")
+		case entry.Err != nil:
+			fmt.Fprintf(&sb, " (error: %s)", entry.Err)
+		default:
+			fmt.Fprintf(&sb, " (passthrough: %d)", entry.StatusCode)
+		}
+	}
+	return sb.String()
+}
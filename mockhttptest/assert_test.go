@@ -0,0 +1,71 @@
+package mockhttptest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mockhttp "github.com/William9923/go-mockhttp"
+)
+
+// recordingT capture the failure messages, instead of failing the test.
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	definition := `
+host: marketplace.com
+path: /products/:id
+method: GET
+responses:
+  - status_code: 200
+`
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "products.yaml"), []byte(definition), 0o644))
+	resolver, err := mockhttp.NewFileResolverAdapter(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, resolver.LoadDefinition(context.Background()))
+
+	client := mockhttp.NewClient(resolver)
+	_, err = client.Get("http://marketplace.com/products/1")
+	assert.Nil(t, err)
+
+	t.Run("passed", func(t *testing.T) {
+		rt := &recordingT{TB: t}
+		assert.True(t, AssertMockUsed(rt, client, "GET marketplace.com/products/:id"))
+		assert.True(t, AssertMockNotUsed(rt, client, "GET marketplace.com/orders"))
+		assert.True(t, AssertNoPassthrough(rt, client))
+		assert.True(t, AssertCalled(rt, client, http.MethodGet, "marketplace.com", "/products/:id", 1))
+		assert.Empty(t, rt.errors)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		_, err = client.Get(server.URL + "/orders")
+		assert.Nil(t, err)
+
+		rt := &recordingT{TB: t}
+		assert.False(t, AssertMockUsed(rt, client, "GET marketplace.com/orders"))
+		assert.False(t, AssertNoPassthrough(rt, client))
+		assert.Equal(t, []string{
+			"mock definition \"GET marketplace.com/orders\" was not used\nmocked requests:\n  GET http://marketplace.com/products/1 (mocked by GET marketplace.com/products/:id)",
+			fmt.Sprintf("1 request(s) were not mocked\nunmocked requests:\n  GET %s/orders (passthrough: 200)", server.URL),
+		}, rt.errors)
+	})
+}
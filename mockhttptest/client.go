@@ -0,0 +1,96 @@
+package mockhttptest
+
+import (
+	"context"
+	"testing"
+
+	mockhttp "github.com/William9923/go-mockhttp"
+)
+
+type clientConfig struct {
+	resolverOpts     []mockhttp.FileResolverOption
+	clientOpts       []mockhttp.ClientOption
+	stubs            []*mockhttp.StubBuilder
+	allowPassthrough bool
+}
+
+// Option customize the client built by NewClient.
+type Option func(*clientConfig)
+
+// WithResolverOptions set the options of the in-memory resolver (ex: mockhttp.WithClock).
+func WithResolverOptions(opts ...mockhttp.FileResolverOption) Option {
+	return func(c *clientConfig) {
+		c.resolverOpts = append(c.resolverOpts, opts...)
+	}
+}
+
+// WithClientOptions set the options of the client (ex: mockhttp.WithTimeout).
+func WithClientOptions(opts ...mockhttp.ClientOption) Option {
+	return func(c *clientConfig) {
+		c.clientOpts = append(c.clientOpts, opts...)
+	}
+}
+
+// WithStubs register the stubs into the resolver, before the client is returned.
+func WithStubs(stubs ...*mockhttp.StubBuilder) Option {
+	return func(c *clientConfig) {
+		c.stubs = append(c.stubs, stubs...)
+	}
+}
+
+// AllowPassthrough let the requests with no mock response reach the actual upstream service,
+// instead of failing the test.
+func AllowPassthrough() Option {
+	return func(c *clientConfig) {
+		c.allowPassthrough = true
+	}
+}
+
+// NewClient creates a mockhttp.Client with in-memory resolver (see mockhttp.NewMemoryResolverAdapter) for the test.
+// Register more stubs at any time with mockhttp.NewStub().Register(ctx, client.Resolver).
+//
+// The client is in mock-only mode, so no request leaves the test process. When the test ends,
+// the test fails on any unmocked request (see AssertNoPassthrough) or unused required definition
+// (see mockhttp.Client.AssertExpectations), then the journal, stubs and state are reset.
+//
+// ex:
+//
+//	func TestCheckout(t *testing.T) {
+//		client := mockhttptest.NewClient(t, mockhttptest.WithStubs(
+//			mockhttp.NewStub().Host("payment.com").Post("/charges").Reply(201),
+//		))
+//		...
+//	}
+func NewClient(t testing.TB, opts ...Option) *mockhttp.Client {
+	t.Helper()
+
+	var cfg clientConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	resolver := mockhttp.NewMemoryResolverAdapter(cfg.resolverOpts...)
+	if err := resolver.LoadDefinition(context.Background()); err != nil {
+		t.Fatalf("mockhttptest: unable to load definitions: %s", err)
+	}
+	for _, stub := range cfg.stubs {
+		if err := stub.Register(context.Background(), resolver); err != nil {
+			t.Fatalf("mockhttptest: unable to register stub: %s", err)
+		}
+	}
+
+	client := mockhttp.NewClient(resolver, cfg.clientOpts...)
+	client.MockOnly = !cfg.allowPassthrough
+
+	t.Cleanup(func() {
+		client.AssertExpectations(t)
+		if !cfg.allowPassthrough {
+			AssertNoPassthrough(t, client)
+		}
+		client.ResetJournal()
+		if resetter, ok := resolver.(mockhttp.Resetter); ok {
+			resetter.Reset()
+		}
+	})
+	return client
+}
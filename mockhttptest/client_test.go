@@ -0,0 +1,66 @@
+package mockhttptest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mockhttp "github.com/William9923/go-mockhttp"
+)
+
+func TestNewClient(t *testing.T) {
+	stub := func() *mockhttp.StubBuilder {
+		return mockhttp.NewStub().Host("payment.com").Post("/charges").Required().Reply(http.StatusCreated)
+	}
+
+	t.Run("passed", func(t *testing.T) {
+		rt := &recordingT{TB: t}
+		t.Run("test", func(t *testing.T) {
+			rt.TB = t
+			client := NewClient(rt, WithStubs(stub()))
+
+			resp, err := client.Post("http://payment.com/charges", "application/json", []byte(`{}`))
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		})
+		assert.Empty(t, rt.errors)
+	})
+
+	t.Run("unexpected passthrough and unused definition", func(t *testing.T) {
+		rt := &recordingT{TB: t}
+		var client *mockhttp.Client
+		t.Run("test", func(t *testing.T) {
+			rt.TB = t
+			client = NewClient(rt, WithStubs(stub()))
+
+			_, err := client.Get("http://payment.com/refunds")
+			assert.ErrorIs(t, err, mockhttp.ErrUnmatchedRequest)
+		})
+		assert.Equal(t, []string{
+			"mockhttp: required mock definition POST payment.com/charges was never used",
+			"1 request(s) were not mocked\nunmocked requests:\n  GET http://payment.com/refunds (error: no mock response found for request in mock-only mode: GET http://payment.com/refunds)",
+		}, rt.errors)
+
+		// the journal and stubs are reset after the test
+		assert.Empty(t, client.Journal())
+		assert.Empty(t, client.Resolver.(mockhttp.StatsReporter).Stats())
+	})
+
+	t.Run("allow passthrough", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		rt := &recordingT{TB: t}
+		t.Run("test", func(t *testing.T) {
+			rt.TB = t
+			client := NewClient(rt, AllowPassthrough())
+
+			resp, err := client.Get(server.URL)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+		assert.Empty(t, rt.errors)
+	})
+}
@@ -1,36 +1,211 @@
 package mockhttp
 
+import (
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/William9923/go-mockhttp/jsonschema"
+	"github.com/William9923/go-mockhttp/pathregex"
+)
+
+// Response selection strategy, used to choose between multiple default responses (response with no rules)
+const (
+	strategyFirst      = "first"
+	strategyRoundRobin = "round_robin"
+	strategyRandom     = "random"
+)
+
+// Replay policy, used as the default max uses of the responses of a definition
+const (
+	replayAlways = "always"
+	replayOnce   = "once"
+)
+
 type fileBasedMockDefinition struct {
 	Host      string         `yaml:"host"`
 	Path      string         `yaml:"path"`
 	Method    string         `yaml:"method"`
 	Desc      string         `yaml:"desc"`
+	Strategy  string         `yaml:"strategy"`
+	Replay    string         `yaml:"replay"`
+	Required  bool           `yaml:"required"`
 	Responses []mockResponse `yaml:"responses"`
 
 	// deferred field
-	compiledPath     string
-	params           []string
+	pathPattern      *pathregex.Pattern
 	containParams    bool
 	containsWildcard bool
+	selectCounter    *atomic.Uint64
+	callCounter      *atomic.Uint64
+	lazy             *lazyDefinition
+	file             string
+	stub             *Stub
 }
 
 type mockResponse struct {
 	ResponseHeaders map[string]string `yaml:"response_headers"`
-	Rules           []string          `yaml:"rules"`
+	Rules           []ruleNode        `yaml:"rules"`
 	Delay           int               `yaml:"delay"`
 	StatusCode      int               `yaml:"status_code"`
 	EnableTemplate  bool              `yaml:"enable_template"`
 	Body            string            `yaml:"response_body"`
+	Callbacks       []mockCallback    `yaml:"callbacks"`
+	SchemaFile      string            `yaml:"response_schema"`
+	ETag            string            `yaml:"etag"`
+	LastModified    string            `yaml:"last_modified"`
+	SetState        map[string]string `yaml:"set_state"`
+	Timeout         bool              `yaml:"timeout"`
+	MaxUses         int               `yaml:"max_uses"`
+	RecordedAt      time.Time         `yaml:"recorded_at"`
+
+	// deferred field
+	schema       *jsonschema.Schema
+	bodyTemplate *template.Template
+	useCounter   *atomic.Uint64
+}
+
+type mockCallback struct {
+	URL            string            `yaml:"url"`
+	Method         string            `yaml:"method"`
+	Headers        map[string]string `yaml:"headers"`
+	Body           string            `yaml:"body"`
+	Delay          int               `yaml:"delay"`
+	EnableTemplate bool              `yaml:"enable_template"`
+}
+
+// ruleNode represents a single rule in the mock response rules.
+// A rule can be either an expression, a JSON Schema that request body must satisfy, or a group of (nested) rules:
+//
+//	rules:
+//	  - headers["X-Region"] == "ID"
+//	  - schema: schemas/order.json
+//	  - any_of:
+//	      - body.name == "William"
+//	      - all_of:
+//	          - body.vip == true
+//	          - body.age > 18
+type ruleNode struct {
+	Expr   string
+	Schema string
+	AnyOf  []ruleNode
+	AllOf  []ruleNode
+
+	// deferred field
+	compiled CompiledRule
+	schema   *jsonschema.Schema
+	// bodyFree is true when the expression is known to not reference the request body (body / raw).
+	bodyFree bool
+}
+
+// UnmarshalYAML decode rule from either plain expression (string), schema rule or group of rules (any_of / all_of).
+func (n *ruleNode) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var expression string
+	if err := unmarshal(&expression); err == nil {
+		n.Expr = expression
+		return nil
+	}
+
+	var group struct {
+		Schema string     `yaml:"schema"`
+		AnyOf  []ruleNode `yaml:"any_of"`
+		AllOf  []ruleNode `yaml:"all_of"`
+	}
+	if err := unmarshal(&group); err != nil {
+		return err
+	}
+	n.Schema = group.Schema
+	n.AnyOf = group.AnyOf
+	n.AllOf = group.AllOf
+	return nil
+}
+
+// name identify the definition by its http method, host and path.
+//
+// ex: GET marketplace.com/products/:id
+func (d *fileBasedMockDefinition) name() string {
+	return d.Method + " " + d.Host + d.Path
+}
+
+// compilePath compile the path pattern, and reset the definition counters.
+// The compiled pattern is reused by every request matched against the definition.
+func (d *fileBasedMockDefinition) compilePath() {
+	d.pathPattern = pathregex.Compile(d.Path)
+	params := d.pathPattern.ParamNames()
+	d.containParams = len(params) > 0
+	d.containsWildcard = findWildcard(params)
+	d.selectCounter = new(atomic.Uint64)
+	d.callCounter = new(atomic.Uint64)
+}
+
+// needsBody check whether the request body can be accessed to select the response (by any response rule),
+// so the request body is only read and parsed when needed.
+func (d *fileBasedMockDefinition) needsBody() bool {
+	for _, response := range d.Responses {
+		if rulesNeedBody(response.Rules) {
+			return true
+		}
+	}
+	return false
+}
+
+// rulesNeedBody check whether any (nested) rule access the request body:
+// schema rule always validates the body, expression only when it references body / raw (analyzed on compile).
+func rulesNeedBody(rules []ruleNode) bool {
+	for _, node := range rules {
+		switch {
+		case node.Expr != "":
+			if !node.bodyFree {
+				return true
+			}
+		case node.Schema != "":
+			return true
+		default:
+			if rulesNeedBody(node.AnyOf) || rulesNeedBody(node.AllOf) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pattern returns the compiled path pattern, compiling it on the fly for definition that is not compiled yet.
+func (d *fileBasedMockDefinition) pattern() *pathregex.Pattern {
+	if d.pathPattern == nil {
+		return pathregex.Compile(d.Path)
+	}
+	return d.pathPattern
 }
 
 func (r *mockResponse) isNil() bool {
-	return r.StatusCode == 0 && r.Body == "" && len(r.Rules) == 0
+	return r.StatusCode == 0 && r.Body == "" && len(r.Rules) == 0 && !r.Timeout
 }
 
 func (r *mockResponse) isDefault() bool {
 	return len(r.Rules) == 0
 }
 
+// isExhausted check whether the response had been used for max_uses times.
+func (r *mockResponse) isExhausted() bool {
+	return r.MaxUses > 0 && r.useCounter != nil && r.useCounter.Load() >= uint64(r.MaxUses)
+}
+
+// use consume one use of the response, return false when the response is exhausted (by concurrent requests).
+func (r *mockResponse) use() bool {
+	if r.useCounter == nil {
+		return true
+	}
+	for {
+		used := r.useCounter.Load()
+		if r.MaxUses > 0 && used >= uint64(r.MaxUses) {
+			return false
+		}
+		if r.useCounter.CompareAndSwap(used, used+1) {
+			return true
+		}
+	}
+}
+
 type params map[string]string
 
 func (p params) export() map[string]interface{} {
@@ -47,12 +222,26 @@ type incomingRequest struct {
 	Host        string
 	Method      string
 	Endpoint    string
+	RawQuery    string
+	URL         string
 	Headers     params
 	Cookies     params
 	QueryParams params
 	RouteParams params
 	Body        map[string]interface{}
 	RawBody     string
+	// CallCount is the number of times the matched definition had been invoked, including current request
+	CallCount int
+	// Definition is the name of the matched definition (see fileBasedMockDefinition.name)
+	Definition string
+	// Response is the index of the selected response of the matched definition, -1 when no response is selected
+	Response int
+	// RuleErrors is the number of rules that failed to be evaluated for the request
+	RuleErrors int
+
+	// req is the request to extract the body from, on demand (see loadBody)
+	req        *Request
+	bodyLoaded bool
 }
 
 func (req incomingRequest) collectAllParams() params {
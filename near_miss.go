@@ -0,0 +1,130 @@
+package mockhttp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxNearMisses is the max number of the closest definitions reported for an unmatched request.
+const maxNearMisses = 3
+
+// NearMissReporter is implemented by resolver adapters that can find the definitions closest to an unmatched request,
+// to diagnose misconfigured mocks (ex: typo on the host, wrong http method).
+//
+// The built-in file based resolver implements NearMissReporter.
+type NearMissReporter interface {
+	NearMisses(ctx context.Context, req *Request) ([]NearMiss, error)
+}
+
+// NearMiss describes a definition that almost matched the request, and which criteria failed.
+type NearMiss struct {
+	Definition string
+	// Mismatches are the failed criteria (host, method, path or rules), ex: `host: expected "a.com", got "b.com"`.
+	Mismatches []string
+}
+
+func (m NearMiss) String() string {
+	return fmt.Sprintf("%s (%s)", m.Definition, strings.Join(m.Mismatches, "; "))
+}
+
+// NearMissHandler is called with the closest definitions when the request has no mock response.
+type NearMissHandler func(req *Request, misses []NearMiss)
+
+// fileBasedResolver NearMisses
+// Compare the request against all the loaded definitions by host, http method and path,
+// and return the closest definitions (at most 3, with the least failed criteria first).
+//
+// Definition matching host, method and path is reported with the reason no response is selected
+// (ex: unsupported content type, response rules not fulfilled, or responses exhausted).
+func (r *fileBasedResolver) NearMisses(ctx context.Context, req *Request) ([]NearMiss, error) {
+	request, err := buildIncomingRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var misses []NearMiss
+	for _, definition := range r.loadedDefinitions() {
+		var mismatches []string
+		if definition.Host != request.Host {
+			mismatches = append(mismatches, fmt.Sprintf("host: expected %q, got %q", definition.Host, request.Host))
+		}
+		if definition.Method != request.Method {
+			mismatches = append(mismatches, fmt.Sprintf("method: expected %q, got %q", definition.Method, request.Method))
+		}
+		if !definition.pattern().Match(request.Endpoint) {
+			mismatches = append(mismatches, fmt.Sprintf("path: expected %q, got %q", definition.Path, request.Endpoint))
+		}
+
+		switch {
+		case len(mismatches) == 3:
+			continue
+		case len(mismatches) == 0:
+			mismatches = append(mismatches, r.explainUnselected(request, definition))
+		}
+		misses = append(misses, NearMiss{Definition: definition.name(), Mismatches: mismatches})
+	}
+
+	sort.SliceStable(misses, func(i, j int) bool {
+		return len(misses[i].Mismatches) < len(misses[j].Mismatches)
+	})
+	if len(misses) > maxNearMisses {
+		misses = misses[:maxNearMisses]
+	}
+	return misses, nil
+}
+
+// fileBasedResolver explainUnselected describe why none of the responses of the matching definition is selected.
+func (r *fileBasedResolver) explainUnselected(request *incomingRequest, definition fileBasedMockDefinition) string {
+	definition, err := r.parseLazy(definition)
+	if err != nil {
+		return fmt.Sprintf("definition: %s", err)
+	}
+
+	request.RouteParams = definition.pattern().Params(request.Endpoint)
+	request.CallCount = int(definition.callCounter.Load())
+	if err := r.validateTarget(request); err != nil {
+		return fmt.Sprintf("request: %s", err)
+	}
+	if definition.needsBody() {
+		if err := request.loadBody(r.maxBodySize, r.bodyLimitPolicy); err != nil {
+			return fmt.Sprintf("request: %s", err)
+		}
+	}
+
+	exhausted, stale := 0, 0
+	for _, response := range r.explainResponses(request, definition) {
+		if response.Exhausted {
+			exhausted++
+		}
+		if response.Stale {
+			stale++
+		}
+	}
+	if exhausted > 0 && exhausted == len(definition.Responses) {
+		return "responses: all responses are exhausted (max_uses)"
+	}
+	if stale > 0 && exhausted+stale == len(definition.Responses) {
+		return "responses: all responses are exhausted (max_uses) or stale (max recording age)"
+	}
+	return "rules: no response rules fulfilled"
+}
+
+// reportNearMisses call the NearMissHandler with the closest definitions of the unmatched request,
+// when the resolver support it.
+func (c *Client) reportNearMisses(resolver ResolverAdapter, req *Request) {
+	reporter, ok := resolver.(NearMissReporter)
+	if !ok {
+		return
+	}
+	if err := req.rewindBody(); err != nil {
+		return
+	}
+
+	misses, err := reporter.NearMisses(req.Context(), req)
+	if err != nil {
+		return
+	}
+	c.NearMissHandler(req, misses)
+}
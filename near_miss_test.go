@@ -0,0 +1,97 @@
+package mockhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileBasedResolver_NearMisses(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /products/:id
+method: GET
+responses:
+  - status_code: 200
+    rules:
+      - routeParams.id == "1"
+`, `
+host: marketplace.com
+path: /orders
+method: POST
+responses:
+  - status_code: 200
+`, `
+host: payment.com
+path: /charges
+method: POST
+responses:
+  - status_code: 200
+`)
+
+	tests := []struct {
+		name string
+		url  string
+		want []NearMiss
+	}{
+		{
+			name: "wrong host",
+			url:  "http://marketplace.co/products/1",
+			want: []NearMiss{
+				{Definition: "GET marketplace.com/products/:id", Mismatches: []string{`host: expected "marketplace.com", got "marketplace.co"`}},
+			},
+		},
+		{
+			name: "rules not fulfilled",
+			url:  "http://marketplace.com/products/2",
+			want: []NearMiss{
+				{Definition: "GET marketplace.com/products/:id", Mismatches: []string{"rules: no response rules fulfilled"}},
+				{Definition: "POST marketplace.com/orders", Mismatches: []string{`method: expected "POST", got "GET"`, `path: expected "/orders", got "/products/2"`}},
+			},
+		},
+		{
+			name: "wrong method",
+			url:  "http://marketplace.com/orders",
+			want: []NearMiss{
+				{Definition: "GET marketplace.com/products/:id", Mismatches: []string{`path: expected "/products/:id", got "/orders"`}},
+				{Definition: "POST marketplace.com/orders", Mismatches: []string{`method: expected "POST", got "GET"`}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			misses, err := resolver.NearMisses(context.Background(), newTestRequest(t, http.MethodGet, tt.url, ""))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, misses)
+		})
+	}
+}
+
+func TestClient_Do_NearMissHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := newTestClient(newTestResolver(t, `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+`))
+	var reported []NearMiss
+	client.NearMissHandler = func(req *Request, misses []NearMiss) {
+		reported = append(reported, misses...)
+	}
+
+	_, err := client.Get("http://marketplace.com/products")
+	assert.Nil(t, err)
+	assert.Empty(t, reported)
+
+	_, err = client.Get(server.URL + "/products")
+	assert.Nil(t, err)
+	assert.Len(t, reported, 1)
+	assert.Equal(t, "GET marketplace.com/products", reported[0].Definition)
+}
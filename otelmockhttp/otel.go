@@ -0,0 +1,109 @@
+// Package otelmockhttp provides OpenTelemetry tracing instrumentation for mockhttp.Client,
+// so traces clearly distinguish mocked calls from real ones.
+//
+// ex:
+//
+//	client := mockhttp.NewClient(resolver)
+//	otelmockhttp.Instrument(client)
+package otelmockhttp
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+
+	mockhttp "github.com/William9923/go-mockhttp"
+)
+
+const instrumentationName = "github.com/William9923/go-mockhttp/otelmockhttp"
+
+// Attributes set on the request span, describing how the request is resolved.
+const (
+	// MockedKey is true when the response is served from the mock resolver.
+	MockedKey = attribute.Key("mockhttp.mocked")
+	// BypassedKey is true when the request skip the mock resolver.
+	BypassedKey = attribute.Key("mockhttp.bypassed")
+	// DefinitionKey is the matched mock definition (ex: GET marketplace.com/products).
+	DefinitionKey = attribute.Key("mockhttp.definition")
+	// ResolveLatencyKey is the time spent resolving the mock response, in milliseconds.
+	ResolveLatencyKey = attribute.Key("mockhttp.resolve_latency_ms")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+}
+
+// Option configures the instrumentation.
+type Option func(*config)
+
+// WithTracerProvider set the tracer provider used to create the spans, the global tracer provider is used by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// Instrument create a client span for each request made by the client, annotated with the mock hit / miss,
+// matched definition and resolve latency.
+//
+// The existing client ResolveHook (if any) is still called.
+func Instrument(client *mockhttp.Client, opts ...Option) {
+	cfg := config{tracerProvider: otel.GetTracerProvider()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	tracer := cfg.tracerProvider.Tracer(instrumentationName)
+
+	client.Use(middleware(tracer))
+
+	next := client.ResolveHook
+	client.SetResolveHook(func(req *mockhttp.Request, info mockhttp.ResolveInfo) {
+		annotate(trace.SpanFromContext(req.Context()), info)
+		if next != nil {
+			next(req, info)
+		}
+	})
+}
+
+func middleware(tracer trace.Tracer) mockhttp.Middleware {
+	return func(next mockhttp.DoFunc) mockhttp.DoFunc {
+		return func(req *mockhttp.Request) (*http.Response, error) {
+			ctx, span := tracer.Start(req.Context(), "HTTP "+req.Method,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(semconv.HTTPMethod(req.Method), semconv.HTTPURL(req.URL.String())),
+			)
+			defer span.End()
+
+			resp, err := next(req.WithContext(ctx))
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return resp, err
+			}
+
+			span.SetAttributes(semconv.HTTPStatusCode(resp.StatusCode))
+			if resp.StatusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+			}
+			return resp, nil
+		}
+	}
+}
+
+func annotate(span trace.Span, info mockhttp.ResolveInfo) {
+	span.SetAttributes(
+		MockedKey.Bool(info.Mocked),
+		BypassedKey.Bool(info.Bypassed),
+		ResolveLatencyKey.Float64(float64(info.Latency.Microseconds())/1000),
+	)
+	if info.Definition != "" {
+		span.SetAttributes(DefinitionKey.String(info.Definition))
+	}
+	if info.Err != nil {
+		span.RecordError(info.Err)
+	}
+}
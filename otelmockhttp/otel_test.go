@@ -0,0 +1,70 @@
+package otelmockhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	mockhttp "github.com/William9923/go-mockhttp"
+)
+
+func TestInstrument(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	definition := `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+`
+	if err := os.WriteFile(filepath.Join(dir, "products.yaml"), []byte(definition), 0o644); err != nil {
+		t.Fatalf("unable to write file: %v", err)
+	}
+	resolver, err := mockhttp.NewFileResolverAdapter(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, resolver.LoadDefinition(context.Background()))
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	client := mockhttp.NewClient(resolver)
+	client.Logger = nil
+	Instrument(client, WithTracerProvider(provider))
+
+	_, err = client.Get("http://marketplace.com/products")
+	assert.Nil(t, err)
+	_, err = client.Get(server.URL)
+	assert.Nil(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	mocked := attributes(spans[0].Attributes())
+	assert.Equal(t, true, mocked[MockedKey].AsBool())
+	assert.Equal(t, "GET marketplace.com/products", mocked[DefinitionKey].AsString())
+
+	passthrough := attributes(spans[1].Attributes())
+	assert.Equal(t, false, passthrough[MockedKey].AsBool())
+	assert.NotContains(t, passthrough, DefinitionKey)
+}
+
+func attributes(kvs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	result := make(map[attribute.Key]attribute.Value)
+	for _, kv := range kvs {
+		result[kv.Key] = kv.Value
+	}
+	return result
+}
@@ -149,6 +149,45 @@ func bufApp(buf *[]byte, s string, w int, c byte) {
 	b[w] = c
 }
 
+var (
+	trailingRe = regexp.MustCompile(`\/*\*?$`)
+	leadingRe  = regexp.MustCompile(`^\/*`)
+	paramsRe   = regexp.MustCompile(`:(\w+)`)
+
+	braceParamRe    = regexp.MustCompile(`^\{(\w+)\}$`)
+	braceWildcardRe = regexp.MustCompile(`^\{(\w+)\.\.\.\}$`)
+)
+
+// normalizePattern convert the Go 1.22 ServeMux-style segments into the colon syntax, following the ServeMux rules:
+//
+//  1. {name} segment is path param, ex: /products/{id} => /products/:id
+//
+//  2. {name...} last segment is wildcard, ex: /files/{path...} => /files/*
+//
+//  3. {$} last segment only match the path itself, which is always the case for the colon syntax
+//
+// It returns the normalized pattern, and the wildcard name (of {name...}) if any.
+func normalizePattern(pattern string) (string, string) {
+	if !strings.Contains(pattern, "{") {
+		return pattern, ""
+	}
+
+	wildcardName := ""
+	segments := strings.Split(pattern, "/")
+	for idx, segment := range segments {
+		last := idx == len(segments)-1
+		if match := braceParamRe.FindStringSubmatch(segment); match != nil {
+			segments[idx] = ":" + match[1]
+		} else if match := braceWildcardRe.FindStringSubmatch(segment); match != nil && last {
+			segments[idx] = "*"
+			wildcardName = match[1]
+		} else if segment == "{$}" && last {
+			segments[idx] = ""
+		}
+	}
+	return strings.Join(segments, "/"), wildcardName
+}
+
 // CompilePath compile usual HTTP endpoint path to a canonical regex based path
 // for categorizing exact endpoint path, wildcard and path params.
 // It output the canonical regular expression to match the path, and the path param names
@@ -165,14 +204,16 @@ func bufApp(buf *[]byte, s string, w int, c byte) {
 //  5. Extract all path param (ex: /path/:id => id is path param)
 //
 //  6. Also extract if wildcards exist in path (ex: /path/*)
+//
+// The ServeMux-style segments ({id}, {path...}) are supported as well, ex: /path/{id} is the same as /path/:id.
 func CompilePath(path string, caseSensitive bool, end bool) (*regexp.Regexp, []string) {
+	path, _ = normalizePattern(path)
 
-	regexpSource := regexp.MustCompile(`\/*\*?$`).ReplaceAllString(path, "")
-	regexpSource = regexp.MustCompile(`^\/*`).ReplaceAllString(regexpSource, "/")
+	regexpSource := trailingRe.ReplaceAllString(path, "")
+	regexpSource = leadingRe.ReplaceAllString(regexpSource, "/")
 	regexpSource = regexp.QuoteMeta(regexpSource)
 	regexpSource = strings.ReplaceAll(regexpSource, "/", "\\/")
 
-	paramsRe := regexp.MustCompile(`:(\w+)`)
 	matches := paramsRe.FindAllStringSubmatch(regexpSource, -1)
 	paramNames := make([]string, len(matches))
 	for i, match := range matches {
@@ -201,48 +242,92 @@ func CompilePath(path string, caseSensitive bool, end bool) (*regexp.Regexp, []s
 	return matcher, paramNames
 }
 
-// MatchPath applies mathing between HTTP path and canonical path
-// to check whether the pattern match / not.
-//
-//	It output the matching result (boolean), and the path param resolved values
-func MatchPath(path string, pattern string) bool {
-	matcher, paramNames := CompilePath(CleanPath(pattern), true, true)
+// Pattern is a compiled path pattern (ex: /products/:id, /products/*, /products/{id}, /files/{path...}),
+// meant to be compiled once and used to match many paths.
+type Pattern struct {
+	source     string
+	matcher    *regexp.Regexp
+	paramNames []string
+	// wildcardName is the name of the {name...} wildcard, empty for * wildcard.
+	wildcardName string
+}
+
+// Compile compile the (cleaned) path pattern, with the same rules as MatchPath.
+func Compile(pattern string) *Pattern {
+	normalized, wildcardName := normalizePattern(pattern)
+	source := CleanPath(normalized)
+	matcher, paramNames := CompilePath(source, true, true)
+	return &Pattern{source: source, matcher: matcher, paramNames: paramNames, wildcardName: wildcardName}
+}
 
-	res := matcher.FindStringSubmatch(path)
+// Source returns the cleaned path pattern in the colon syntax, ex: /products/:id for products//{id}.
+func (p *Pattern) Source() string {
+	return p.source
+}
+
+// String returns the regular expression matching the pattern.
+func (p *Pattern) String() string {
+	return p.matcher.String()
+}
+
+// ParamNames returns the path param names of the pattern, with "*" for wildcard (including {name...} wildcard).
+func (p *Pattern) ParamNames() []string {
+	return p.paramNames
+}
+
+// Match check whether the path match the pattern.
+func (p *Pattern) Match(path string) bool {
+	res := p.matcher.FindStringSubmatch(path)
 	if res == nil {
 		return false
 	}
 
-	if len(paramNames) == 0 {
+	if len(p.paramNames) == 0 {
 		return true
 	}
 
-	if len(paramNames) != len(res)-1 {
-		return false
-	}
-	return true
+	return len(p.paramNames) == len(res)-1
 }
 
-func ExtractPathParam(path string, pattern string) map[string]string {
-	matcher, paramNames := CompilePath(CleanPath(pattern), true, true)
-	res := matcher.FindStringSubmatch(path)
+// Params extract the path param values of the path, nil when the path does not match the pattern.
+func (p *Pattern) Params(path string) map[string]string {
+	res := p.matcher.FindStringSubmatch(path)
 	if res == nil {
 		return nil
 	}
 
-	if len(paramNames) == 0 {
+	if len(p.paramNames) == 0 {
 		return make(map[string]string)
 	}
 
-	if len(paramNames) != len(res)-1 {
+	if len(p.paramNames) != len(res)-1 {
 		return nil
 	}
 
 	params := make(map[string]string)
 
 	for idx, parseRes := range res[1:] {
-		params[paramNames[idx]] = parseRes
+		params[p.paramNames[idx]] = parseRes
+	}
+	// {name...} wildcard is available by its name, as well as "*"
+	if p.wildcardName != "" {
+		params[p.wildcardName] = params["*"]
 	}
 
 	return params
 }
+
+// MatchPath applies mathing between HTTP path and canonical path
+// to check whether the pattern match / not.
+//
+// Use Compile to match many paths against the same pattern, without compiling the pattern every time.
+func MatchPath(path string, pattern string) bool {
+	return Compile(pattern).Match(path)
+}
+
+// ExtractPathParam extract the path param values of the path matching the pattern.
+//
+// Use Compile to extract from many paths with the same pattern, without compiling the pattern every time.
+func ExtractPathParam(path string, pattern string) map[string]string {
+	return Compile(pattern).Params(path)
+}
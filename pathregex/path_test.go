@@ -201,3 +201,74 @@ func TestMatchPath(t *testing.T) {
 		})
 	}
 }
+
+func TestCompile(t *testing.T) {
+	pattern := Compile("/products/:id/*")
+	if pattern.String() != `^\/products\/([^\/]+)(?:\/(.+)|\/*)$` {
+		t.Errorf("Compile(/products/:id/*) regex = %v", pattern.String())
+	}
+	if !reflect.DeepEqual(pattern.ParamNames(), []string{"id", "*"}) {
+		t.Errorf("Compile(/products/:id/*) param names = %v", pattern.ParamNames())
+	}
+
+	// the compiled pattern is reusable across paths
+	for path, expected := range map[string]map[string]string{
+		"/products/1":            {"id": "1", "*": ""},
+		"/products/2/reviews/10": {"id": "2", "*": "reviews/10"},
+		"/orders/1":              nil,
+	} {
+		if pattern.Match(path) != (expected != nil) {
+			t.Errorf("Match(%v) = %v, expected %v", path, pattern.Match(path), expected != nil)
+		}
+		if params := pattern.Params(path); !reflect.DeepEqual(params, expected) {
+			t.Errorf("Params(%v) = %v, expected %v", path, params, expected)
+		}
+	}
+}
+
+func TestCompile_ServeMuxSyntax(t *testing.T) {
+	tests := []struct {
+		pattern string
+		source  string
+		path    string
+		params  map[string]string
+	}{
+		{"/products/{id}", "/products/:id", "/products/1", map[string]string{"id": "1"}},
+		{"/products/{id}/reviews/{reviewID}", "/products/:id/reviews/:reviewID", "/products/1/reviews/2", map[string]string{"id": "1", "reviewID": "2"}},
+		{"/files/{path...}", "/files/*", "/files/docs/report.pdf", map[string]string{"*": "docs/report.pdf", "path": "docs/report.pdf"}},
+		{"/{path...}", "/*", "/docs/report.pdf", map[string]string{"*": "docs/report.pdf", "path": "docs/report.pdf"}},
+		{"/products/{$}", "/products/", "/products", map[string]string{}},
+		{"/products/{$}", "/products/", "/products/1", nil},
+		// only the whole segment is param, and {name...} must be the last segment
+		{"/files/{name}.json", "/files/{name}.json", "/files/{name}.json", map[string]string{}},
+		{"/files/{path...}/raw", "/files/{path...}/raw", "/files/a/raw", nil},
+	}
+	for _, tt := range tests {
+		pattern := Compile(tt.pattern)
+		if pattern.Source() != tt.source {
+			t.Errorf("Compile(%v) source = %v, expected %v", tt.pattern, pattern.Source(), tt.source)
+		}
+		if params := pattern.Params(tt.path); !reflect.DeepEqual(params, tt.params) {
+			t.Errorf("Compile(%v).Params(%v) = %v, expected %v", tt.pattern, tt.path, params, tt.params)
+		}
+	}
+
+	if !MatchPath("/products/1", "/products/{id}") {
+		t.Errorf("MatchPath(/products/1, /products/{id}) = false")
+	}
+}
+
+func BenchmarkMatchPath(b *testing.B) {
+	b.Run("MatchPath", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			MatchPath("/products/1/reviews", "/products/:id/reviews")
+		}
+	})
+	b.Run("Compiled", func(b *testing.B) {
+		pattern := Compile("/products/:id/reviews")
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			pattern.Match("/products/1/reviews")
+		}
+	})
+}
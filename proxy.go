@@ -0,0 +1,43 @@
+package mockhttp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/go-cleanhttp"
+)
+
+// SetProxy set the outbound proxy of the passthrough (non-mocked) requests, without replacing the whole HTTPClient.
+// By default, HTTP(S)_PROXY and NO_PROXY environment variables are honored (http.ProxyFromEnvironment),
+// nil proxy disable proxying.
+//
+// ex:
+//
+//	proxyURL, _ := url.Parse("http://proxy.corp.internal:3128")
+//	err := client.SetProxy(http.ProxyURL(proxyURL))
+//
+// Only HTTPClient with *http.Transport (or nil transport) is supported, otherwise ErrUnsupportedTransport is returned.
+// SetProxy is not safe for concurrent use with Do, set the proxy before the client is used.
+func (c *Client) SetProxy(proxy func(*http.Request) (*url.URL, error)) error {
+	if c.HTTPClient == nil {
+		c.HTTPClient = cleanhttp.DefaultPooledClient()
+	}
+
+	var transport *http.Transport
+	switch t := c.HTTPClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		// clone the transport, as it might be shared with other clients
+		transport = t.Clone()
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedTransport, t)
+	}
+	transport.Proxy = proxy
+
+	httpClient := *c.HTTPClient
+	httpClient.Transport = transport
+	c.HTTPClient = &httpClient
+	return nil
+}
@@ -0,0 +1,39 @@
+package mockhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_SetProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	assert.Nil(t, err)
+
+	t.Run("proxy passthrough request", func(t *testing.T) {
+		client := newTestClient(noMockResolver{})
+		assert.Nil(t, client.SetProxy(http.ProxyURL(proxyURL)))
+
+		resp, err := client.Get("http://inventory.internal/stocks")
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"http://inventory.internal/stocks"}, proxied)
+	})
+
+	t.Run("unsupported transport", func(t *testing.T) {
+		client := newTestClient(noMockResolver{})
+		client.HTTPClient = &http.Client{Transport: roundTripFunc(http.DefaultTransport.RoundTrip)}
+
+		assert.ErrorIs(t, client.SetProxy(http.ProxyURL(proxyURL)), ErrUnsupportedTransport)
+	})
+}
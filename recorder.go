@@ -0,0 +1,171 @@
+package mockhttp
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Recorder records the actual upstream interaction of the request that does not match any mock definition,
+// ex: to bootstrap the mock definitions of an existing integration.
+type Recorder interface {
+	Record(req *Request, resp *http.Response, body []byte) error
+}
+
+// recordedDefinition is the mock definition spec written by the file recorder.
+type recordedDefinition struct {
+	Host      string             `yaml:"host"`
+	Path      string             `yaml:"path"`
+	Method    string             `yaml:"method"`
+	Desc      string             `yaml:"desc,omitempty"`
+	Responses []recordedResponse `yaml:"responses"`
+}
+
+type recordedResponse struct {
+	RecordedAt      time.Time         `yaml:"recorded_at"`
+	Rules           []string          `yaml:"rules,omitempty"`
+	StatusCode      int               `yaml:"status_code"`
+	ResponseHeaders map[string]string `yaml:"response_headers,omitempty"`
+	Body            string            `yaml:"response_body,omitempty"`
+}
+
+// skippedRecordHeaders are the response headers that are not recorded, as they are generated per response.
+var skippedRecordHeaders = []string{"Content-Length", "Date", "Connection", "Transfer-Encoding", "Keep-Alive"}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// recordPatternChars are the path pattern characters of the mock definition (path params and wildcards).
+const recordPatternChars = ":*{}"
+
+type fileRecorder struct {
+	dir string
+	now func() time.Time
+	mu  sync.Mutex
+
+	redactHeaders   []string
+	redactJSONPaths []string
+	redactPatterns  []*regexp.Regexp
+}
+
+// NewFileRecorder creates recorder that write the recorded interactions as YAML mock definitions into the directory,
+// one file per method, host and path. Interactions with different request query / body are appended as responses
+// with rules (rawQuery / raw) into the same definition.
+//
+// Each recorded response has the recorded_at timestamp, see WithMaxRecordingAge to re-record the stale responses.
+// The recorded definitions are served once the definitions are loaded again by the resolver.
+//
+// The request with path containing the path pattern characters (: * { }) is not recorded, ErrUnrecordablePath is returned.
+//
+// Sensitive data (tokens, PII) can be redacted before written to disk, see WithRedactHeaders, WithRedactJSONPaths
+// and WithRedactPatterns. Note that the rules of redacted request query / body won't match the original request.
+func NewFileRecorder(dir string, opts ...RecorderOption) Recorder {
+	recorder := &fileRecorder{dir: dir, now: time.Now}
+	for _, opt := range opts {
+		opt(recorder)
+	}
+	return recorder
+}
+
+func (r *fileRecorder) Record(req *Request, resp *http.Response, body []byte) error {
+	// the path is written as is into the definition pattern, where these characters are path params / wildcards
+	// (ex: /v1/items:batchGet would also match /v1/itemsX), so such paths can't be recorded faithfully
+	if strings.ContainsAny(req.URL.Path, recordPatternChars) {
+		return fmt.Errorf("%w: %s", ErrUnrecordablePath, req.URL.Path)
+	}
+
+	reqBody, err := req.BodyBytes()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := filepath.Join(r.dir, recordFileName(req))
+	definition := recordedDefinition{
+		Host:   req.URL.Host,
+		Path:   req.URL.Path,
+		Method: req.Method,
+		Desc:   "recorded from upstream",
+	}
+	if content, err := os.ReadFile(path); err == nil {
+		if err := yaml.Unmarshal(content, &definition); err != nil {
+			return fmt.Errorf("unable to parse recorded definition %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if len(reqBody) > 0 {
+		reqBody = r.redactBody(reqBody)
+	}
+	if len(body) > 0 {
+		body = r.redactBody(body)
+	}
+	headers := recordHeaders(resp.Header)
+	r.redactHeaderValues(headers)
+
+	response := recordedResponse{
+		RecordedAt:      r.now().UTC().Truncate(time.Second),
+		Rules:           recordRules(r.redactText(req.URL.RawQuery), reqBody),
+		StatusCode:      resp.StatusCode,
+		ResponseHeaders: headers,
+		Body:            string(body),
+	}
+
+	// replace the previously recorded response of the same request, and keep the default response (no rules) last
+	responses := filter[recordedResponse](definition.Responses, func(data recordedResponse) bool {
+		return strings.Join(data.Rules, "\n") != strings.Join(response.Rules, "\n")
+	})
+	if len(response.Rules) > 0 {
+		responses = append([]recordedResponse{response}, responses...)
+	} else {
+		responses = append(responses, response)
+	}
+	definition.Responses = responses
+
+	content, err := yaml.Marshal(definition)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+// recordFileName build the definition file name from the request method, host and path.
+//
+// ex: GET api.example.com/products/1 => get_api.example.com_products_1.yaml
+func recordFileName(req *Request) string {
+	name := strings.ToLower(req.Method) + "_" + req.URL.Host + req.URL.Path
+	return strings.Trim(unsafeFileNameChars.ReplaceAllString(name, "_"), "_") + ".yaml"
+}
+
+// recordRules build the rules that match the exact request query and body.
+func recordRules(rawQuery string, body []byte) []string {
+	var rules []string
+	if rawQuery != "" {
+		rules = append(rules, "rawQuery == "+strconv.Quote(rawQuery))
+	}
+	if len(body) > 0 {
+		rules = append(rules, "raw == "+strconv.Quote(string(body)))
+	}
+	return rules
+}
+
+func recordHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string)
+	for key := range header {
+		if in[string](key, skippedRecordHeaders) {
+			continue
+		}
+		headers[key] = header.Get(key)
+	}
+	return headers
+}
@@ -0,0 +1,110 @@
+package mockhttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Record(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"echo":` + string(body) + `}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	client := newTestClient(noMockResolver{})
+	client.Recorder = NewFileRecorder(dir)
+
+	for _, payload := range []string{`{"id":1}`, `{"id":2}`} {
+		resp, err := client.Post(server.URL+"/products", "application/json", []byte(payload))
+		assert.Nil(t, err)
+
+		// the response body can still be read after recorded
+		body, err := io.ReadAll(resp.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"echo":`+payload+`}`, string(body))
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+
+	content, err := os.ReadFile(files[0])
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), `raw == "{\"id\":2}"`)
+	assert.Contains(t, string(content), "recorded_at: ")
+
+	resolver, err := NewFileResolverAdapter(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, resolver.LoadDefinition(context.Background()))
+
+	replay := newTestClient(resolver)
+	replay.MockOnly = true
+	resp, err := replay.StandardClient().Post(server.URL+"/products", "application/json", strings.NewReader(`{"id":1}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"echo":{"id":1}}`, string(body))
+}
+
+func TestFileRecorder_Redaction(t *testing.T) {
+	dir := t.TempDir()
+	recorder := NewFileRecorder(dir,
+		WithRedactHeaders("set-cookie"),
+		WithRedactJSONPaths("token", "users.email"),
+		WithRedactPatterns(regexp.MustCompile(`sk_live_[a-z0-9]+`)),
+	)
+
+	req, err := NewRequest(http.MethodPost, "http://payment.com/charges?key=sk_live_abc123", []byte(`{"token":"secret","amount":10}`))
+	assert.Nil(t, err)
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Set-Cookie":   []string{"session=secret"},
+			"Content-Type": []string{"application/json"},
+		},
+	}
+	body := []byte(`{"users":[{"email":"john@doe.com","name":"john"}]}`)
+	assert.Nil(t, recorder.Record(req, resp, body))
+
+	content, err := os.ReadFile(filepath.Join(dir, "post_payment.com_charges.yaml"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(content), "secret")
+	assert.NotContains(t, string(content), "sk_live_abc123")
+	assert.NotContains(t, string(content), "john@doe.com")
+	assert.Contains(t, string(content), "Set-Cookie: '[REDACTED]'")
+	assert.Contains(t, string(content), `"name":"john"`)
+}
+
+func TestFileRecorder_PatternCharacters(t *testing.T) {
+	dir := t.TempDir()
+	recorder := NewFileRecorder(dir)
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+
+	for _, url := range []string{
+		"http://api.example.com/v1/items:batchGet",
+		"http://api.example.com/v1/files/*",
+		"http://api.example.com/v1/files/%7Bid%7D",
+	} {
+		req, err := NewRequest(http.MethodGet, url, nil)
+		assert.Nil(t, err)
+		assert.ErrorIs(t, recorder.Record(req, resp, nil), ErrUnrecordablePath)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	assert.Nil(t, err)
+	assert.Empty(t, files)
+}
@@ -0,0 +1,98 @@
+package mockhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// redactedValue replace the sensitive data in the recorded interaction.
+const redactedValue = "[REDACTED]"
+
+// RecorderOption configure optional behavior of the file recorder.
+type RecorderOption func(*fileRecorder)
+
+// WithRedactHeaders redact the value of the response headers (case insensitive) before written to disk.
+//
+// ex: WithRedactHeaders("Set-Cookie", "Authorization")
+func WithRedactHeaders(names ...string) RecorderOption {
+	return func(r *fileRecorder) {
+		for _, name := range names {
+			r.redactHeaders = append(r.redactHeaders, http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+// WithRedactJSONPaths redact the value of the JSON fields (dot separated path, arrays are traversed) in
+// the request and response body before written to disk.
+//
+// ex: WithRedactJSONPaths("token", "user.email") redact {"user": {"email": "..."}} and {"user": [{"email": "..."}]}
+func WithRedactJSONPaths(paths ...string) RecorderOption {
+	return func(r *fileRecorder) {
+		r.redactJSONPaths = append(r.redactJSONPaths, paths...)
+	}
+}
+
+// WithRedactPatterns redact every match of the patterns in the request query, request body, response headers
+// and response body before written to disk.
+//
+// ex: WithRedactPatterns(regexp.MustCompile(`Bearer [A-Za-z0-9._-]+`))
+func WithRedactPatterns(patterns ...*regexp.Regexp) RecorderOption {
+	return func(r *fileRecorder) {
+		r.redactPatterns = append(r.redactPatterns, patterns...)
+	}
+}
+
+// redactText redact every match of the redaction patterns.
+func (r *fileRecorder) redactText(text string) string {
+	for _, pattern := range r.redactPatterns {
+		text = pattern.ReplaceAllString(text, redactedValue)
+	}
+	return text
+}
+
+// redactBody redact the JSON paths (for JSON body) and the patterns of the body.
+func (r *fileRecorder) redactBody(body []byte) []byte {
+	if len(r.redactJSONPaths) > 0 {
+		var data interface{}
+		if err := json.Unmarshal(body, &data); err == nil {
+			for _, path := range r.redactJSONPaths {
+				redactJSONPath(data, strings.Split(path, "."))
+			}
+			if redacted, err := json.Marshal(data); err == nil {
+				body = redacted
+			}
+		}
+	}
+	return []byte(r.redactText(string(body)))
+}
+
+func (r *fileRecorder) redactHeaderValues(headers map[string]string) {
+	for key, value := range headers {
+		if in[string](key, r.redactHeaders) {
+			headers[key] = redactedValue
+			continue
+		}
+		headers[key] = r.redactText(value)
+	}
+}
+
+func redactJSONPath(data interface{}, path []string) {
+	switch value := data.(type) {
+	case []interface{}:
+		for _, item := range value {
+			redactJSONPath(item, path)
+		}
+	case map[string]interface{}:
+		child, ok := value[path[0]]
+		if !ok {
+			return
+		}
+		if len(path) == 1 {
+			value[path[0]] = redactedValue
+			return
+		}
+		redactJSONPath(child, path[1:])
+	}
+}
@@ -12,11 +12,12 @@ import (
 type ReaderFunc func() (io.Reader, error)
 
 // ResponseHandlerFunc is a type of function that takes in a Response, and does something with it.
-// The ResponseHandlerFunc is called when the HTTP client successfully receives a response and the
+// The ResponseHandlerFunc is called when the HTTP client successfully receives a response, both mocked and
+// actual upstream response. When it returns an error, Do returns the error instead of the response.
 // The response body is not automatically closed. It must be closed either by the ResponseHandlerFunc or
 // by the caller out-of-band. Failure to do so will result in a memory leak.
 //
-// Main purposes: to enable delay for mocking http calls
+// Main purposes: to enable delay / per-request post-processing for mocking http calls
 type ResponseHandlerFunc func(*http.Response) error
 
 // LenReader is an interface implemented by many in-memory io.Reader's. Used
@@ -102,6 +103,24 @@ func (r *Request) SetBody(rawBody interface{}) error {
 	return nil
 }
 
+// rewindBody set a fresh request body from the body reader (if any),
+// so the request body can be read again (ex: by the resolver, then by the upstream call).
+func (r *Request) rewindBody() error {
+	if r.body == nil {
+		return nil
+	}
+	body, err := r.body()
+	if err != nil {
+		return err
+	}
+	if c, ok := body.(io.ReadCloser); ok {
+		r.Body = c
+	} else {
+		r.Body = io.NopCloser(body)
+	}
+	return nil
+}
+
 // WriteTo allows copying the request body into a writer.
 //
 // It writes data to w until there's no more data to write or
@@ -222,8 +241,10 @@ func getBodyReaderAndContentLength(rawBody interface{}) (ReaderFunc, int64, erro
 }
 
 // FromRequest wraps an http.Request in a retryablehttp.Request
+//
+// Request without body (nil or http.NoBody) is wrapped as is, without capturing the body.
 func FromRequest(r *http.Request) (*Request, error) {
-	if r.Body != nil {
+	if r.Body != nil && r.Body != http.NoBody {
 		bodyReader, _, err := getBodyReaderAndContentLength(r.Body)
 		if err != nil {
 			return nil, err
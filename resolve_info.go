@@ -0,0 +1,81 @@
+package mockhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ResolveInfo describes how the request is resolved by Client.Do, ex: to tell mocked calls from real ones.
+type ResolveInfo struct {
+	// Mocked is true when the response is served from the mock resolver.
+	Mocked bool
+	// Bypassed is true when the request skip the mock resolver (WithBypass / EnableMock(false)).
+	Bypassed bool
+	// Definition is the matched mock definition, empty when no definition matched
+	// (or the resolver does not report it, see SetMatchedDefinition).
+	Definition string
+	// Response is the index of the selected response of the matched definition (in the definition order),
+	// -1 when no response is selected (or the resolver does not report it, see SetMatchedResponse).
+	Response int
+	// Latency is the time spent resolving the mock response (including the mock delay).
+	Latency time.Duration
+	// RuleErrors is the number of rules that failed to be evaluated (ex: comparing string with number).
+	RuleErrors int
+	// Err is the error returned by the resolver, no mock response found (ErrNoMockResponse) is not considered as an error.
+	Err error
+}
+
+// ResolveHook is called by Client.Do after the request is resolved (or bypassed), before the request is sent upstream.
+type ResolveHook func(req *Request, info ResolveInfo)
+
+type resolveInfoContextKey struct{}
+
+// SetMatchedDefinition report the mock definition matched by the request, into the ResolveInfo.
+// It is meant to be called by resolver adapters during Resolve, with the context passed by Client.Do.
+func SetMatchedDefinition(ctx context.Context, definition string) {
+	if info, ok := ctx.Value(resolveInfoContextKey{}).(*ResolveInfo); ok {
+		info.Definition = definition
+	}
+}
+
+// SetMatchedResponse report the index of the response selected from the matched definition, into the ResolveInfo.
+// It is meant to be called by resolver adapters during Resolve, with the context passed by Client.Do.
+func SetMatchedResponse(ctx context.Context, idx int) {
+	if info, ok := ctx.Value(resolveInfoContextKey{}).(*ResolveInfo); ok {
+		info.Response = idx
+	}
+}
+
+// reportRuleErrors report the number of rules failed to be evaluated during Resolve, into the ResolveInfo.
+func reportRuleErrors(ctx context.Context, count int) {
+	if info, ok := ctx.Value(resolveInfoContextKey{}).(*ResolveInfo); ok {
+		info.RuleErrors = count
+	}
+}
+
+// resolve the mock response of the request (unless bypassed), and collect the ResolveInfo along the way.
+// The simulated error (see SimulatedError) is returned separately, as it should fail the request as is.
+func resolve(resolver ResolverAdapter, req *Request, bypass bool) (*http.Response, ResolveInfo, error) {
+	info := ResolveInfo{Bypassed: bypass, Response: -1}
+	if bypass {
+		return nil, info, nil
+	}
+
+	ctx := context.WithValue(req.Context(), resolveInfoContextKey{}, &info)
+	start := time.Now()
+	resp, err := resolver.Resolve(ctx, req)
+	info.Latency = time.Since(start)
+	info.Mocked = resp != nil
+
+	var simulated *SimulatedError
+	if errors.As(err, &simulated) {
+		info.Mocked = true
+		return nil, info, simulated.Err
+	}
+	if err != nil && !errors.Is(err, ErrNoMockResponse) {
+		info.Err = err
+	}
+	return resp, info, nil
+}
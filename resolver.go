@@ -3,15 +3,25 @@ package mockhttp
 import (
 	"bytes"
 	"context"
-	"html/template"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 	"sync/atomic"
+	"text/template"
+	"time"
 
+	"github.com/William9923/go-mockhttp/jsonschema"
 	"github.com/William9923/go-mockhttp/parser"
 	"github.com/William9923/go-mockhttp/pathregex"
+	"github.com/hashicorp/go-cleanhttp"
 	"gopkg.in/yaml.v2"
 )
 
@@ -28,144 +38,526 @@ type ResolverAdapter interface {
 // File Based Resolver Adapter
 // Use file (.yaml) based mock definition spec to resolve the mock.
 type fileBasedResolver struct {
-	dir         string
-	definitions []fileBasedMockDefinition
-	isLoaded    atomic.Bool
-	template    *template.Template
+	dir      string
+	isLoaded atomic.Bool
+
+	// definitions is swapped as a whole on (re)load, so concurrent Resolve calls always see a consistent set
+	// (along with its router).
+	definitions atomic.Pointer[definitionSet]
+
+	// loadMu serialize LoadDefinition and Reload, so the last read definitions always win.
+	loadMu sync.Mutex
+
+	// callbackClient is the http client used to trigger the mock response callbacks (webhook).
+	callbackClient *http.Client
+
+	// callbackCtx is cancelled to abort the pending callbacks (see DrainCallbacks),
+	// callbacks track the running callbacks goroutines.
+	callbackCtx     context.Context
+	cancelCallbacks context.CancelFunc
+	callbacks       sync.WaitGroup
+
+	// ruleFunctions is the custom functions registered into the rule environment.
+	ruleFunctions map[string]interface{}
+
+	// maxRecordingAge is the age after which the recorded response is stale, 0 means never stale.
+	maxRecordingAge time.Duration
+
+	// delayPolicy decide how the mock delay behave when it exceeds the request context deadline.
+	delayPolicy DelayPolicy
+
+	// maxBodySize is the max number of request body bytes buffered for matching, 0 means unlimited.
+	maxBodySize int64
+
+	// bodyLimitPolicy decide how the request body exceeding maxBodySize is matched.
+	bodyLimitPolicy BodyLimitPolicy
+
+	// contentTypePolicy decide the Content-Type of the mock response without Content-Type header,
+	// contentType is the fixed content type of ContentTypeFixed policy.
+	contentTypePolicy ContentTypePolicy
+	contentType       string
+
+	// defaultHeaders are added to every generated mock response, unless defined by the response itself.
+	// defaultHeaderTemplates are the parsed default header values with Go text/template.
+	defaultHeaders         map[string]string
+	defaultHeaderTemplates map[string]*template.Template
+
+	// matchTraceHandler is called with the matching trace of every Resolve call, nil when tracing is disabled.
+	matchTraceHandler MatchTraceHandler
+
+	// eventListeners receive the definition loaded, rule error and callback error events.
+	eventListeners []EventListener
+
+	// ruleErrorHandler is called for every rule that failed to be evaluated.
+	ruleErrorHandler RuleErrorHandler
+
+	// lazyLoad only index the definition files on (re)load, the whole definition is parsed on the first matching request.
+	lazyLoad bool
+
+	// partialLoad skip the invalid definition files on (re)load, instead of aborting the whole load.
+	partialLoad bool
+
+	// loadConcurrency is the max number of definition files read and parsed concurrently on (re)load.
+	loadConcurrency int
+
+	// strictRules makes Resolve fail on rule evaluation error, instead of treating it as unfulfilled rule.
+	strictRules bool
+
+	// clock returns the current time exposed to the rules.
+	clock func() time.Time
+
+	// ruleEngine compile and evaluate the response rules.
+	ruleEngine RuleEngine
+
+	// state is the key-value store shared across rules, templates and responses.
+	state *stateStore
 }
 
 // NewFileResolverAdapter returns new ResolverAdapter for Mock client,
 // with file based mock definition.
 //
 // param: dir (string) -> directory path where all the mock definition specs located.
-func NewFileResolverAdapter(dir string) (ResolverAdapter, error) {
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
+// param: opts (FileResolverOption) -> optional behavior of the resolver.
+func NewFileResolverAdapter(dir string, opts ...FileResolverOption) (ResolverAdapter, error) {
+	if _, err := os.Stat(dir); dir != "" && os.IsNotExist(err) {
 		return nil, err
 	}
-	return &fileBasedResolver{
-		dir:         dir,
-		definitions: []fileBasedMockDefinition{},
-		template:    template.New("mock-svc"),
-	}, nil
+	state := newStateStore()
+	callbackClient := cleanhttp.DefaultPooledClient()
+	callbackClient.Timeout = defaultCallbackTimeout
+	callbackCtx, cancelCallbacks := context.WithCancel(context.Background())
+	resolver := &fileBasedResolver{
+		dir: dir,
+
+		callbackClient:  callbackClient,
+		callbackCtx:     callbackCtx,
+		cancelCallbacks: cancelCallbacks,
+		ruleFunctions:   make(map[string]interface{}),
+		clock:           time.Now,
+		ruleEngine:      NewExprRuleEngine(),
+		state:           state,
+
+		loadConcurrency: runtime.GOMAXPROCS(0),
+	}
+	for _, opt := range opts {
+		opt(resolver)
+	}
+	if err := resolver.parseDefaultHeaders(); err != nil {
+		return nil, err
+	}
+	return resolver, nil
+}
+
+// NewMemoryResolverAdapter returns new ResolverAdapter for Mock client, without any mock definition file.
+// The definitions are registered in code (see NewStub, DefinitionAdder and StubManager),
+// and LoadDefinition (or Reload) register no definition.
+//
+// Panics when the options are invalid (ex: malformed default header template, see WithDefaultHeaders).
+//
+// param: opts (FileResolverOption) -> optional behavior of the resolver.
+func NewMemoryResolverAdapter(opts ...FileResolverOption) ResolverAdapter {
+	resolver, err := NewFileResolverAdapter("", opts...)
+	if err != nil {
+		panic(fmt.Errorf("mockhttp: NewMemoryResolverAdapter: %w", err))
+	}
+	return resolver
 }
 
 // fileBasedResolver LoadDefinition use dir field to search all the mock definition specs file (.yaml)
 // and register the definitions into the adapter resolver.
 //
-// Also, compile all deferred field from the definitions file spec
+// Also, compile all deferred field from the definitions file spec (including the response rules)
+//
+// On partial load mode (WithPartialLoad), the valid definitions are registered even when LoadErrors is returned.
 func (r *fileBasedResolver) LoadDefinition(ctx context.Context) error {
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+
 	if r.isLoaded.Load() {
 		return ErrDefinitionLoaded
 	}
 
-	fileItems, err := os.ReadDir(r.dir)
+	definitions, err := r.readDefinitions()
+	if definitions == nil {
+		return err
+	}
+	r.setDefinitions(definitions)
+
+	r.isLoaded.Store(true)
+	return err
+}
+
+// Resetter is implemented by resolver adapters that can unload the mock definitions,
+// so a single resolver instance can be reused across tests that need different mock sets.
+//
+// The built-in file based resolver implements Resetter:
+//
+//	resolver.(mockhttp.Resetter).Reset()
+type Resetter interface {
+	Reset()
+}
+
+// fileBasedResolver Reset
+// Unregister all the loaded definitions and clear the state store, so LoadDefinition can be called again
+// (ex: after the definition files are replaced). Requests are not mocked until the definitions are loaded again.
+func (r *fileBasedResolver) Reset() {
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+
+	r.definitions.Store(nil)
+	r.state.reset()
+	r.isLoaded.Store(false)
+}
+
+// DefinitionAdder is implemented by resolver adapters that can register mock definition at runtime,
+// without writing it into the source (ex: to override a definition for a single test).
+//
+// The built-in file based resolver implements DefinitionAdder:
+//
+//	err := resolver.(mockhttp.DefinitionAdder).AddDefinition(ctx, []byte(spec))
+type DefinitionAdder interface {
+	AddDefinition(ctx context.Context, spec []byte) error
+}
+
+// fileBasedResolver AddDefinition
+// Parse the mock definition spec (.yaml) and register it before the loaded definitions,
+// so it takes priority over the definition with the same method, host and path.
+//
+// The added definition is kept until the definitions are reloaded, reset or restored (see Snapshotter).
+func (r *fileBasedResolver) AddDefinition(ctx context.Context, spec []byte) error {
+	definition, err := r.parseDefinition(spec)
 	if err != nil {
 		return err
 	}
 
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+
+	definitions := append(mockDefinitions{definition}, r.loadedDefinitions()...)
+	r.setDefinitions(definitions)
+	r.isLoaded.Store(true)
+	return nil
+}
+
+// fileBasedResolver readDefinitions
+// Read and compile all the mock definition specs file (.yaml) in the dir,
+// without touching the registered definitions.
+//
+// Each failing file is reported as DefinitionFileError. On partial load mode, the failing files are skipped
+// and reported together as LoadErrors, along with the successfully read definitions.
+func (r *fileBasedResolver) readDefinitions() (mockDefinitions, error) {
+	// in-memory resolver, see NewMemoryResolverAdapter
+	if r.dir == "" {
+		return mockDefinitions{}, nil
+	}
+
+	fileItems, err := os.ReadDir(r.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
 	for _, item := range fileItems {
-		if item.IsDir() {
+		if item.IsDir() || !isDefinitionFile(item.Name()) {
 			continue
 		}
+		names = append(names, item.Name())
+	}
 
-		f, err := os.ReadFile(filepath.Join(r.dir, item.Name()))
-		if err != nil {
-			return err
+	read := r.readDefinition
+	if r.lazyLoad {
+		read = r.indexDefinition
+	}
+
+	// read the files concurrently, the definitions are still registered (and errors reported) in the file order
+	readDefinitions := make([]fileBasedMockDefinition, len(names))
+	readErrs := make([]error, len(names))
+	r.forEachConcurrently(len(names), func(idx int) bool {
+		readDefinitions[idx], readErrs[idx] = read(names[idx])
+		// without partial load mode, the files after the failing file are not needed anymore
+		return readErrs[idx] == nil || r.partialLoad
+	})
+
+	definitions := mockDefinitions{}
+	var loadErrs LoadErrors
+	for idx, name := range names {
+		if err := readErrs[idx]; err != nil {
+			fileErr := &DefinitionFileError{File: name, Err: err}
+			if !r.partialLoad {
+				return nil, fileErr
+			}
+			loadErrs = append(loadErrs, fileErr)
+			continue
+		}
+		definitions = append(definitions, readDefinitions[idx])
+	}
+
+	if len(loadErrs) > 0 {
+		return definitions, loadErrs
+	}
+	return definitions, nil
+}
+
+// fileBasedResolver forEachConcurrently call fn for every index of [0, n), with up to loadConcurrency concurrent calls.
+// The indexes are dispatched in order, and no more index is dispatched once any fn returns false,
+// so every index before the one returning false is always processed.
+func (r *fileBasedResolver) forEachConcurrently(n int, fn func(idx int) bool) {
+	workers := r.loadConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	var (
+		wg      sync.WaitGroup
+		next    atomic.Int64
+		stopped atomic.Bool
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !stopped.Load() {
+				idx := int(next.Add(1) - 1)
+				if idx >= n {
+					return
+				}
+				if !fn(idx) {
+					stopped.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// fileBasedResolver readDefinition read a single mock definition spec file,
+// and compile all deferred field (including the response rules).
+func (r *fileBasedResolver) readDefinition(name string) (fileBasedMockDefinition, error) {
+	var definition fileBasedMockDefinition
+	if err := decodeYAMLFile(filepath.Join(r.dir, name), &definition); err != nil {
+		return definition, err
+	}
+
+	definition, err := r.compileDefinition(definition)
+	definition.file = name
+	return definition, err
+}
+
+// decodeYAMLFile decode the YAML file into out, streaming the file to the decoder
+// instead of reading the whole (possibly multi-megabyte recorded) file into memory first.
+// Empty file is decoded as zero value, the same as yaml.Unmarshal.
+func decodeYAMLFile(path string, out interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := yaml.NewDecoder(f).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// fileBasedResolver parseDefinition parse a single mock definition spec (.yaml),
+// and compile all deferred field (including the response rules).
+func (r *fileBasedResolver) parseDefinition(spec []byte) (fileBasedMockDefinition, error) {
+	var definition fileBasedMockDefinition
+
+	err := yaml.Unmarshal(spec, &definition)
+	if err != nil {
+		return definition, err
+	}
+	return r.compileDefinition(definition)
+}
+
+// fileBasedResolver compileDefinition validate and compile all deferred field of the decoded definition
+// (including the path pattern, response rules and schema files).
+func (r *fileBasedResolver) compileDefinition(definition fileBasedMockDefinition) (fileBasedMockDefinition, error) {
+	definition.compilePath()
+
+	if !in[string](definition.Strategy, []string{"", strategyFirst, strategyRoundRobin, strategyRandom}) {
+		return definition, fmt.Errorf("%w: %q", ErrUnknownStrategy, definition.Strategy)
+	}
+	if !in[string](definition.Replay, []string{"", replayAlways, replayOnce}) {
+		return definition, fmt.Errorf("%w: %q", ErrUnknownReplay, definition.Replay)
+	}
+
+	for idx, response := range definition.Responses {
+		definition.Responses[idx].useCounter = new(atomic.Uint64)
+		if definition.Replay == replayOnce && response.MaxUses == 0 {
+			definition.Responses[idx].MaxUses = 1
 		}
 
-		var definition fileBasedMockDefinition
-		err = yaml.Unmarshal(f, &definition)
+		if err := r.compileRules(response.Rules); err != nil {
+			var ruleErr *RuleError
+			if errors.As(err, &ruleErr) {
+				ruleErr.Definition = definition.name()
+			}
+			return definition, err
+		}
+
+		if response.EnableTemplate && response.Body != "" {
+			bodyTemplate, err := r.parseTemplate("mock-body", response.Body)
+			if err != nil {
+				return definition, fmt.Errorf("%w: response #%d body template: %v", ErrInvalidDefinition, idx, err)
+			}
+			definition.Responses[idx].bodyTemplate = bodyTemplate
+		}
+
+		if response.SchemaFile == "" {
+			continue
+		}
+		schema, err := r.loadSchema(response.SchemaFile)
 		if err != nil {
-			return err
+			return definition, err
 		}
+		definition.Responses[idx].schema = schema
+	}
+	return definition, nil
+}
 
-		compiledRegex, params := pathregex.CompilePath(definition.Path, true, true)
-		definition.compiledPath = compiledRegex.String()
-		definition.params = params
-		definition.containParams = len(params) > 0
-		definition.containsWildcard = findWildcard(params)
+// fileBasedResolver setDefinitions atomically replace all the registered definitions,
+// and emit the definition loaded event.
+func (r *fileBasedResolver) setDefinitions(definitions mockDefinitions) {
+	r.definitions.Store(newDefinitionSet(definitions))
+	r.emit(Event{Type: EventDefinitionLoaded, Definitions: len(definitions)})
+}
+
+// fileBasedResolver loadedDefinitions returns all the registered definitions.
+// The returned definitions must not be modified, as it is shared with the concurrent Resolve calls.
+func (r *fileBasedResolver) loadedDefinitions() mockDefinitions {
+	return r.loadedSet().definitions
+}
 
-		r.definitions = append(r.definitions, definition)
+// fileBasedResolver loadedSet returns the registered definitions along with their router and index,
+// empty set when no definition is registered.
+func (r *fileBasedResolver) loadedSet() *definitionSet {
+	if set := r.definitions.Load(); set != nil {
+		return set
 	}
+	return emptyDefinitionSet
+}
 
-	r.isLoaded.Store(true)
-	return nil
+// fileBasedResolver loadSchema read and parse JSON Schema file.
+// Relative path is resolved from the mock definition directory.
+func (r *fileBasedResolver) loadSchema(path string) (*jsonschema.Schema, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.dir, path)
+	}
+
+	f, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema.Parse(f)
 }
 
 // fileBasedResolver Resolve receive req object and
 // find possible mock response from loaded mock definitions spec file (.yaml)
 //
 // Resolve process (file based) include these steps:
-//  1. Extract request headers (and request body if it was PUT,PATCH,POST,DELETE)
+//  1. Extract request headers, cookies and query params
 //  2. Build incoming request data object
 //  3. Find mock response via loaded mock definitions. The priorities of the mock definitions as below:
 //     Exact path (ex: /var/william -> /var/william)
 //     With path parameters (ex: /var/:name -> /var/william)
 //     With wildcard (ex: /var/* -> /var/william)
 //  4. Return nil with ErrNoMockResponse when no mock definitions found
-//  5. Find the correct response defined in mock definitions (based on CEL rules).
+//  5. Extract the request body, only if the responses of the matched definition have rules
+//  6. Find the correct response defined in mock definitions (based on CEL rules).
 //     Mock responses with rules will always be prioritized before mock responses with no rules (default)
-//  6. Generate mock response body (support templating via Go text/template)
+//  7. Generate mock response body (support templating via Go text/template)
+//  8. Store the mock response `set_state` values into the state store, if any
+//  9. Trigger the mock response callbacks (webhook) asynchronously, if any
 //
-// WARN: req body must be using reuseable reader, as it will be read multiple time during extract request process
-func (r *fileBasedResolver) Resolve(ctx context.Context, req *Request) (*http.Response, error) {
-
-	var (
-		err     error
-		body    map[string]interface{}
-		rawBody string
-	)
-
-	headers := extractHeader(req)
+// The request body is read once (only when needed), the raw body and the parsed body are derived from the same bytes.
+func (r *fileBasedResolver) Resolve(ctx context.Context, req *Request) (resp *http.Response, err error) {
 
-	if req.Body != nil {
-		rawBody, err = extractRawBody(req)
-		if err != nil {
-			return nil, err
-		}
-		body, err = extractReqBody(req, headers)
-		if err != nil {
-			return nil, err
-		}
+	request, err := buildIncomingRequest(req)
+	if err != nil {
+		return nil, err
 	}
 
-	request := incomingRequest{
-		Host:        req.Host,
-		Method:      req.Method,
-		Endpoint:    pathregex.CleanPath(req.URL.EscapedPath()),
-		Headers:     headers,
-		Cookies:     extractCookies(req),
-		QueryParams: extractQueryParam(req),
-		Body:        body,
-		RawBody:     rawBody,
+	var trace *MatchTrace
+	if r.matchTraceHandler != nil {
+		trace = &MatchTrace{Method: request.Method, Host: request.Host, Path: request.Endpoint}
+		defer func() {
+			trace.Err = err
+			r.matchTraceHandler(*trace)
+		}()
 	}
 
-	mockResp, err := r.findMockResponse(&request, []mockDefinitionsStore{
-		r.getAllExactPathDefinitions,
-		r.getAllContainPathParamDefinitions,
-		r.getAllHaveWildcardDefinitions,
-	})
+	mockResp, err := r.findMockResponse(request, trace)
+	if request.Definition != "" {
+		SetMatchedDefinition(ctx, request.Definition)
+	}
+	if request.Response >= 0 {
+		SetMatchedResponse(ctx, request.Response)
+	}
+	reportRuleErrors(ctx, request.RuleErrors)
 	if err != nil {
 		return nil, err
 	}
 	if mockResp == nil {
 		return nil, ErrNoMockResponse
 	}
+	return r.serveResponse(ctx, req, request, mockResp)
+}
 
-	return r.generateResp(&request, mockResp)
+// fileBasedResolver serveResponse
+// Generate the selected mock response, simulate its delay (or timeout),
+// then apply its `set_state` values and trigger its callbacks.
+func (r *fileBasedResolver) serveResponse(ctx context.Context, req *Request, request *incomingRequest, mockResp *mockResponse) (*http.Response, error) {
+	if mockResp.Timeout {
+		// Simulate the upstream never respond within the delay, the client fail with timeout error.
+		if err := r.delay(ctx, time.Duration(mockResp.Delay)*time.Millisecond); err != nil {
+			return nil, err
+		}
+		return nil, newSimulatedTimeout(req)
+	}
+
+	resp, err := r.generateResp(request, mockResp)
+	if err != nil {
+		return nil, err
+	}
+	resp.Request = req.Request
+
+	// Simulate the response latency, cancelled request return immediately without side effect (state / callbacks).
+	if err := r.delay(ctx, time.Duration(mockResp.Delay)*time.Millisecond); err != nil {
+		return nil, err
+	}
+
+	if err := r.applyState(request, mockResp); err != nil {
+		return nil, err
+	}
+	r.fireCallbacks(request, mockResp)
+	return resp, nil
 }
 
-func (r *fileBasedResolver) findMockResponse(request *incomingRequest, definitionsFn []mockDefinitionsStore) (*mockResponse, error) {
-	for _, fn := range definitionsFn {
+// fileBasedResolver findMockResponse
+// Find the definition matching the request host, http method and path, and select its mock response.
+//
+// The definition is looked up with the router, unless the match is traced: then every candidate definition
+// of the definition stores is evaluated in order, so the trace reports all of them.
+func (r *fileBasedResolver) findMockResponse(request *incomingRequest, trace *MatchTrace) (*mockResponse, error) {
+	if trace == nil {
+		definition, found := r.loadedSet().router.lookup(request.Host, request.Method, request.Endpoint)
+		if !found {
+			return nil, ErrNoMockResponse
+		}
+		return r.useDefinition(request, definition, nil)
+	}
+
+	for idx, fn := range r.definitionStores() {
 		for _, definition := range fn(request.Host, request.Method) {
-			if isMatch := pathregex.MatchPath(request.Endpoint, definition.Path); isMatch {
-				params := pathregex.ExtractPathParam(request.Endpoint, definition.Path)
-				request.RouteParams = params
-				resp, err := r.findResponse(request, definition)
-				if err != nil {
-					return nil, err
-				}
-				return resp, nil
+			isMatch := definition.pattern().Match(request.Endpoint)
+			trace.traceCandidate(idx, definition, isMatch)
+			if isMatch {
+				return r.useDefinition(request, definition, trace)
 			}
 		}
 	}
@@ -173,21 +565,75 @@ func (r *fileBasedResolver) findMockResponse(request *incomingRequest, definitio
 	return nil, ErrNoMockResponse
 }
 
+// fileBasedResolver useDefinition
+// Select the mock response of the definition matching the request path, and count the definition call.
+func (r *fileBasedResolver) useDefinition(request *incomingRequest, definition fileBasedMockDefinition, trace *MatchTrace) (*mockResponse, error) {
+	definition, err := r.parseLazy(definition)
+	if err != nil {
+		return nil, err
+	}
+	if definition.needsBody() {
+		if err := request.loadBody(r.maxBodySize, r.bodyLimitPolicy); err != nil {
+			return nil, err
+		}
+	}
+	params := definition.pattern().Params(request.Endpoint)
+	request.RouteParams = params
+	request.CallCount = int(definition.callCounter.Add(1))
+	request.Definition = definition.name()
+	if trace != nil {
+		trace.Definition = request.Definition
+		if r.validateTarget(request) == nil {
+			trace.Responses = r.explainResponses(request, definition)
+		}
+	}
+	resp, err := r.findResponse(request, definition)
+	if err != nil {
+		return nil, err
+	}
+	for idx := range definition.Responses {
+		if resp != nil && definition.Responses[idx].useCounter == resp.useCounter {
+			request.Response = idx
+		}
+	}
+	if trace != nil {
+		for idx := range trace.Responses {
+			trace.Responses[idx].Selected = idx == request.Response
+		}
+	}
+	return resp, nil
+}
+
 // fileBasedResolver generateResp
 // Generate http.Response object based on defined response from mock definition.
 //
-// Support templating via Go text/template if `enabled_template` is true
+// Support templating via Go text/template if `enabled_template` is true (the template is parsed once, on compile)
+// Support generating the body from JSON Schema if `response_schema` is defined without `response_body`
+// Support conditional request (304 Not Modified) if `etag` / `last_modified` is defined
+// The Content-Type (when not defined) is decided by the resolver content type policy (see WithContentTypeFallback)
 // The template will be filled with all parameters from request (cookies, headers, path param and query params)
 func (r *fileBasedResolver) generateResp(request *incomingRequest, response *mockResponse) (*http.Response, error) {
 	headers := response.ResponseHeaders
 	statusCode := response.StatusCode
 	body := response.Body
 
-	if response.EnableTemplate {
-		buf := new(bytes.Buffer)
+	if body == "" && response.schema != nil {
+		generated, err := response.schema.Generate()
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := json.Marshal(generated)
+		if err != nil {
+			return nil, err
+		}
+		body = string(encoded)
+	}
+
+	if response.bodyTemplate != nil {
+		buf := getBuffer()
+		defer putBuffer(buf)
 
-		t := template.Must(r.template.Parse(body))
-		if err := t.Execute(buf, request.collectAllParams()); err != nil {
+		if err := response.bodyTemplate.Execute(buf, request.collectAllParams()); err != nil {
 			return nil, ErrCommon
 		}
 		body = buf.String()
@@ -196,72 +642,142 @@ func (r *fileBasedResolver) generateResp(request *incomingRequest, response *moc
 	actualHeaders := make(http.Header)
 	isContentTypeSet := false
 	for name, value := range headers {
-		if name == "Content-Type" {
+		if http.CanonicalHeaderKey(name) == "Content-Type" {
 			isContentTypeSet = true
 		}
-		actualHeaders[name] = []string{value}
+		// canonical key, so the response headers written in any case take precedence over the default headers
+		actualHeaders.Set(name, value)
+	}
+	// the default headers (including Content-Type) take precedence over the content type fallback
+	if err := r.applyDefaultHeaders(request, actualHeaders); err != nil {
+		return nil, err
+	}
+	if actualHeaders.Get("Content-Type") != "" {
+		isContentTypeSet = true
+	}
+	if !isContentTypeSet && response.schema != nil {
+		isContentTypeSet = true
+		actualHeaders["Content-Type"] = []string{"application/json"}
 	}
 	if !isContentTypeSet {
-		contentType := http.DetectContentType([]byte(body))
-		actualHeaders["Content-Type"] = []string{contentType}
+		if contentType := r.fallbackContentType(body); contentType != "" {
+			actualHeaders["Content-Type"] = []string{contentType}
+		}
+	}
+
+	if response.ETag != "" {
+		actualHeaders.Set("ETag", response.ETag)
+	}
+	if response.LastModified != "" {
+		actualHeaders.Set("Last-Modified", response.LastModified)
+	}
+	if isNotModified(request, response) {
+		statusCode = http.StatusNotModified
+		body = ""
+		actualHeaders.Del("Content-Type")
 	}
 
 	return &http.Response{
-		Body:       io.NopCloser(bytes.NewBufferString(body)),
-		StatusCode: statusCode,
-		Header:     actualHeaders,
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        actualHeaders,
+		Body:          io.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
 	}, nil
 }
 
+// fileBasedResolver applyDefaultHeaders
+// Add the default headers (see WithDefaultHeaders) not defined by the mock response itself.
+// The templated values (parsed on construction, see parseDefaultHeaders) are rendered with all parameters from request.
+func (r *fileBasedResolver) applyDefaultHeaders(request *incomingRequest, headers http.Header) error {
+	var data params
+	for name, value := range r.defaultHeaders {
+		if headers.Get(name) != "" {
+			continue
+		}
+		if t, ok := r.defaultHeaderTemplates[name]; ok {
+			if data == nil {
+				data = request.collectAllParams()
+			}
+			buf := getBuffer()
+			err := t.Execute(buf, data)
+			value = buf.String()
+			putBuffer(buf)
+			if err != nil {
+				return err
+			}
+		}
+		headers.Set(name, value)
+	}
+	return nil
+}
+
+// fileBasedResolver parseDefaultHeaders
+// Parse the default header values with Go text/template once, so the malformed template is reported on construction.
+func (r *fileBasedResolver) parseDefaultHeaders() error {
+	for name, value := range r.defaultHeaders {
+		if !strings.Contains(value, "{{") {
+			continue
+		}
+		t, err := r.parseTemplate(name, value)
+		if err != nil {
+			return fmt.Errorf("default header %s: %w", name, err)
+		}
+		if r.defaultHeaderTemplates == nil {
+			r.defaultHeaderTemplates = make(map[string]*template.Template)
+		}
+		r.defaultHeaderTemplates[name] = t
+	}
+	return nil
+}
+
+// fileBasedResolver applyState
+// Write all `set_state` values of the served mock response into the state store.
+// The values are rendered using Go text/template, filled with all parameters from request.
+func (r *fileBasedResolver) applyState(request *incomingRequest, response *mockResponse) error {
+	if len(response.SetState) == 0 {
+		return nil
+	}
+
+	data := request.collectAllParams()
+	for key, value := range response.SetState {
+		rendered, err := r.renderTemplate(value, data)
+		if err != nil {
+			return err
+		}
+		r.state.set(key, rendered)
+	}
+	return nil
+}
+
 // --- Repository-like (datastore) function to get definition based on condition ---
 type mockDefinitionsStore func(host, method string) []fileBasedMockDefinition
 
-// fileBasedResolver getAllContainPathParamDefinitions
-// Fetch all mock definitions that contain path param
-// based on request Host and http method.
-//
-// ex:
-// /v1/api/mock/:id => true (contain path param)
-// /v1/api/mock/1   => false (exact path)
-// /v1/api/mock/*   => false (have wildcard)
-func (r *fileBasedResolver) getAllContainPathParamDefinitions(host, method string) []fileBasedMockDefinition {
-	var dataToQuery = r.definitions
-	dataToQuery = filter[fileBasedMockDefinition](dataToQuery, func(definition fileBasedMockDefinition) bool {
-		return definition.Method == method && definition.containParams && !definition.containsWildcard
-	})
-	return dataToQuery
-}
+// mockDefinitions is the set of definitions registered at once (on load / reload).
+type mockDefinitions []fileBasedMockDefinition
 
-// fileBasedResolver getAllExactPathDefinitions
-// Fetch all mock definitions with exact path
-// based on request Host and http method.
+// fileBasedResolver definitionStores
+// Return all the definition stores, ordered by the matching priorities:
+// exact path, with path parameters and with wildcard.
 //
-// ex:
-// /v1/api/mock/:id => false (contain path param)
-// /v1/api/mock/1   => true (exact path)
-// /v1/api/mock/*   => false (have wildcard)
-func (r *fileBasedResolver) getAllExactPathDefinitions(host, method string) []fileBasedMockDefinition {
-	var dataToQuery = r.definitions
-	dataToQuery = filter[fileBasedMockDefinition](dataToQuery, func(definition fileBasedMockDefinition) bool {
-		return definition.Method == method && definition.Host == host && !definition.containParams && !definition.containsWildcard
-	})
-	return dataToQuery
+// All the stores query the same definitions, even when the definitions are reloaded in between.
+func (r *fileBasedResolver) definitionStores() []mockDefinitionsStore {
+	set := r.loadedSet()
+	return []mockDefinitionsStore{
+		set.getAllExactPathDefinitions,
+		set.getAllContainPathParamDefinitions,
+		set.getAllHaveWildcardDefinitions,
+	}
 }
 
-// fileBasedResolver getAllHaveWildcardDefinitions
-// Fetch all mock definitions that have wildcard
-// based on request Host and http method.
-//
-// ex:
-// /v1/api/mock/:id => false (contain path param)
-// /v1/api/mock/1   => false (exact path)
-// /v1/api/mock/*   => true (have wildcard)
-func (r *fileBasedResolver) getAllHaveWildcardDefinitions(host, method string) []fileBasedMockDefinition {
-	var dataToQuery = r.definitions
-	dataToQuery = filter[fileBasedMockDefinition](dataToQuery, func(definition fileBasedMockDefinition) bool {
-		return definition.Method == method && definition.Host == host && definition.containParams && definition.containsWildcard
-	})
-	return dataToQuery
+// isDefinitionFile check whether the file is a mock definition spec file (.yaml / .yml),
+// so supporting files (ex: JSON Schema) can be placed in the same directory.
+func isDefinitionFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yaml" || ext == ".yml"
 }
 
 func findWildcard(params []string) bool {
@@ -274,6 +790,55 @@ func findWildcard(params []string) bool {
 }
 
 // --- Utility for extracting info from HTTP request ---
+
+// buildIncomingRequest extract all the information needed for matching from the request.
+// The request body is not read yet, see incomingRequest.loadBody.
+func buildIncomingRequest(req *Request) (*incomingRequest, error) {
+	return &incomingRequest{
+		Host:        req.Host,
+		Method:      req.Method,
+		Endpoint:    pathregex.CleanPath(req.URL.EscapedPath()),
+		RawQuery:    req.URL.RawQuery,
+		URL:         req.URL.String(),
+		Headers:     extractHeader(req),
+		Cookies:     extractCookies(req),
+		QueryParams: extractQueryParam(req),
+		Response:    -1,
+		req:         req,
+	}, nil
+}
+
+// loadBody extract the request body (if it exists), only once. It is deferred until a definition
+// that needs the body matches the request, so requests without matching definition never read their body.
+//
+// At most maxSize bytes of the body are buffered (0 means unlimited), the larger body is handled based on the policy.
+func (req *incomingRequest) loadBody(maxSize int64, policy BodyLimitPolicy) error {
+	if req.bodyLoaded || !req.hasBody() {
+		return nil
+	}
+
+	rawBody, err := extractRawBody(req.req, maxSize)
+	if errors.Is(err, ErrBodyTooLarge) && policy == BodyLimitIgnore {
+		req.RawBody, req.Body, req.bodyLoaded = "", nil, true
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	body, err := extractReqBody(req.req, req.Headers, rawBody)
+	if err != nil {
+		return err
+	}
+	req.RawBody, req.Body, req.bodyLoaded = rawBody, body, true
+	return nil
+}
+
+// hasBody check whether the request has a body to extract, bodyless requests (ex: most GET requests)
+// skip the content type check and the body extraction entirely.
+func (req *incomingRequest) hasBody() bool {
+	return req.req != nil && req.req.Body != nil && req.req.Body != http.NoBody
+}
+
 func extractHeader(req *Request) params {
 	headers := make(params)
 	for name, values := range req.Header {
@@ -298,37 +863,60 @@ func extractQueryParam(req *Request) params {
 	return queryParams
 }
 
-func extractRawBody(req *Request) (string, error) {
+// extractRawBody read the whole request body, or fail with ErrBodyTooLarge
+// without buffering more than maxSize + 1 bytes when the body is larger than maxSize (0 means unlimited).
+func extractRawBody(req *Request, maxSize int64) (string, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
 	// Read the request body
-	body, err := io.ReadAll(req.Body)
-	if err != nil {
+	var body io.Reader = req.Body
+	if maxSize > 0 {
+		body = io.LimitReader(req.Body, maxSize+1)
+	}
+	if _, err := buf.ReadFrom(body); err != nil {
 		return "", err
 	}
+	if maxSize > 0 && int64(buf.Len()) > maxSize {
+		return "", &BodyTooLargeError{Limit: maxSize}
+	}
 
-	// Convert the body to a string
-	bodyString := string(body)
-	return bodyString, nil
+	// Convert the body to a string (copied, so the buffer can be reused)
+	return buf.String(), nil
 }
 
-func extractFormReqBody(req *Request) (map[string]interface{}, error) {
-	data := make(map[string]interface{})
-	err := req.ParseForm()
-	if err != nil {
-		return data, err
+// extractFormReqBody parse the url-encoded body (and the URL query), the same way as http.Request.ParseForm,
+// but from the raw body that had been read, instead of reading the request body again.
+func extractFormReqBody(req *Request, contentType, rawBody string) (map[string]interface{}, error) {
+	form := make(url.Values)
+
+	var err error
+	// only url-encoded body of POST, PUT and PATCH request is parsed, the same as http.Request.ParseForm
+	if contentType == "application/x-www-form-urlencoded" && in[string](req.Method, []string{http.MethodPost, http.MethodPut, http.MethodPatch}) {
+		form, err = url.ParseQuery(rawBody)
+	}
+	query, queryErr := url.ParseQuery(req.URL.RawQuery)
+	if err == nil {
+		err = queryErr
+	}
+	for name, values := range query {
+		form[name] = append(form[name], values...)
 	}
 
-	for name, values := range req.Form {
+	data := make(map[string]interface{})
+	for name, values := range form {
 		data[name] = values[len(values)-1]
 	}
 
-	return data, nil
+	return data, err
 }
 
-func extractReqBody(req *Request, headers params) (map[string]interface{}, error) {
+// extractReqBody parse the raw body (read once by extractRawBody), based on the request content type.
+func extractReqBody(req *Request, headers params, rawBody string) (map[string]interface{}, error) {
 
 	contentType, exist := headers["Content-Type"]
 	if !exist {
-		return make(map[string]interface{}), ErrUnsupportedContentType
+		return make(map[string]interface{}), &ContentTypeError{Err: ErrUnsupportedContentType}
 	}
 
 	checker := func(supportedContentType string) bool {
@@ -336,13 +924,9 @@ func extractReqBody(req *Request, headers params) (map[string]interface{}, error
 	}
 
 	if some(parsedFormBodyMimeTypes, checker) {
-		return extractFormReqBody(req)
+		return extractFormReqBody(req, contentType, rawBody)
 	}
 
-	rawBody, err := extractRawBody(req)
-	if err != nil {
-		return make(map[string]interface{}), err
-	}
 	if some(parsedJSONBodyMimeTypes, checker) {
 		return parser.ParseJSON(rawBody)
 	}
@@ -351,5 +935,5 @@ func extractReqBody(req *Request, headers params) (map[string]interface{}, error
 		return parser.ParseXML(rawBody)
 	}
 
-	return make(map[string]interface{}), ErrUnsupportedContentType
+	return make(map[string]interface{}), &ContentTypeError{ContentType: contentType, Err: ErrUnsupportedContentType}
 }
@@ -0,0 +1,167 @@
+package mockhttp
+
+import (
+	"net/http"
+	"time"
+)
+
+// FileResolverOption configure optional behavior of the file based resolver adapter.
+type FileResolverOption func(*fileBasedResolver)
+
+// WithRuleFunction register custom Go function into the rule environment,
+// so it can be called from any rules in the mock definitions.
+//
+// ex:
+//
+//	WithRuleFunction("hasPrefix", strings.HasPrefix)
+//	rules:
+//	  - hasPrefix(headers["X-Sign"], "v1=")
+//
+// The function will shadow any built-in variables or functions with the same name.
+func WithRuleFunction(name string, fn interface{}) FileResolverOption {
+	return func(r *fileBasedResolver) {
+		r.ruleFunctions[name] = fn
+	}
+}
+
+// RuleErrorHandler is called when a rule failed to be evaluated against the incoming request
+// (ex: comparing string with number), with the rule and the evaluation error.
+type RuleErrorHandler func(rule string, err error)
+
+// WithRuleErrorHandler register handler that is called for every rule evaluation error,
+// so broken rules can be observed (ex: logged) instead of silently evaluated as false.
+func WithRuleErrorHandler(fn RuleErrorHandler) FileResolverOption {
+	return func(r *fileBasedResolver) {
+		r.ruleErrorHandler = fn
+	}
+}
+
+// WithStrictRules makes Resolve return ErrRuleEvaluation when any rule failed to be evaluated,
+// instead of treating the rule as unfulfilled and falling back to other responses.
+func WithStrictRules() FileResolverOption {
+	return func(r *fileBasedResolver) {
+		r.strictRules = true
+	}
+}
+
+// WithClock override the clock used for the time related rule variables (currentTime, weekday, hour, minute),
+// ex: to simulate business hours in a specific timezone, or to freeze the time in tests.
+func WithClock(clock func() time.Time) FileResolverOption {
+	return func(r *fileBasedResolver) {
+		r.clock = clock
+	}
+}
+
+// WithRuleEngine replace the default rule engine (expr-lang) used to compile and evaluate the response rules,
+// ex: to use CEL, Starlark, or in-house DSL as the rule expression.
+//
+// Implement RuleInspector as well, so the request body is only parsed for the rules referencing it.
+func WithRuleEngine(engine RuleEngine) FileResolverOption {
+	return func(r *fileBasedResolver) {
+		r.ruleEngine = engine
+	}
+}
+
+// WithDelayPolicy set how the mock delay behave when it exceeds the remaining request context deadline
+// (sleep fully, truncate and respond, or fail with context.DeadlineExceeded). The default policy is DelayUntilDeadline.
+func WithDelayPolicy(policy DelayPolicy) FileResolverOption {
+	return func(r *fileBasedResolver) {
+		r.delayPolicy = policy
+	}
+}
+
+// WithMaxBodySize limit the request body buffered for matching to maxSize bytes, so a large upload sent through
+// the mock client can't exhaust the memory. The body larger than maxSize is handled based on the policy
+// (fail with ErrBodyTooLarge, or match as if the request has no body). The body is unlimited by default.
+//
+// Only the body of the request matching a definition with body rule is read (see RuleInspector).
+func WithMaxBodySize(maxSize int64, policy BodyLimitPolicy) FileResolverOption {
+	return func(r *fileBasedResolver) {
+		r.maxBodySize = maxSize
+		r.bodyLimitPolicy = policy
+	}
+}
+
+// WithContentTypeFallback set how the Content-Type of the mock response without Content-Type header is decided
+// (sniff from the body, fixed content type, or none). The contentType is only used by ContentTypeFixed policy:
+//
+//	WithContentTypeFallback(ContentTypeFixed, "application/json")
+//
+// The default policy is ContentTypeSniff.
+func WithContentTypeFallback(policy ContentTypePolicy, contentType string) FileResolverOption {
+	return func(r *fileBasedResolver) {
+		r.contentTypePolicy = policy
+		r.contentType = contentType
+	}
+}
+
+// WithDefaultHeaders add the headers into every generated mock response, ex: X-Mocked: true,
+// so the downstream logging can always tell the mocked traffic from the real one.
+// The header defined by the mock response (response_headers, including Content-Type) takes precedence,
+// while the default Content-Type takes precedence over the content type fallback (see WithContentTypeFallback).
+//
+// The values support Go text/template, filled with all parameters from request (same as the response body),
+// a malformed template is returned as error by NewFileResolverAdapter:
+//
+//	WithDefaultHeaders(map[string]string{
+//		"X-Mocked":         "true",
+//		"X-Correlation-Id": `{{ index . "X-Request-Id" }}`,
+//	})
+func WithDefaultHeaders(headers map[string]string) FileResolverOption {
+	return func(r *fileBasedResolver) {
+		if r.defaultHeaders == nil {
+			r.defaultHeaders = make(map[string]string, len(headers))
+		}
+		for name, value := range headers {
+			r.defaultHeaders[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+}
+
+// WithMaxRecordingAge skip the recorded responses (with recorded_at) older than the max age, as if they are not defined.
+// Combined with record mode (Client.Recorder), the stale responses are re-recorded from the actual upstream service,
+// so long-lived mock catalogs don't silently drift from reality.
+func WithMaxRecordingAge(maxAge time.Duration) FileResolverOption {
+	return func(r *fileBasedResolver) {
+		r.maxRecordingAge = maxAge
+	}
+}
+
+// WithPartialLoad makes LoadDefinition (and Reload) skip the malformed definition files and load the rest,
+// instead of aborting the whole load. The failing files are returned as LoadErrors, listing every file and reason.
+func WithPartialLoad() FileResolverOption {
+	return func(r *fileBasedResolver) {
+		r.partialLoad = true
+	}
+}
+
+// WithLazyLoad makes LoadDefinition (and Reload) only index the host, method and path of the definition files,
+// and parse the whole definition (responses, rules and schema files) on the first matching request,
+// cutting the startup time for directories with thousands of definitions.
+//
+// As the definitions are validated lazily, invalid definition is only reported (as DefinitionFileError)
+// by the Resolve call that match it. Use Validate to check all the definitions up front.
+func WithLazyLoad() FileResolverOption {
+	return func(r *fileBasedResolver) {
+		r.lazyLoad = true
+	}
+}
+
+// WithLoadConcurrency set the max number of definition files read and parsed concurrently by LoadDefinition
+// (and Reload), default to GOMAXPROCS. Set 1 to read the files one by one.
+//
+// The definitions are always registered in the file name order, regardless of the concurrency.
+// Custom RuleEngine (see WithRuleEngine) must support concurrent Compile calls, unless the concurrency is 1.
+func WithLoadConcurrency(n int) FileResolverOption {
+	return func(r *fileBasedResolver) {
+		r.loadConcurrency = n
+	}
+}
+
+// WithEventListener register the listener to receive the resolver events (definition loaded, rule error and callback error).
+// Register the listener into the client (Client.AddEventListener) to also receive the request events.
+func WithEventListener(fn EventListener) FileResolverOption {
+	return func(r *fileBasedResolver) {
+		r.eventListeners = append(r.eventListeners, fn)
+	}
+}
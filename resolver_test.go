@@ -0,0 +1,1328 @@
+package mockhttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestResolver write all the mock definition specs into temporary directory
+// and return the loaded file based resolver.
+func newTestResolver(t *testing.T, definitions ...string) *fileBasedResolver {
+	t.Helper()
+
+	files := make(map[string]string)
+	for idx, definition := range definitions {
+		files[fmt.Sprintf("definition-%d.yaml", idx)] = definition
+	}
+	return newTestResolverWithFiles(t, files)
+}
+
+// newTestResolverWithFiles write all the files (mock definition specs and its supporting files)
+// into temporary directory and return the loaded file based resolver.
+func newTestResolverWithFiles(t *testing.T, files map[string]string, opts ...FileResolverOption) *fileBasedResolver {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("unable to write file: %v", err)
+		}
+	}
+
+	resolver, err := NewFileResolverAdapter(dir, opts...)
+	if err != nil {
+		t.Fatalf("unable to create resolver: %v", err)
+	}
+	if err := resolver.LoadDefinition(context.Background()); err != nil {
+		t.Fatalf("unable to load mock definition: %v", err)
+	}
+	return resolver.(*fileBasedResolver)
+}
+
+// newTestRequest build request with reuseable body, similar to the request passed by Client.Do into the resolver.
+func newTestRequest(t *testing.T, method, url, body string) *Request {
+	t.Helper()
+
+	var bodyReader io.Reader
+	if body != "" {
+		bodyReader = strings.NewReader(body)
+	}
+	httpReq, err := http.NewRequest(method, url, bodyReader)
+	if err != nil {
+		t.Fatalf("unable to create request: %v", err)
+	}
+
+	req, err := FromRequest(httpReq)
+	if err != nil {
+		t.Fatalf("unable to create request: %v", err)
+	}
+	if req.body != nil {
+		reader, err := req.body()
+		if err != nil {
+			t.Fatalf("unable to read request body: %v", err)
+		}
+		req.Body = io.NopCloser(reader)
+	}
+	return req
+}
+
+func TestFileBasedResolver_Callbacks(t *testing.T) {
+	received := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r.Method + " " + r.URL.Path + " " + string(body)
+	}))
+	defer server.Close()
+
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /orders/:id
+method: GET
+responses:
+  - status_code: 202
+    response_body: accepted
+    callbacks:
+      - url: `+server.URL+`/webhook/{{.id}}
+        body: "{{.id}} settled"
+        delay: 10
+        enable_template: true
+`)
+
+	req, err := NewRequest(http.MethodGet, "http://marketplace.com/orders/99", nil)
+	assert.Nil(t, err)
+
+	resp, err := resolver.Resolve(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	select {
+	case got := <-received:
+		assert.Equal(t, "POST /webhook/99 99 settled", got)
+	case <-time.After(2 * time.Second):
+		t.Fatal("callback was never triggered")
+	}
+}
+
+func TestFileBasedResolver_DrainCallbacks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/webhook/rejected" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		id       string
+		delay    int
+		timeout  time.Duration
+		wantErr  error
+		wantSent bool
+	}{
+		{name: "deliver pending callback", id: "1", delay: 50, timeout: 2 * time.Second},
+		{name: "report rejected callback", id: "rejected", delay: 50, timeout: 2 * time.Second, wantSent: true},
+		{name: "abort delayed callback", id: "1", delay: 5000, timeout: 50 * time.Millisecond, wantErr: context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := make(chan Event, 1)
+			resolver := newTestResolverWithFiles(t, map[string]string{"orders.yaml": fmt.Sprintf(`
+host: marketplace.com
+path: /orders/:id
+method: GET
+responses:
+  - status_code: 202
+    callbacks:
+      - url: %s/webhook/{{.id}}
+        delay: %d
+        enable_template: true
+`, server.URL, tt.delay)}, WithEventListener(func(event Event) {
+				if event.Type == EventCallbackError {
+					events <- event
+				}
+			}))
+
+			req := newTestRequest(t, http.MethodGet, "http://marketplace.com/orders/"+tt.id, "")
+			_, err := resolver.Resolve(context.Background(), req)
+			assert.Nil(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			assert.ErrorIs(t, resolver.DrainCallbacks(ctx), tt.wantErr)
+
+			if tt.wantErr == nil && !tt.wantSent {
+				assert.Len(t, events, 0)
+				return
+			}
+			event := <-events
+			assert.Equal(t, "GET marketplace.com/orders/:id", event.Definition)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, event.Err, context.Canceled)
+			}
+		})
+	}
+}
+
+func TestFileBasedResolver_Template(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /orders/:id
+method: GET
+responses:
+  - status_code: 200
+    enable_template: true
+    response_body: '{"id": "{{ .id }}", "status": "{{ state "status" }}"}'
+`)
+	resolver.state.set("status", "paid")
+
+	resolve := func(id string) (string, error) {
+		resp, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, "http://marketplace.com/orders/"+id, ""))
+		if err != nil {
+			return "", err
+		}
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	t.Run("same response served twice", func(t *testing.T) {
+		for _, id := range []string{"1", "2"} {
+			body, err := resolve(id)
+			assert.Nil(t, err)
+			assert.Equal(t, `{"id": "`+id+`", "status": "paid"}`, body)
+		}
+	})
+
+	t.Run("concurrent resolve", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+				body, err := resolve(id)
+				assert.Nil(t, err)
+				assert.Equal(t, `{"id": "`+id+`", "status": "paid"}`, body)
+			}(strconv.Itoa(i))
+		}
+		wg.Wait()
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, err := resolver.parseDefinition([]byte(`
+host: marketplace.com
+path: /orders
+method: GET
+responses:
+  - status_code: 200
+    enable_template: true
+    response_body: '{{ .id '
+`))
+		assert.ErrorIs(t, err, ErrInvalidDefinition)
+	})
+}
+
+func TestFileBasedResolver_ResponseSchema(t *testing.T) {
+	resolver := newTestResolverWithFiles(t, map[string]string{
+		"user.yaml": `
+host: marketplace.com
+path: /users/:id
+method: GET
+responses:
+  - status_code: 200
+    response_schema: user.schema.json
+`,
+		"user.schema.json": `{
+			"type": "object",
+			"properties": {
+				"id": { "type": "integer", "minimum": 1 },
+				"email": { "type": "string", "format": "email" }
+			}
+		}`,
+	})
+
+	req, err := NewRequest(http.MethodGet, "http://marketplace.com/users/1", nil)
+	assert.Nil(t, err)
+
+	resp, err := resolver.Resolve(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"id": 1, "email": "user@example.com"}`, string(body))
+}
+
+func TestFileBasedResolver_ResponseFields(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /check-price
+method: GET
+responses:
+  - status_code: 404
+    response_body: not found
+`)
+
+	req, err := NewRequest(http.MethodGet, "http://marketplace.com/check-price", nil)
+	assert.Nil(t, err)
+
+	resp, err := resolver.Resolve(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, "404 Not Found", resp.Status)
+	assert.Equal(t, "HTTP/1.1", resp.Proto)
+	assert.Equal(t, 1, resp.ProtoMajor)
+	assert.Equal(t, 1, resp.ProtoMinor)
+	assert.Equal(t, int64(len("not found")), resp.ContentLength)
+	assert.Equal(t, req.Request, resp.Request)
+}
+
+func TestFileBasedResolver_DefaultResponseStrategy(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /status
+method: GET
+strategy: round_robin
+responses:
+  - status_code: 200
+  - status_code: 503
+  - status_code: 429
+`)
+
+	var statusCodes []int
+	for i := 0; i < 4; i++ {
+		req, err := NewRequest(http.MethodGet, "http://marketplace.com/status", nil)
+		assert.Nil(t, err)
+
+		resp, err := resolver.Resolve(context.Background(), req)
+		assert.Nil(t, err)
+		statusCodes = append(statusCodes, resp.StatusCode)
+	}
+	assert.Equal(t, []int{200, 503, 429, 200}, statusCodes)
+}
+
+func TestFileBasedResolver_Rules(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /check-price
+method: POST
+responses:
+  - status_code: 200
+  - status_code: 488
+    rules:
+      - body.name == "William"
+      - headers["X-Region"] == "ID"
+`)
+
+	tests := []struct {
+		name       string
+		body       string
+		region     string
+		statusCode int
+	}{
+		{"all rules fulfilled", `{"name": "William"}`, "ID", 488},
+		{"one of the rule is not fulfilled", `{"name": "William"}`, "SG", 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newTestRequest(t, http.MethodPost, "http://marketplace.com/check-price", tt.body)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Region", tt.region)
+
+			resp, err := resolver.Resolve(context.Background(), req)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.statusCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestFileBasedResolver_DeferredBody(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /check-price
+method: POST
+responses:
+  - status_code: 488
+    rules:
+      - body.name == "William"
+  - status_code: 200
+`, `
+host: marketplace.com
+path: /orders
+method: POST
+responses:
+  - status_code: 201
+`, `
+host: marketplace.com
+path: /carts/:id
+method: POST
+responses:
+  - status_code: 409
+    rules:
+      - any_of:
+          - routeParams.id == "1"
+          - headers["X-Cart-Locked"] == "true"
+  - status_code: 200
+`)
+
+	newRequest := func(path string) *Request {
+		req := newTestRequest(t, http.MethodPost, "http://marketplace.com"+path, `{"name": `)
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	// the invalid body is never parsed without matching definition, or when no rule references the body
+	_, err := resolver.Resolve(context.Background(), newRequest("/products"))
+	assert.ErrorIs(t, err, ErrNoMockResponse)
+
+	resp, err := resolver.Resolve(context.Background(), newRequest("/orders"))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// the rules does not reference body / raw
+	resp, err = resolver.Resolve(context.Background(), newRequest("/carts/1"))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	_, err = resolver.Resolve(context.Background(), newRequest("/check-price"))
+	assert.NotNil(t, err)
+	assert.NotErrorIs(t, err, ErrNoMockResponse)
+}
+
+func TestFileBasedResolver_SinglePassBody(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /check-price
+method: POST
+responses:
+  - status_code: 488
+    rules:
+      - body.name == "William"
+      - raw contains "William"
+  - status_code: 200
+`)
+
+	tests := []struct {
+		name        string
+		contentType string
+		url         string
+		body        string
+		statusCode  int
+	}{
+		{"json", "application/json", "http://marketplace.com/check-price", `{"name": "William"}`, 488},
+		{"form", "application/x-www-form-urlencoded", "http://marketplace.com/check-price", "name=William", 488},
+		{"form query takes precedence", "application/x-www-form-urlencoded", "http://marketplace.com/check-price?name=Jane", "name=William", 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the body can only be read once, unlike the reusable body passed by Client.Do
+			httpReq, err := http.NewRequest(http.MethodPost, tt.url, io.NopCloser(strings.NewReader(tt.body)))
+			assert.Nil(t, err)
+			httpReq.Header.Set("Content-Type", tt.contentType)
+
+			resp, err := resolver.Resolve(context.Background(), &Request{Request: httpReq})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.statusCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestFileBasedResolver_BodylessRequest(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /orders/:id/cancel
+method: POST
+responses:
+  - status_code: 409
+    rules:
+      - routeParams.id == "1"
+  - status_code: 200
+`)
+
+	for _, body := range []io.ReadCloser{nil, http.NoBody} {
+		httpReq, err := http.NewRequest(http.MethodPost, "http://marketplace.com/orders/1/cancel", nil)
+		assert.Nil(t, err)
+		httpReq.Body = body
+
+		// bodyless request is not captured, and does not need content type
+		req, err := FromRequest(httpReq)
+		assert.Nil(t, err)
+		assert.Nil(t, req.body)
+
+		resp, err := resolver.Resolve(context.Background(), req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	}
+}
+
+func TestFileBasedResolver_DefaultHeaders(t *testing.T) {
+	files := map[string]string{"products.yaml": `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+    response_headers:
+      X-Mocked: "recorded"
+      Content-Type: application/json
+    response_body: '[]'
+`}
+	resolver := newTestResolverWithFiles(t, files, WithDefaultHeaders(map[string]string{
+		"x-mock-source":    "mockhttp",
+		"X-Mocked":         "true",
+		"Content-Type":     "text/plain",
+		"X-Correlation-Id": `{{ index . "X-Request-Id" }}`,
+	}))
+
+	req := newTestRequest(t, http.MethodGet, "http://marketplace.com/products", "")
+	req.Header.Set("X-Request-Id", "req-1")
+	resp, err := resolver.Resolve(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, "mockhttp", resp.Header.Get("X-Mock-Source"))
+	assert.Equal(t, "req-1", resp.Header.Get("X-Correlation-Id"))
+	// the headers defined by the response take precedence
+	assert.Equal(t, "recorded", resp.Header.Get("X-Mocked"))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	t.Run("default content type", func(t *testing.T) {
+		resolver := NewMemoryResolverAdapter(WithDefaultHeaders(map[string]string{"Content-Type": "application/json"}))
+		err := NewStub().Host("marketplace.com").Get("/users").Body(`{"id": 1}`).Register(context.Background(), resolver)
+		assert.Nil(t, err)
+
+		resp, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, "http://marketplace.com/users", ""))
+		assert.Nil(t, err)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	})
+
+	t.Run("lowercase response header", func(t *testing.T) {
+		resolver := newTestResolverWithFiles(t, map[string]string{"users.yaml": `
+host: marketplace.com
+path: /users
+method: GET
+responses:
+  - status_code: 200
+    response_headers:
+      x-mocked: "false"
+      content-type: application/json
+    response_body: '[]'
+`}, WithDefaultHeaders(map[string]string{"X-Mocked": "true", "Content-Type": "text/plain"}))
+
+		resp, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, "http://marketplace.com/users", ""))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"false"}, resp.Header.Values("X-Mocked"))
+		assert.Equal(t, []string{"application/json"}, resp.Header.Values("Content-Type"))
+		assert.Len(t, resp.Header, 2)
+	})
+
+	t.Run("malformed template", func(t *testing.T) {
+		_, err := NewFileResolverAdapter(t.TempDir(), WithDefaultHeaders(map[string]string{"X-Correlation-Id": "{{ .id"}))
+		assert.ErrorContains(t, err, "default header X-Correlation-Id")
+
+		assert.Panics(t, func() {
+			NewMemoryResolverAdapter(WithDefaultHeaders(map[string]string{"X-Correlation-Id": "{{ .id"}))
+		})
+	})
+}
+
+func TestFileBasedResolver_ContentTypeFallback(t *testing.T) {
+	files := map[string]string{"products.yaml": `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+    rules:
+      - queryParams["format"] == "xml"
+    response_headers:
+      Content-Type: application/xml
+    response_body: '<products/>'
+  - status_code: 200
+    response_body: '[{"id": 1}]'
+`}
+
+	tests := []struct {
+		name        string
+		opts        []FileResolverOption
+		url         string
+		contentType string
+	}{
+		{"sniff by default", nil, "http://marketplace.com/products", "text/plain; charset=utf-8"},
+		{"fixed", []FileResolverOption{WithContentTypeFallback(ContentTypeFixed, "application/json")}, "http://marketplace.com/products", "application/json"},
+		{"none", []FileResolverOption{WithContentTypeFallback(ContentTypeNone, "")}, "http://marketplace.com/products", ""},
+		{"defined by response", []FileResolverOption{WithContentTypeFallback(ContentTypeFixed, "application/json")}, "http://marketplace.com/products?format=xml", "application/xml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := newTestResolverWithFiles(t, files, tt.opts...)
+
+			resp, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, tt.url, ""))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.contentType, resp.Header.Get("Content-Type"))
+		})
+	}
+}
+
+func TestFileBasedResolver_MaxBodySize(t *testing.T) {
+	files := map[string]string{"uploads.yaml": `
+host: marketplace.com
+path: /uploads
+method: POST
+responses:
+  - status_code: 400
+    rules:
+      - body.name == ""
+  - status_code: 201
+`}
+	newRequest := func(name string) *Request {
+		req := newTestRequest(t, http.MethodPost, "http://marketplace.com/uploads", fmt.Sprintf(`{"name": %q}`, name))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	tests := []struct {
+		name       string
+		policy     BodyLimitPolicy
+		body       string
+		statusCode int
+		err        error
+	}{
+		{"within limit", BodyLimitReject, "report.pdf", http.StatusCreated, nil},
+		{"reject oversized body", BodyLimitReject, strings.Repeat("a", 64), 0, ErrBodyTooLarge},
+		{"ignore oversized body", BodyLimitIgnore, strings.Repeat("a", 64), http.StatusCreated, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := newTestResolverWithFiles(t, files, WithMaxBodySize(32, tt.policy))
+
+			resp, err := resolver.Resolve(context.Background(), newRequest(tt.body))
+			assert.ErrorIs(t, err, tt.err)
+			if tt.err == nil {
+				assert.Equal(t, tt.statusCode, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestFileBasedResolver_ServeMuxPathSyntax(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /stores/{store}/files/{path...}
+method: GET
+responses:
+  - status_code: 200
+    rules:
+      - routeParams.store == "1" && routeParams.path == "docs/report.pdf"
+  - status_code: 404
+`)
+
+	resp, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, "http://marketplace.com/stores/1/files/docs/report.pdf", ""))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, "http://marketplace.com/stores/2/files/docs/report.pdf", ""))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestFileBasedResolver_InvalidRule(t *testing.T) {
+	dir := t.TempDir()
+	definition := `
+host: marketplace.com
+path: /check-price
+method: POST
+responses:
+  - status_code: 488
+    rules:
+      - body.name == 
+`
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "definition.yaml"), []byte(definition), 0o644))
+
+	resolver, err := NewFileResolverAdapter(dir)
+	assert.Nil(t, err)
+
+	err = resolver.LoadDefinition(context.Background())
+	assert.ErrorIs(t, err, ErrInvalidRule)
+
+	var fileErr *DefinitionFileError
+	assert.ErrorAs(t, err, &fileErr)
+	assert.Equal(t, "definition.yaml", fileErr.File)
+	var ruleErr *RuleError
+	assert.ErrorAs(t, err, &ruleErr)
+	assert.Equal(t, "POST marketplace.com/check-price", ruleErr.Definition)
+	assert.Equal(t, "body.name ==", ruleErr.Rule)
+	assert.NotNil(t, ruleErr.Cause)
+}
+
+func TestFileBasedResolver_RuleFunction(t *testing.T) {
+	resolver := newTestResolverWithFiles(t, map[string]string{
+		"signed.yaml": `
+host: marketplace.com
+path: /webhook
+method: GET
+responses:
+  - status_code: 401
+  - status_code: 200
+    rules:
+      - hasPrefix(headers["X-Sign"], "v1=")
+`,
+	}, WithRuleFunction("hasPrefix", strings.HasPrefix))
+
+	req := newTestRequest(t, http.MethodGet, "http://marketplace.com/webhook", "")
+	req.Header.Set("X-Sign", "v1=abcdef")
+
+	resp, err := resolver.Resolve(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestFileBasedResolver_RuleEvaluationError(t *testing.T) {
+	files := map[string]string{
+		"price.yaml": `
+host: marketplace.com
+path: /check-price
+method: POST
+responses:
+  - status_code: 200
+  - status_code: 488
+    rules:
+      - body.price > 100
+`,
+	}
+	body := `{"price": "expensive"}`
+
+	t.Run("report to rule error handler", func(t *testing.T) {
+		var reported []string
+		resolver := newTestResolverWithFiles(t, files, WithRuleErrorHandler(func(rule string, err error) {
+			assert.ErrorIs(t, err, ErrRuleEvaluation)
+			reported = append(reported, rule)
+		}))
+
+		req := newTestRequest(t, http.MethodPost, "http://marketplace.com/check-price", body)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := resolver.Resolve(context.Background(), req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"body.price > 100"}, reported)
+	})
+
+	t.Run("fail resolve on strict rules", func(t *testing.T) {
+		resolver := newTestResolverWithFiles(t, files, WithStrictRules())
+
+		req := newTestRequest(t, http.MethodPost, "http://marketplace.com/check-price", body)
+		req.Header.Set("Content-Type", "application/json")
+
+		_, err := resolver.Resolve(context.Background(), req)
+		assert.ErrorIs(t, err, ErrRuleEvaluation)
+	})
+}
+
+func TestFileBasedResolver_RequestMetadataRules(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /v1/*
+method: GET
+responses:
+  - status_code: 200
+  - status_code: 410
+    rules:
+      - method == "GET" && host == "marketplace.com"
+      - path startsWith "/v1/legacy"
+      - rawQuery == "page=2"
+      - url == "http://marketplace.com/v1/legacy/items?page=2"
+`)
+
+	tests := []struct {
+		name       string
+		url        string
+		statusCode int
+	}{
+		{"match request metadata", "http://marketplace.com/v1/legacy/items?page=2", http.StatusGone},
+		{"path not match", "http://marketplace.com/v1/items?page=2", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newTestRequest(t, http.MethodGet, tt.url, "")
+
+			resp, err := resolver.Resolve(context.Background(), req)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.statusCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestFileBasedResolver_RuleGroups(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /check-price
+method: POST
+responses:
+  - status_code: 200
+  - status_code: 488
+    rules:
+      - headers["X-Region"] == "ID"
+      - any_of:
+          - body.name == "William"
+          - all_of:
+              - body.vip == true
+              - body.age > 18
+`)
+
+	tests := []struct {
+		name       string
+		body       string
+		region     string
+		statusCode int
+	}{
+		{"first alternative fulfilled", `{"name": "William"}`, "ID", 488},
+		{"nested alternative fulfilled", `{"name": "Mocker", "vip": true, "age": 20}`, "ID", 488},
+		{"nested alternative partially fulfilled", `{"name": "Mocker", "vip": true, "age": 10}`, "ID", 200},
+		{"top level rule not fulfilled", `{"name": "William"}`, "SG", 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newTestRequest(t, http.MethodPost, "http://marketplace.com/check-price", tt.body)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Region", tt.region)
+
+			resp, err := resolver.Resolve(context.Background(), req)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.statusCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestFileBasedResolver_SchemaRule(t *testing.T) {
+	resolver := newTestResolverWithFiles(t, map[string]string{
+		"order.yaml": `
+host: marketplace.com
+path: /orders
+method: POST
+responses:
+  - status_code: 400
+  - status_code: 201
+    rules:
+      - schema: order.schema.json
+`,
+		"order.schema.json": `{
+			"type": "object",
+			"required": ["item", "quantity"],
+			"properties": {
+				"item": { "type": "string" },
+				"quantity": { "type": "integer", "minimum": 1 }
+			}
+		}`,
+	})
+
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+	}{
+		{"valid payload", `{"item": "book", "quantity": 2}`, http.StatusCreated},
+		{"missing required field", `{"item": "book"}`, http.StatusBadRequest},
+		{"invalid quantity", `{"item": "book", "quantity": 0}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newTestRequest(t, http.MethodPost, "http://marketplace.com/orders", tt.body)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := resolver.Resolve(context.Background(), req)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.statusCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestFileBasedResolver_TimeWindowRules(t *testing.T) {
+	files := map[string]string{
+		"maintenance.yaml": `
+host: marketplace.com
+path: /check-price
+method: GET
+responses:
+  - status_code: 503
+  - status_code: 200
+    rules:
+      - weekday not in ["Saturday", "Sunday"]
+      - timeBetween(currentTime, "09:00", "17:00")
+`,
+	}
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		statusCode int
+	}{
+		{"business hours", time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), http.StatusOK},
+		{"after business hours", time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC), http.StatusServiceUnavailable},
+		{"weekend", time.Date(2024, 1, 6, 10, 0, 0, 0, time.UTC), http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := newTestResolverWithFiles(t, files, WithClock(func() time.Time { return tt.now }))
+			req := newTestRequest(t, http.MethodGet, "http://marketplace.com/check-price", "")
+
+			resp, err := resolver.Resolve(context.Background(), req)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.statusCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestFileBasedResolver_CallCountRules(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /check-price
+method: GET
+responses:
+  - status_code: 200
+  - status_code: 503
+    rules:
+      - callCount % 3 == 0
+`)
+
+	var statusCodes []int
+	for i := 0; i < 6; i++ {
+		req := newTestRequest(t, http.MethodGet, "http://marketplace.com/check-price", "")
+
+		resp, err := resolver.Resolve(context.Background(), req)
+		assert.Nil(t, err)
+		statusCodes = append(statusCodes, resp.StatusCode)
+	}
+	assert.Equal(t, []int{200, 200, 503, 200, 200, 503}, statusCodes)
+}
+
+func TestFileBasedResolver_State(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /login/:user
+method: POST
+responses:
+  - status_code: 200
+    set_state:
+      token: "token-{{.user}}"
+      user: "{{.user}}"
+`, `
+host: marketplace.com
+path: /profile
+method: GET
+responses:
+  - status_code: 401
+  - status_code: 200
+    response_body: "hello {{state \"user\"}}"
+    enable_template: true
+    rules:
+      - state.token != "" && headers["Authorization"] == "Bearer " + state.token
+`)
+
+	profile := func() (int, string) {
+		req := newTestRequest(t, http.MethodGet, "http://marketplace.com/profile", "")
+		req.Header.Set("Authorization", "Bearer token-william")
+
+		resp, err := resolver.Resolve(context.Background(), req)
+		assert.Nil(t, err)
+		body, err := io.ReadAll(resp.Body)
+		assert.Nil(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	statusCode, _ := profile()
+	assert.Equal(t, http.StatusUnauthorized, statusCode)
+
+	login := newTestRequest(t, http.MethodPost, "http://marketplace.com/login/william", `{"password": "secret"}`)
+	login.Header.Set("Content-Type", "application/json")
+	_, err := resolver.Resolve(context.Background(), login)
+	assert.Nil(t, err)
+
+	statusCode, body := profile()
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, "hello william", body)
+}
+
+func TestFileBasedResolver_Delay(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /slow
+method: GET
+responses:
+  - status_code: 200
+    delay: 200
+`)
+
+	t.Run("delay the response", func(t *testing.T) {
+		req := newTestRequest(t, http.MethodGet, "http://marketplace.com/slow", "")
+
+		start := time.Now()
+		resp, err := resolver.Resolve(context.Background(), req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	})
+
+	t.Run("return immediately when context is done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		req := newTestRequest(t, http.MethodGet, "http://marketplace.com/slow", "").WithContext(ctx)
+
+		start := time.Now()
+		resp, err := resolver.Resolve(ctx, req)
+		assert.Nil(t, resp)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 200*time.Millisecond)
+	})
+}
+
+func TestFileBasedResolver_MatchedDefinition(t *testing.T) {
+	resolver := newTestResolver(t, `
+host: marketplace.com
+path: /products/:id
+method: GET
+responses:
+  - status_code: 200
+`)
+
+	client := newTestClient(resolver)
+	var infos []ResolveInfo
+	client.ResolveHook = func(req *Request, info ResolveInfo) {
+		infos = append(infos, info)
+	}
+
+	_, err := client.Get("http://marketplace.com/products/1")
+	assert.Nil(t, err)
+
+	assert.Len(t, infos, 1)
+	assert.True(t, infos[0].Mocked)
+	assert.False(t, infos[0].Bypassed)
+	assert.Equal(t, "GET marketplace.com/products/:id", infos[0].Definition)
+}
+
+func TestFileBasedResolver_DelayPolicy(t *testing.T) {
+	files := map[string]string{"slow.yaml": `
+host: marketplace.com
+path: /slow
+method: GET
+responses:
+  - status_code: 200
+    delay: 200
+`}
+
+	tests := []struct {
+		name        string
+		policy      DelayPolicy
+		wantErr     error
+		minDuration time.Duration
+		maxDuration time.Duration
+	}{
+		{name: "until deadline", policy: DelayUntilDeadline, wantErr: context.DeadlineExceeded, minDuration: 50 * time.Millisecond, maxDuration: 200 * time.Millisecond},
+		{name: "full", policy: DelayFull, minDuration: 200 * time.Millisecond, maxDuration: time.Second},
+		{name: "truncate", policy: DelayTruncate, minDuration: 50 * time.Millisecond, maxDuration: 200 * time.Millisecond},
+		{name: "fail fast", policy: DelayFailFast, wantErr: context.DeadlineExceeded, maxDuration: 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := newTestResolverWithFiles(t, files, WithDelayPolicy(tt.policy))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			req := newTestRequest(t, http.MethodGet, "http://marketplace.com/slow", "").WithContext(ctx)
+
+			start := time.Now()
+			resp, err := resolver.Resolve(ctx, req)
+			elapsed := time.Since(start)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			}
+			assert.GreaterOrEqual(t, elapsed, tt.minDuration)
+			assert.Less(t, elapsed, tt.maxDuration)
+		})
+	}
+}
+
+func TestFileBasedResolver_MaxUses(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		want       []int
+	}{
+		{
+			name: "max uses per response",
+			definition: `
+host: marketplace.com
+path: /checkout
+method: GET
+responses:
+  - status_code: 500
+    max_uses: 2
+    rules:
+      - method == "GET"
+  - status_code: 200
+`,
+			want: []int{500, 500, 200, 200},
+		},
+		{
+			name: "replay once per definition",
+			definition: `
+host: marketplace.com
+path: /checkout
+method: GET
+replay: once
+responses:
+  - status_code: 201
+  - status_code: 200
+    max_uses: 2
+    rules:
+      - method == "GET"
+`,
+			want: []int{200, 200, 201, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := newTestResolver(t, tt.definition)
+
+			var statusCodes []int
+			for range tt.want {
+				req := newTestRequest(t, http.MethodGet, "http://marketplace.com/checkout", "")
+				resp, err := resolver.Resolve(context.Background(), req)
+				if err != nil {
+					assert.ErrorIs(t, err, ErrNoMockResponse)
+					statusCodes = append(statusCodes, 0)
+					continue
+				}
+				statusCodes = append(statusCodes, resp.StatusCode)
+			}
+			assert.Equal(t, tt.want, statusCodes)
+		})
+	}
+}
+
+func TestFileBasedResolver_UnknownReplay(t *testing.T) {
+	dir := t.TempDir()
+	definition := `
+host: marketplace.com
+path: /checkout
+method: GET
+replay: twice
+responses:
+  - status_code: 200
+`
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "checkout.yaml"), []byte(definition), 0o644))
+
+	resolver, err := NewFileResolverAdapter(dir)
+	assert.Nil(t, err)
+	assert.ErrorIs(t, resolver.LoadDefinition(context.Background()), ErrUnknownReplay)
+}
+
+func TestFileBasedResolver_MaxRecordingAge(t *testing.T) {
+	files := map[string]string{"products.yaml": `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - recorded_at: 2024-01-01T00:00:00Z
+    status_code: 200
+`}
+	recordedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		wantErr error
+	}{
+		{name: "fresh recording", now: recordedAt.Add(24 * time.Hour)},
+		{name: "stale recording", now: recordedAt.Add(8 * 24 * time.Hour), wantErr: ErrNoMockResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := newTestResolverWithFiles(t, files,
+				WithClock(func() time.Time { return tt.now }),
+				WithMaxRecordingAge(7*24*time.Hour),
+			)
+
+			req := newTestRequest(t, http.MethodGet, "http://marketplace.com/products", "")
+			resp, err := resolver.Resolve(context.Background(), req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func TestFileBasedResolver_PartialLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"products.yaml": "host: marketplace.com\npath: /products\nmethod: GET\nresponses:\n  - status_code: 200\n",
+		"orders.yaml":   "host: [marketplace.com",
+		"checkout.yaml": "host: marketplace.com\npath: /checkout\nmethod: GET\nstrategy: sometimes\nresponses:\n  - status_code: 200\n",
+	}
+	for name, content := range files {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	t.Run("abort on first invalid file", func(t *testing.T) {
+		resolver, err := NewFileResolverAdapter(dir)
+		assert.Nil(t, err)
+
+		err = resolver.LoadDefinition(context.Background())
+		var fileErr *DefinitionFileError
+		assert.ErrorAs(t, err, &fileErr)
+		assert.Equal(t, "checkout.yaml", fileErr.File)
+		assert.ErrorIs(t, err, ErrUnknownStrategy)
+	})
+
+	t.Run("skip invalid files", func(t *testing.T) {
+		resolver, err := NewFileResolverAdapter(dir, WithPartialLoad())
+		assert.Nil(t, err)
+
+		err = resolver.LoadDefinition(context.Background())
+		var loadErrs LoadErrors
+		assert.ErrorAs(t, err, &loadErrs)
+		assert.Len(t, loadErrs, 2)
+		assert.Equal(t, "checkout.yaml", loadErrs[0].File)
+		assert.Equal(t, "orders.yaml", loadErrs[1].File)
+		assert.ErrorIs(t, err, ErrUnknownStrategy)
+
+		resp, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, "http://marketplace.com/products", ""))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestFileBasedResolver_LoadConcurrency(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 100; i++ {
+		content := fmt.Sprintf("host: marketplace.com\npath: /products\nmethod: GET\nresponses:\n  - status_code: %d\n", 200+i)
+		if i == 40 || i == 70 {
+			content = "host: [marketplace.com"
+		}
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("products-%03d.yaml", i)), []byte(content), 0o644))
+	}
+
+	for _, concurrency := range []int{0, 1, 8, 200} {
+		t.Run(fmt.Sprintf("concurrency %d", concurrency), func(t *testing.T) {
+			resolver, err := NewFileResolverAdapter(dir, WithLoadConcurrency(concurrency))
+			assert.Nil(t, err)
+			var fileErr *DefinitionFileError
+			assert.ErrorAs(t, resolver.LoadDefinition(context.Background()), &fileErr)
+			assert.Equal(t, "products-040.yaml", fileErr.File)
+
+			adapter, err := NewFileResolverAdapter(dir, WithLoadConcurrency(concurrency), WithPartialLoad())
+			assert.Nil(t, err)
+			resolver = adapter
+			var loadErrs LoadErrors
+			assert.ErrorAs(t, resolver.LoadDefinition(context.Background()), &loadErrs)
+			assert.Len(t, loadErrs, 2)
+			assert.Equal(t, "products-040.yaml", loadErrs[0].File)
+			definitions := adapter.(*fileBasedResolver).loadedDefinitions()
+			assert.Len(t, definitions, 98)
+			assert.Equal(t, "products-099.yaml", definitions[97].file)
+
+			// definitions are registered in the file order, the first file is matched
+			resp, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, "http://marketplace.com/products", ""))
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func TestFileBasedResolver_LargeDefinitionFile(t *testing.T) {
+	recorded := strings.Repeat(`{"id": 1, "name": "Shoes"},`, 100000)
+	spec := fmt.Sprintf("host: marketplace.com\npath: /products\nmethod: GET\nresponses:\n  - status_code: 200\n    response_body: '[%s]'\n", strings.TrimSuffix(recorded, ","))
+
+	for _, opts := range [][]FileResolverOption{nil, {WithLazyLoad()}} {
+		resolver := newTestResolverWithFiles(t, map[string]string{"products.yaml": spec, "empty.yaml": ""}, opts...)
+
+		resp, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, "http://marketplace.com/products", ""))
+		assert.Nil(t, err)
+		body, err := io.ReadAll(resp.Body)
+		assert.Nil(t, err)
+		assert.Len(t, body, len(recorded)+1)
+	}
+}
+
+func TestFileBasedResolver_Reset(t *testing.T) {
+	resolver := newTestResolverWithFiles(t, map[string]string{"products.yaml": `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+    set_state:
+      visited: "true"
+`})
+	newReq := func() *Request {
+		return newTestRequest(t, http.MethodGet, "http://marketplace.com/products", "")
+	}
+
+	_, err := resolver.Resolve(context.Background(), newReq())
+	assert.Nil(t, err)
+	assert.Equal(t, "true", resolver.state.get("visited"))
+
+	resolver.Reset()
+	assert.Equal(t, "", resolver.state.get("visited"))
+	_, err = resolver.Resolve(context.Background(), newReq())
+	assert.ErrorIs(t, err, ErrNoMockResponse)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(resolver.dir, "products.yaml"), []byte("host: marketplace.com\npath: /products\nmethod: GET\nresponses:\n  - status_code: 201\n"), 0o644))
+	assert.Nil(t, resolver.LoadDefinition(context.Background()))
+	resp, err := resolver.Resolve(context.Background(), newReq())
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+}
+
+func TestFileBasedResolver_LazyLoad(t *testing.T) {
+	resolver := newTestResolverWithFiles(t, map[string]string{
+		"products.yaml": `
+host: marketplace.com
+path: /products/:id
+method: GET
+responses:
+  - status_code: 200
+    rules:
+      - callCount > 1
+  - status_code: 201
+`,
+		"orders.yaml": `
+host: marketplace.com
+path: /orders
+method: GET
+responses:
+  - status_code: 200
+    rules:
+      - body.amount >
+`,
+	}, WithLazyLoad())
+
+	for _, definition := range resolver.loadedDefinitions() {
+		assert.Nil(t, definition.Responses)
+	}
+
+	for _, want := range []int{http.StatusCreated, http.StatusOK} {
+		resp, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, "http://marketplace.com/products/1", ""))
+		assert.Nil(t, err)
+		assert.Equal(t, want, resp.StatusCode)
+	}
+
+	_, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, "http://marketplace.com/orders", ""))
+	var fileErr *DefinitionFileError
+	assert.ErrorAs(t, err, &fileErr)
+	assert.Equal(t, "orders.yaml", fileErr.File)
+	assert.ErrorIs(t, err, ErrInvalidRule)
+}
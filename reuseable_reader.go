@@ -8,22 +8,23 @@ import (
 // reusableReader is a custom type implementing the io.Reader interface, enhancing it with
 // the ability to reset and re-read the underlying data efficiently.
 type reusableReader struct {
-	io.Reader
-	readBuf *bytes.Buffer
-	backBuf *bytes.Buffer
+	reader *bytes.Reader
+	data   []byte
 }
 
 // ReusableReader creates and returns a new reusableReader based on the provided io.Reader.
 // The reusableReader allows for multiple reads of the same data efficiently.
+//
+// The data is captured once with pooled buffer, and kept in a single slice of the exact size.
 func ReusableReader(r io.Reader) io.Reader {
-	readBuf := bytes.Buffer{}
-	readBuf.ReadFrom(r) // error handling ignored for brevity
-	backBuf := bytes.Buffer{}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.ReadFrom(r) // error handling ignored for brevity
+	data := append([]byte(nil), buf.Bytes()...)
 
 	return reusableReader{
-		io.TeeReader(&readBuf, &backBuf),
-		&readBuf,
-		&backBuf,
+		reader: bytes.NewReader(data),
+		data:   data,
 	}
 }
 
@@ -31,15 +32,15 @@ func ReusableReader(r io.Reader) io.Reader {
 // If the end of the underlying data is reached (io.EOF), it automatically resets the reader for
 // subsequent reads.
 func (r reusableReader) Read(p []byte) (int, error) {
-	n, err := r.Reader.Read(p)
+	n, err := r.reader.Read(p)
 	if err == io.EOF {
 		r.reset()
 	}
 	return n, err
 }
 
-// reset rewinds the reusableReader by copying the captured data from the backup buffer
-// back to the main read buffer, allowing for the underlying data to be read again.
+// reset rewinds the reusableReader to the beginning of the captured data,
+// allowing for the underlying data to be read again.
 func (r reusableReader) reset() {
-	io.Copy(r.readBuf, r.backBuf) // nolint: errcheck
+	r.reader.Reset(r.data)
 }
@@ -38,3 +38,19 @@ func TestReusableReader_Read(t *testing.T) {
 		t.Errorf("Data mismatch after reset")
 	}
 }
+
+func TestReusableReader_ReadAll(t *testing.T) {
+	data := bytes.Repeat([]byte("Hello, world!"), 1000)
+	reusable := ReusableReader(bytes.NewReader(data))
+
+	// the reader is rewound on EOF, so the whole data can be read again
+	for i := 0; i < 3; i++ {
+		read, err := io.ReadAll(reusable)
+		if err != nil {
+			t.Errorf("Error reading: %s", err)
+		}
+		if !bytes.Equal(read, data) {
+			t.Errorf("Data mismatch on read %d", i)
+		}
+	}
+}
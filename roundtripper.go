@@ -10,10 +10,29 @@ import (
 // HTTP client to execute requests.
 //
 // WARN: roundTripper struct is not intended to be used by outside package, only to support StandardClient
+// and NewRoundTripper
 type roundTripper struct {
 	Client *Client
 }
 
+// NewRoundTripper returns http.RoundTripper that serve the mock response from the resolver,
+// and send the other requests (passthrough) through next, ex: the application's own transport with auth / instrumentation:
+//
+//	httpClient.Transport = mockhttp.NewRoundTripper(resolver, httpClient.Transport)
+//
+// next defaults to http.DefaultTransport when nil. The mock client behind the transport can be customized via opts,
+// except the redirects, which are returned as is to be followed by the caller's http.Client.
+func NewRoundTripper(resolver ResolverAdapter, next http.RoundTripper, opts ...ClientOption) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	client := NewClient(resolver, append([]ClientOption{WithTransport(next)}, opts...)...)
+	client.HTTPClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &roundTripper{Client: client}
+}
+
 // RoundTrip satisfies the http.RoundTripper interface.
 func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 
@@ -37,3 +56,28 @@ func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 
 	return resp, err
 }
+
+// Wrap layer the mock on top of the transport of the existing client in place (see NewRoundTripper),
+// keeping its Timeout, Jar, CheckRedirect and Transport, ex: the client constructed by third-party SDK:
+//
+//	mockhttp.Wrap(sdk.HTTPClient(), resolver)
+//
+// Nil client is replaced by a new client. Client that is already wrapped is returned as is.
+func Wrap(client *http.Client, resolver ResolverAdapter, opts ...ClientOption) *http.Client {
+	if client == nil {
+		client = &http.Client{}
+	}
+	if _, wrapped := client.Transport.(*roundTripper); wrapped {
+		return client
+	}
+	client.Transport = NewRoundTripper(resolver, client.Transport, opts...)
+	return client
+}
+
+// CloseIdleConnections closes the idle connections of the client transports,
+// called by http.Client.CloseIdleConnections.
+func (rt *roundTripper) CloseIdleConnections() {
+	if rt.Client != nil {
+		rt.Client.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,97 @@
+package mockhttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// headerTransport set the header on every request sent through it, like an auth transport.
+type headerTransport struct {
+	next  http.RoundTripper
+	calls atomic.Int32
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls.Add(1)
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer token")
+	return t.next.RoundTrip(req)
+}
+
+func TestNewRoundTripper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old-orders" {
+			http.Redirect(w, r, "/orders", http.StatusMovedPermanently)
+			return
+		}
+		_, _ = io.WriteString(w, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	resolver := NewMemoryResolverAdapter()
+	assert.Nil(t, NewStub().Host("marketplace.com").Get("/products").Reply(http.StatusOK).Body("mocked").Register(context.Background(), resolver))
+
+	transport := &headerTransport{next: http.DefaultTransport}
+	httpClient := &http.Client{Transport: NewRoundTripper(resolver, transport)}
+
+	resp, err := httpClient.Get("http://marketplace.com/products")
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "mocked", string(body))
+	assert.Equal(t, int32(0), transport.calls.Load())
+
+	// the redirect is followed by the caller, every hop goes through the existing transport
+	resp, err = httpClient.Get(server.URL + "/old-orders")
+	assert.Nil(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, "Bearer token", string(body))
+	assert.Equal(t, server.URL+"/orders", resp.Request.URL.String())
+	assert.Equal(t, int32(2), transport.calls.Load())
+
+	httpClient.CloseIdleConnections()
+}
+
+func TestWrap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "1"})
+		_, _ = io.WriteString(w, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	resolver := NewMemoryResolverAdapter()
+	assert.Nil(t, NewStub().Host("marketplace.com").Get("/products").Reply(http.StatusOK).Body("mocked").Register(context.Background(), resolver))
+
+	// the client constructed by the SDK
+	jar, _ := cookiejar.New(nil)
+	transport := &headerTransport{next: http.DefaultTransport}
+	sdkClient := &http.Client{Transport: transport, Jar: jar, Timeout: time.Minute}
+
+	assert.Same(t, sdkClient, Wrap(sdkClient, resolver))
+	assert.Same(t, sdkClient, Wrap(sdkClient, resolver))
+	assert.Equal(t, time.Minute, sdkClient.Timeout)
+	assert.Same(t, jar, sdkClient.Jar)
+
+	resp, err := sdkClient.Get("http://marketplace.com/products")
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "mocked", string(body))
+
+	resp, err = sdkClient.Get(server.URL)
+	assert.Nil(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, "Bearer token", string(body))
+	assert.Equal(t, int32(1), transport.calls.Load())
+	serverURL, _ := url.Parse(server.URL)
+	assert.Len(t, jar.Cookies(serverURL), 1)
+
+	assert.NotNil(t, Wrap(nil, resolver).Transport)
+}
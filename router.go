@@ -0,0 +1,272 @@
+package mockhttp
+
+import (
+	"regexp"
+	"strings"
+)
+
+// definitionSet is the registered definitions, along with the router and index to look them up.
+// The set is built once (on load / reload / runtime registration) and never modified.
+type definitionSet struct {
+	definitions mockDefinitions
+	router      *router
+	// index is the definitions grouped by host and http method, then by rank (in the registration order).
+	index map[routeKey]rankedDefinitions
+}
+
+// rankedDefinitions is the definitions with the same host and http method, grouped by rank.
+type rankedDefinitions [rankWildcard + 1]mockDefinitions
+
+var emptyDefinitionSet = newDefinitionSet(nil)
+
+func newDefinitionSet(definitions mockDefinitions) *definitionSet {
+	index := make(map[routeKey]rankedDefinitions)
+	for _, definition := range definitions {
+		key := routeKey{host: definition.Host, method: definition.Method}
+		ranked := index[key]
+		rank := rankOf(definition)
+		ranked[rank] = append(ranked[rank], definition)
+		index[key] = ranked
+	}
+	return &definitionSet{definitions: definitions, router: newRouter(definitions), index: index}
+}
+
+// definitionSet getAllExactPathDefinitions
+// Fetch all mock definitions with exact path
+// based on request Host and http method.
+//
+// ex:
+// /v1/api/mock/:id => false (contain path param)
+// /v1/api/mock/1   => true (exact path)
+// /v1/api/mock/*   => false (have wildcard)
+func (s *definitionSet) getAllExactPathDefinitions(host, method string) []fileBasedMockDefinition {
+	return s.index[routeKey{host: host, method: method}][rankExactPath]
+}
+
+// definitionSet getAllContainPathParamDefinitions
+// Fetch all mock definitions that contain path param
+// based on request Host and http method.
+//
+// ex:
+// /v1/api/mock/:id => true (contain path param)
+// /v1/api/mock/1   => false (exact path)
+// /v1/api/mock/*   => false (have wildcard)
+func (s *definitionSet) getAllContainPathParamDefinitions(host, method string) []fileBasedMockDefinition {
+	return s.index[routeKey{host: host, method: method}][rankPathParam]
+}
+
+// definitionSet getAllHaveWildcardDefinitions
+// Fetch all mock definitions that have wildcard
+// based on request Host and http method.
+//
+// ex:
+// /v1/api/mock/:id => false (contain path param)
+// /v1/api/mock/1   => false (exact path)
+// /v1/api/mock/*   => true (have wildcard)
+func (s *definitionSet) getAllHaveWildcardDefinitions(host, method string) []fileBasedMockDefinition {
+	return s.index[routeKey{host: host, method: method}][rankWildcard]
+}
+
+// definitionRank is the matching priority of the definition path: exact path, with path parameters, with wildcard.
+type definitionRank int
+
+const (
+	rankExactPath definitionRank = iota
+	rankPathParam
+	rankWildcard
+)
+
+func rankOf(definition fileBasedMockDefinition) definitionRank {
+	switch {
+	case definition.containsWildcard:
+		return rankWildcard
+	case definition.containParams:
+		return rankPathParam
+	default:
+		return rankExactPath
+	}
+}
+
+// routeKey is the host and http method of the definitions in the same route tree.
+type routeKey struct {
+	host   string
+	method string
+}
+
+// routeNode is a single path segment of the route tree. The path of the node is the path of its parent,
+// followed by either a literal segment (static child) or any non-empty segment (param child).
+type routeNode struct {
+	static map[string]*routeNode
+	param  *routeNode
+
+	// ends are the definitions (index) whose path ends at the node.
+	ends []int
+	// wildcards are the definitions (index) whose path ends at the node followed by wildcard,
+	// matching the node path itself and any path under it.
+	wildcards []int
+}
+
+// routeTree is the route tree of the definitions with the same host and http method.
+type routeTree struct {
+	root routeNode
+	// fallback are the definitions (index) whose path can't be split into segments
+	// (ex: param in the middle of the segment, /files/:name.json), matched with the path regex instead.
+	fallback []int
+}
+
+// router look up the definition matching the request host, http method and path,
+// without evaluating the path regex of every registered definition.
+//
+// The router selects the same definition as scanning the definition stores in order (see definitionStores):
+// the lowest rank (exact path, path parameters, wildcard) first, then the first registered definition.
+type router struct {
+	definitions mockDefinitions
+	trees       map[routeKey]*routeTree
+}
+
+func newRouter(definitions mockDefinitions) *router {
+	rt := &router{definitions: definitions, trees: make(map[routeKey]*routeTree)}
+	for idx, definition := range definitions {
+		key := routeKey{host: definition.Host, method: definition.Method}
+		tree, exist := rt.trees[key]
+		if !exist {
+			tree = &routeTree{}
+			rt.trees[key] = tree
+		}
+		tree.insert(idx, definition)
+	}
+	return rt
+}
+
+// simpleParamSegment is the path param segment supported by the route tree, same as the param name of pathregex.
+var simpleParamSegment = regexp.MustCompile(`^:\w+$`)
+
+func (t *routeTree) insert(idx int, definition fileBasedMockDefinition) {
+	segments, wildcard, ok := splitPattern(definition)
+	if !ok {
+		t.fallback = append(t.fallback, idx)
+		return
+	}
+
+	node := &t.root
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			if node.param == nil {
+				node.param = &routeNode{}
+			}
+			node = node.param
+			continue
+		}
+		if node.static == nil {
+			node.static = make(map[string]*routeNode)
+		}
+		child, exist := node.static[segment]
+		if !exist {
+			child = &routeNode{}
+			node.static[segment] = child
+		}
+		node = child
+	}
+
+	if wildcard {
+		node.wildcards = append(node.wildcards, idx)
+	} else {
+		node.ends = append(node.ends, idx)
+	}
+}
+
+// splitPattern split the definition path into the route segments, following the same rules as pathregex.CompilePath:
+// the trailing / and * are ignored, and the path ending with * match any path under it.
+// Return false when the path has segment not supported by the route tree.
+func splitPattern(definition fileBasedMockDefinition) ([]string, bool, bool) {
+	pattern := definition.pattern()
+	path := pattern.Source()
+
+	wildcard := strings.HasSuffix(path, "*")
+	path = strings.TrimSuffix(path, "*")
+	segments := splitPath(path)
+
+	params := 0
+	for _, segment := range segments {
+		switch {
+		case simpleParamSegment.MatchString(segment):
+			params++
+		case strings.Contains(segment, ":"):
+			return nil, false, false
+		}
+	}
+	if wildcard {
+		params++
+	}
+	// the route tree must capture the same params as the path regex
+	if params != len(pattern.ParamNames()) {
+		return nil, false, false
+	}
+	return segments, wildcard, true
+}
+
+// splitPath split the (cleaned) path into non-empty segments, ex: /products/1/ => [products 1].
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// lookup returns the definition matching the request, false when no definition matched.
+func (rt *router) lookup(host, method, path string) (fileBasedMockDefinition, bool) {
+	tree, exist := rt.trees[routeKey{host: host, method: method}]
+	if !exist {
+		return fileBasedMockDefinition{}, false
+	}
+
+	best := -1
+	consider := func(idx int) {
+		if best < 0 || rt.less(idx, best) {
+			best = idx
+		}
+	}
+
+	tree.root.match(splitPath(path), consider)
+	for _, idx := range tree.fallback {
+		if rt.definitions[idx].pattern().Match(path) {
+			consider(idx)
+		}
+	}
+
+	if best < 0 {
+		return fileBasedMockDefinition{}, false
+	}
+	return rt.definitions[best], true
+}
+
+// less compare the matching priority of two definitions (index).
+func (rt *router) less(i, j int) bool {
+	rankI, rankJ := rankOf(rt.definitions[i]), rankOf(rt.definitions[j])
+	if rankI != rankJ {
+		return rankI < rankJ
+	}
+	return i < j
+}
+
+// match walk every branch of the node matching the remaining path segments,
+// and report all the matching definitions.
+func (n *routeNode) match(segments []string, consider func(idx int)) {
+	for _, idx := range n.wildcards {
+		consider(idx)
+	}
+	if len(segments) == 0 {
+		for _, idx := range n.ends {
+			consider(idx)
+		}
+		return
+	}
+
+	if child, exist := n.static[segments[0]]; exist {
+		child.match(segments[1:], consider)
+	}
+	if n.param != nil {
+		n.param.match(segments[1:], consider)
+	}
+}
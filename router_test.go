@@ -0,0 +1,123 @@
+package mockhttp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestDefinitions(host, method string, paths ...string) mockDefinitions {
+	definitions := make(mockDefinitions, 0, len(paths))
+	for _, path := range paths {
+		definition := fileBasedMockDefinition{Host: host, Method: method, Path: path}
+		definition.compilePath()
+		definitions = append(definitions, definition)
+	}
+	return definitions
+}
+
+// scanDefinitions look up the definition by scanning all the definitions for every rank in order, as the router reference.
+func scanDefinitions(definitions mockDefinitions, host, method, path string) (fileBasedMockDefinition, bool) {
+	for _, rank := range []definitionRank{rankExactPath, rankPathParam, rankWildcard} {
+		for _, definition := range definitions {
+			if definition.Host == host && definition.Method == method && rankOf(definition) == rank && definition.pattern().Match(path) {
+				return definition, true
+			}
+		}
+	}
+	return fileBasedMockDefinition{}, false
+}
+
+func TestDefinitionSet_Stores(t *testing.T) {
+	definitions := newTestDefinitions("marketplace.com", "GET", "/products/*", "/products/:id", "/products", "/orders/:id", "/orders")
+	definitions = append(definitions, newTestDefinitions("seller.com", "GET", "/products/:id")...)
+	set := newDefinitionSet(definitions)
+
+	paths := func(definitions []fileBasedMockDefinition) []string {
+		var paths []string
+		for _, definition := range definitions {
+			paths = append(paths, definition.Path)
+		}
+		return paths
+	}
+	assert.Equal(t, []string{"/products", "/orders"}, paths(set.getAllExactPathDefinitions("marketplace.com", "GET")))
+	assert.Equal(t, []string{"/products/:id", "/orders/:id"}, paths(set.getAllContainPathParamDefinitions("marketplace.com", "GET")))
+	assert.Equal(t, []string{"/products/*"}, paths(set.getAllHaveWildcardDefinitions("marketplace.com", "GET")))
+	assert.Equal(t, []string{"/products/:id"}, paths(set.getAllContainPathParamDefinitions("seller.com", "GET")))
+	assert.Empty(t, set.getAllExactPathDefinitions("marketplace.com", "POST"))
+	assert.Empty(t, emptyDefinitionSet.getAllExactPathDefinitions("marketplace.com", "GET"))
+}
+
+func TestRouter_Lookup(t *testing.T) {
+	definitions := newTestDefinitions("marketplace.com", "GET",
+		"/products/*",
+		"/products/:id",
+		"/products/:id/reviews",
+		"/products/featured",
+		"/:resource/featured",
+		"/files/:name.json",
+		"/products/:id/*",
+		"/",
+		"/orders//:id/",
+	)
+	definitions = append(definitions, newTestDefinitions("marketplace.com", "POST", "/products")...)
+	definitions = append(definitions, newTestDefinitions("seller.com", "GET", "/products/:id", "*")...)
+	definitions = append(definitions, newTestDefinitions("buyer.com", "GET", "/orders/{id}", "/files/{path...}")...)
+	rt := newRouter(definitions)
+
+	tests := []struct {
+		host, method, path string
+		expected           string
+	}{
+		{"marketplace.com", "GET", "/products/featured", "/products/featured"},
+		{"marketplace.com", "GET", "/products/featured/", "/products/featured"},
+		{"marketplace.com", "GET", "/products/1", "/products/:id"},
+		{"marketplace.com", "GET", "/products/1/reviews", "/products/:id/reviews"},
+		{"marketplace.com", "GET", "/categories/featured", "/:resource/featured"},
+		{"marketplace.com", "GET", "/products/1/reviews/2", "/products/*"},
+		{"marketplace.com", "GET", "/products", "/products/*"},
+		{"marketplace.com", "GET", "/files/report.json", "/files/:name.json"},
+		{"marketplace.com", "GET", "/", "/"},
+		{"marketplace.com", "GET", "/orders/1", "/orders//:id/"},
+		{"marketplace.com", "GET", "/orders", ""},
+		{"marketplace.com", "POST", "/products", "/products"},
+		{"marketplace.com", "POST", "/products/1", ""},
+		{"seller.com", "GET", "/products/1", "/products/:id"},
+		{"seller.com", "GET", "/orders/1", "*"},
+		{"buyer.com", "GET", "/orders/1", "/orders/{id}"},
+		{"buyer.com", "GET", "/files/docs/report.pdf", "/files/{path...}"},
+		{"unknown.com", "GET", "/products/1", ""},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s%s", tt.method, tt.host, tt.path), func(t *testing.T) {
+			definition, found := rt.lookup(tt.host, tt.method, tt.path)
+			assert.Equal(t, tt.expected != "", found)
+			assert.Equal(t, tt.expected, definition.Path)
+
+			// the router must select the same definition as scanning the definition stores
+			expected, _ := scanDefinitions(definitions, tt.host, tt.method, tt.path)
+			assert.Equal(t, expected.Path, definition.Path)
+		})
+	}
+}
+
+func BenchmarkRouter_Lookup(b *testing.B) {
+	paths := make([]string, 0, 3000)
+	for i := 0; i < 1000; i++ {
+		paths = append(paths, fmt.Sprintf("/v1/resource-%d", i), fmt.Sprintf("/v1/resource-%d/:id", i), fmt.Sprintf("/v1/resource-%d/:id/*", i))
+	}
+	definitions := newTestDefinitions("marketplace.com", "GET", paths...)
+
+	b.Run("Router", func(b *testing.B) {
+		rt := newRouter(definitions)
+		for i := 0; i < b.N; i++ {
+			rt.lookup("marketplace.com", "GET", "/v1/resource-999/1")
+		}
+	})
+	b.Run("Scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			scanDefinitions(definitions, "marketplace.com", "GET", "/v1/resource-999/1")
+		}
+	})
+}
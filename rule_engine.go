@@ -0,0 +1,111 @@
+package mockhttp
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/parser"
+	"github.com/expr-lang/expr/vm"
+)
+
+// CompiledRule is the rule expression compiled by a RuleEngine.
+// The concrete type is only meaningful to the RuleEngine that compiled it.
+type CompiledRule interface{}
+
+// RuleEngine Contract:
+// 1. Compile : compile (and validate) the rule expression once, during LoadDefinition
+// 2. Eval    : evaluate the compiled rule against the incoming request variables, for every request
+//
+// used to plug in any expression language (CEL, Starlark, in-house DSL, etc...) as the response rules,
+// without changing the matching logic of the resolver.
+//
+// env contains all the rule variables (and custom functions) available to the rule,
+// filled with zero value request on Compile, and the incoming request on Eval.
+type RuleEngine interface {
+	Compile(rule string, env map[string]interface{}) (CompiledRule, error)
+	Eval(rule CompiledRule, env map[string]interface{}) (bool, error)
+}
+
+// RuleInspector is optionally implemented by the RuleEngine, to report the variables referenced by the rule.
+//
+// used during LoadDefinition to find the rules that access the request body (`body` / `raw`),
+// so the request body is only read and parsed when a rule of the matched definition needs it.
+// Without it, every rule is assumed to access the request body.
+type RuleInspector interface {
+	Variables(rule string, env map[string]interface{}) ([]string, error)
+}
+
+// exprRuleEngine is the default RuleEngine, using expr-lang (https://expr-lang.org) expression.
+type exprRuleEngine struct{}
+
+// NewExprRuleEngine returns the default RuleEngine, based on expr-lang expression.
+func NewExprRuleEngine() RuleEngine {
+	return exprRuleEngine{}
+}
+
+// Compile type check the rule against env, and ensure the rule always evaluated into boolean.
+func (exprRuleEngine) Compile(rule string, env map[string]interface{}) (CompiledRule, error) {
+	options := []expr.Option{expr.Env(env), expr.AsBool()}
+	for name, value := range env {
+		// allow custom function to override built-in function with the same name
+		if reflect.TypeOf(value) != nil && reflect.TypeOf(value).Kind() == reflect.Func {
+			options = append(options, expr.DisableBuiltin(name))
+		}
+	}
+	return expr.Compile(rule, options...)
+}
+
+// Eval run the compiled expr program against env.
+func (exprRuleEngine) Eval(rule CompiledRule, env map[string]interface{}) (bool, error) {
+	program, ok := rule.(*vm.Program)
+	if !ok {
+		return false, fmt.Errorf("unexpected compiled rule %T", rule)
+	}
+
+	evalRes, err := expr.Run(program, env)
+	if err != nil {
+		return false, err
+	}
+	fulfilled, ok := evalRes.(bool)
+	if !ok {
+		return false, fmt.Errorf("rule evaluated to %T instead of bool", evalRes)
+	}
+	return fulfilled, nil
+}
+
+// Variables returns the identifiers referenced by the expression.
+// The whole env is reported when the expression access the env directly (via $env).
+func (exprRuleEngine) Variables(rule string, env map[string]interface{}) ([]string, error) {
+	tree, err := parser.Parse(rule)
+	if err != nil {
+		return nil, err
+	}
+	visitor := &identifierVisitor{seen: make(map[string]bool)}
+	ast.Walk(&tree.Node, visitor)
+
+	if visitor.seen["$env"] {
+		variables := make([]string, 0, len(env))
+		for name := range env {
+			variables = append(variables, name)
+		}
+		return variables, nil
+	}
+	return visitor.identifiers, nil
+}
+
+// identifierVisitor collect the (unique) identifiers of the expression.
+type identifierVisitor struct {
+	seen        map[string]bool
+	identifiers []string
+}
+
+func (v *identifierVisitor) Visit(node *ast.Node) {
+	identifier, ok := (*node).(*ast.IdentifierNode)
+	if !ok || v.seen[identifier.Value] {
+		return
+	}
+	v.seen[identifier.Value] = true
+	v.identifiers = append(v.identifiers, identifier.Value)
+}
@@ -0,0 +1,92 @@
+package mockhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// headerRuleEngine is a minimal in-house DSL, only support `<header name>=<value>` rule.
+type headerRuleEngine struct{}
+
+type headerRule struct {
+	name  string
+	value string
+}
+
+func (headerRuleEngine) Compile(rule string, env map[string]interface{}) (CompiledRule, error) {
+	name, value, found := strings.Cut(rule, "=")
+	if !found {
+		return nil, fmt.Errorf("expected <header name>=<value>")
+	}
+	return headerRule{name: name, value: value}, nil
+}
+
+func (headerRuleEngine) Eval(rule CompiledRule, env map[string]interface{}) (bool, error) {
+	compiled := rule.(headerRule)
+	headers := env["headers"].(map[string]interface{})
+	return headers[compiled.name] == compiled.value, nil
+}
+
+func TestFileBasedResolver_CustomRuleEngine(t *testing.T) {
+	resolver := newTestResolverWithFiles(t, map[string]string{
+		"region.yaml": `
+host: marketplace.com
+path: /check-price
+method: GET
+responses:
+  - status_code: 200
+  - status_code: 451
+    rules:
+      - X-Region=RU
+`,
+	}, WithRuleEngine(headerRuleEngine{}))
+
+	req := newTestRequest(t, http.MethodGet, "http://marketplace.com/check-price", "")
+	req.Header.Set("X-Region", "RU")
+
+	resp, err := resolver.Resolve(context.Background(), req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnavailableForLegalReasons, resp.StatusCode)
+}
+
+func Test_exprRuleEngine(t *testing.T) {
+	engine := NewExprRuleEngine()
+	env := map[string]interface{}{"body": map[string]interface{}{}}
+
+	_, err := engine.Compile("body.name ==", env)
+	assert.NotNil(t, err, "should err")
+
+	compiled, err := engine.Compile(`body.name == "William"`, env)
+	assert.Nil(t, err, "should not error")
+
+	fulfilled, err := engine.Eval(compiled, map[string]interface{}{"body": map[string]interface{}{"name": "William"}})
+	assert.Nil(t, err, "should not error")
+	assert.True(t, fulfilled)
+}
+
+func Test_exprRuleEngine_Variables(t *testing.T) {
+	inspector := NewExprRuleEngine().(RuleInspector)
+	env := map[string]interface{}{"body": nil, "headers": nil}
+
+	tests := []struct {
+		rule     string
+		expected []string
+	}{
+		{`headers["X-Region"] == "RU" && routeParams.id != body.id`, []string{"headers", "routeParams", "body"}},
+		{`all(queryParams.ids, {# in ["1", "2"]}) and raw contains "name"`, []string{"queryParams", "raw"}},
+		{`$env.headers == nil`, []string{"body", "headers"}},
+	}
+	for _, tt := range tests {
+		variables, err := inspector.Variables(tt.rule, env)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, tt.expected, variables, tt.rule)
+	}
+
+	_, err := inspector.Variables("body.name ==", env)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,173 @@
+package mockhttp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+)
+
+// ServerOption configure optional behavior of the mock server (and handler).
+type ServerOption func(*resolverHandler)
+
+// WithServerHost resolve every request received by the mock server as if it was sent to host,
+// so mock definitions written for the actual upstream (ex: api.example.com) match the requests
+// sent to the test server address. By default, the Host header of the request is used.
+func WithServerHost(host string) ServerOption {
+	return func(h *resolverHandler) {
+		h.host = host
+	}
+}
+
+// WithMissHandler delegate the request without mock response to the handler, instead of answering 404 Not Found.
+// ex: the next handler of the router, or httputil.NewSingleHostReverseProxy to pass the request through
+// to the actual upstream.
+func WithMissHandler(handler http.Handler) ServerOption {
+	return func(h *resolverHandler) {
+		h.missHandler = handler
+	}
+}
+
+// resolverHandler serve the mock responses of the resolver (or the client) over HTTP.
+type resolverHandler struct {
+	resolver    ResolverAdapter
+	client      *Client
+	host        string
+	missHandler http.Handler
+}
+
+// NewServer starts and returns a new *httptest.Server answering every request from the resolver,
+// so code that takes a base URL (rather than an injectable http.Client) can be tested against the mock definitions.
+// The caller should call Close when finished, to shut it down.
+//
+// ex:
+//
+//	resolver, _ := mockhttp.NewFileResolverAdapter("./mocks")
+//	if err := resolver.LoadDefinition(ctx); err != nil { ... }
+//
+//	server := mockhttp.NewServer(resolver, mockhttp.WithServerHost("api.example.com"))
+//	defer server.Close()
+//
+//	sdk := example.NewClient(server.URL)
+//
+// Request without mock response is answered with 404 Not Found, and simulated error (ex: timeout)
+// closes the connection without any response.
+func NewServer(resolver ResolverAdapter, opts ...ServerOption) *httptest.Server {
+	return httptest.NewServer(Handler(resolver, opts...))
+}
+
+// Handler returns http.Handler answering every request from the resolver, just like NewServer,
+// so the mock definitions can be served on any address (ex: standalone mock server),
+// or mounted inside an existing test server or router.
+//
+// ex: serve the mocks of the payment service, and fallback to the router for the other routes
+//
+//	r := chi.NewRouter()
+//	r.NotFound(mockhttp.Handler(resolver, mockhttp.WithMissHandler(http.NotFoundHandler())).ServeHTTP)
+func Handler(resolver ResolverAdapter, opts ...ServerOption) http.Handler {
+	handler := &resolverHandler{resolver: resolver}
+	for _, opt := range opts {
+		opt(handler)
+	}
+	return handler
+}
+
+// ClientHandler returns http.Handler answering every request via the client (Client.Do), just like Handler,
+// so the served requests are journaled, reported (events, metrics) and verifiable just like the requests
+// sent by the Go code. Set client.MockOnly to handle the requests without mock response with the miss handler
+// (or 404 Not Found), otherwise they are sent to the actual upstream.
+//
+// Upstream failure (and simulated error) is answered with 502 Bad Gateway.
+func ClientHandler(client *Client, opts ...ServerOption) http.Handler {
+	handler := &resolverHandler{client: client}
+	for _, opt := range opts {
+		opt(handler)
+	}
+	return handler
+}
+
+func (h *resolverHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	inReq := r.Clone(r.Context())
+	inReq.RequestURI = ""
+	inReq.URL.Scheme = "http"
+	if r.TLS != nil {
+		inReq.URL.Scheme = "https"
+	}
+	inReq.URL.Host = r.Host
+	if h.host != "" {
+		inReq.URL.Host = h.host
+		inReq.Host = h.host
+	}
+	if r.ContentLength == 0 {
+		inReq.Body = nil
+	}
+
+	req, err := FromRequest(inReq)
+	if err == nil {
+		// the resolver reads the request body, make sure it can be read again (just like Client.Do)
+		err = req.rewindBody()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var resp *http.Response
+	if h.client != nil {
+		resp, err = h.client.Do(req)
+	} else {
+		resp, err = h.resolver.Resolve(r.Context(), req)
+	}
+	miss := errors.Is(err, ErrNoMockResponse) || errors.Is(err, ErrUnmatchedRequest)
+
+	var simulatedErr *SimulatedError
+	switch {
+	case miss && h.missHandler != nil:
+		// give the (already read) request body back to the miss handler
+		if inReq.Body != nil {
+			body, bodyErr := req.BodyBytes()
+			if bodyErr != nil {
+				http.Error(w, bodyErr.Error(), http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		h.missHandler.ServeHTTP(w, r)
+		return
+	case miss:
+		http.Error(w, fmt.Sprintf("mockhttp: no mock response for %s %s", inReq.Method, inReq.URL), http.StatusNotFound)
+		return
+	case errors.As(err, &simulatedErr):
+		abortConnection(w)
+		return
+	case err != nil && h.client != nil:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer resp.Body.Close()
+
+	for name, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// abortConnection close the underlying connection without writing any response,
+// so the client fails the request with transport error (just like the simulated error).
+func abortConnection(w http.ResponseWriter) {
+	if hijacker, ok := w.(http.Hijacker); ok {
+		if conn, _, err := hijacker.Hijack(); err == nil {
+			_ = conn.Close()
+			return
+		}
+	}
+	http.Error(w, "mockhttp: simulated error", http.StatusBadGateway)
+}
@@ -0,0 +1,79 @@
+package mockhttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewServer(t *testing.T) {
+	resolver := newTestResolverWithFiles(t, map[string]string{
+		"products.yaml": `
+host: marketplace.com
+path: /products/:id
+method: GET
+responses:
+  - status_code: 200
+    response_headers:
+      Content-Type: application/json
+    response_body: '{"id":1}'
+`,
+		"checkout.yaml": `
+host: marketplace.com
+path: /checkout
+method: GET
+responses:
+  - timeout: true
+`,
+	})
+
+	server := NewServer(resolver, WithServerHost("marketplace.com"))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/products/1")
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `{"id":1}`, string(body))
+
+	resp, err = http.Get(server.URL + "/unknown")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	_, err = http.Get(server.URL + "/checkout")
+	assert.NotNil(t, err)
+}
+
+func TestHandler_MissHandler(t *testing.T) {
+	resolver := newTestResolverWithFiles(t, map[string]string{"products.yaml": `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+    response_body: mocked
+`})
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write([]byte("next: " + r.Method + " " + r.URL.Path + " " + string(body)))
+	})
+	server := httptest.NewServer(Handler(resolver, WithServerHost("marketplace.com"), WithMissHandler(next)))
+	defer server.Close()
+
+	get := func(resp *http.Response, err error) string {
+		assert.Nil(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return string(body)
+	}
+	assert.Equal(t, "mocked", get(http.Get(server.URL+"/products")))
+	assert.Equal(t, "next: GET /orders ", get(http.Get(server.URL+"/orders")))
+	assert.Equal(t, `next: POST /orders {"id":1}`, get(http.Post(server.URL+"/orders", "application/json", strings.NewReader(`{"id":1}`))))
+}
@@ -0,0 +1,44 @@
+package mockhttp
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SimulatedError is returned by the resolver adapter to make Client.Do fail the mocked request with Err,
+// as if Err was returned by the actual transport (ex: timeout, connection reset).
+type SimulatedError struct {
+	Err error
+}
+
+func (e *SimulatedError) Error() string {
+	return "simulated error: " + e.Err.Error()
+}
+
+func (e *SimulatedError) Unwrap() error {
+	return e.Err
+}
+
+// timeoutError is the simulated timeout, implementing net.Error with Timeout() == true.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "simulated timeout awaiting response headers" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// newSimulatedTimeout build the simulated timeout error for the request,
+// wrapped in *url.Error just like the error returned by http.Client.
+func newSimulatedTimeout(req *Request) error {
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	return &SimulatedError{
+		Err: &url.Error{
+			Op:  method[:1] + strings.ToLower(method[1:]),
+			URL: req.URL.String(),
+			Err: timeoutError{},
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package mockhttp
+
+// Snapshotter is implemented by resolver adapters that can capture the registered definitions and restore them later,
+// so a test can temporarily add / override definitions and guarantee the restoration afterward, even if the test panics.
+//
+// The built-in file based resolver implements Snapshotter:
+//
+//	snapshotter := resolver.(mockhttp.Snapshotter)
+//	defer snapshotter.Restore(snapshotter.Snapshot())
+type Snapshotter interface {
+	Snapshot() Snapshot
+	Restore(snapshot Snapshot)
+}
+
+// Snapshot is a point-in-time copy of the registered definitions and the state store of a resolver.
+type Snapshot struct {
+	definitions *definitionSet
+	state       map[string]string
+	loaded      bool
+}
+
+// fileBasedResolver Snapshot capture the registered definitions and the state store values.
+//
+// The definitions are captured as is (not re-read), so the response usage counters (max_uses, callCount, round_robin)
+// continue from their current values after restored.
+func (r *fileBasedResolver) Snapshot() Snapshot {
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+
+	return Snapshot{
+		definitions: r.definitions.Load(),
+		state:       r.state.copy(),
+		loaded:      r.isLoaded.Load(),
+	}
+}
+
+// fileBasedResolver Restore replace the registered definitions and the state store values with the snapshot.
+func (r *fileBasedResolver) Restore(snapshot Snapshot) {
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+
+	r.definitions.Store(snapshot.definitions)
+	r.state.replace(snapshot.state)
+	r.isLoaded.Store(snapshot.loaded)
+}
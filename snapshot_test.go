@@ -0,0 +1,51 @@
+package mockhttp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileBasedResolver_SnapshotRestore(t *testing.T) {
+	resolver := newTestResolverWithFiles(t, map[string]string{"products.yaml": `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+`})
+	statusCode := func(url string) int {
+		resp, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, url, ""))
+		if err != nil {
+			return 0
+		}
+		return resp.StatusCode
+	}
+
+	func() {
+		defer func() { _ = recover() }()
+		defer resolver.Restore(resolver.Snapshot())
+
+		assert.Nil(t, resolver.AddDefinition(context.Background(), []byte(`
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 503
+    set_state:
+      outage: "true"
+`)))
+		assert.Nil(t, resolver.AddDefinition(context.Background(), []byte("host: marketplace.com\npath: /orders\nmethod: GET\nresponses:\n  - status_code: 202\n")))
+
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode("http://marketplace.com/products"))
+		assert.Equal(t, http.StatusAccepted, statusCode("http://marketplace.com/orders"))
+		assert.Equal(t, "true", resolver.state.get("outage"))
+		panic("test failed")
+	}()
+
+	assert.Equal(t, http.StatusOK, statusCode("http://marketplace.com/products"))
+	assert.Equal(t, 0, statusCode("http://marketplace.com/orders"))
+	assert.Equal(t, "", resolver.state.get("outage"))
+}
@@ -0,0 +1,72 @@
+package mockhttp
+
+import "sync"
+
+// stateStore is a small key-value store shared across mock definitions,
+// that can be read by the rules (state variable) and templates (state function),
+// and written by the mock responses (set_state).
+//
+// ex: login mock store the issued token, and profile mock check the token against it.
+type stateStore struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+func newStateStore() *stateStore {
+	return &stateStore{values: make(map[string]string)}
+}
+
+func (s *stateStore) get(key string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.values[key]
+}
+
+func (s *stateStore) set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+// export copy all the values, so it can be used safely as rule variable.
+func (s *stateStore) export() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	exported := make(map[string]interface{}, len(s.values))
+	for key, value := range s.values {
+		exported[key] = value
+	}
+	return exported
+}
+
+// reset remove all the values.
+func (s *stateStore) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = make(map[string]string)
+}
+
+// copy returns a copy of all the values.
+func (s *stateStore) copy() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	values := make(map[string]string, len(s.values))
+	for key, value := range s.values {
+		values[key] = value
+	}
+	return values
+}
+
+// replace replace all the values with a copy of values.
+func (s *stateStore) replace(values map[string]string) {
+	replaced := make(map[string]string, len(values))
+	for key, value := range values {
+		replaced[key] = value
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = replaced
+}
@@ -0,0 +1,67 @@
+package mockhttp
+
+// StatsReporter is implemented by resolver adapters that can count how many times each mock definition
+// (and response) was used, ex: for load tests to verify the traffic distribution across the mock responses.
+//
+// The built-in file based resolver implements StatsReporter:
+//
+//	for _, stats := range resolver.(mockhttp.StatsReporter).Stats() {
+//		fmt.Println(stats.Definition, stats.Hits)
+//	}
+type StatsReporter interface {
+	Stats() []DefinitionStats
+}
+
+// DefinitionStats is the hit counts of a single mock definition.
+type DefinitionStats struct {
+	Definition string `json:"definition"`
+	// File is the definition file, empty for definition added at runtime.
+	File string `json:"file,omitempty"`
+	// Required is true when the definition must be used (see Client.AssertExpectations).
+	Required bool `json:"required"`
+	// Hits is the number of the requests matching the definition host, method and path.
+	Hits uint64 `json:"hits"`
+	// Responses is the hit counts of every response, in the definition order.
+	// Empty for definition that has not been parsed yet (lazy load mode).
+	Responses []ResponseStats `json:"responses"`
+}
+
+// ResponseStats is the hit counts of a single mock response.
+type ResponseStats struct {
+	Index      int `json:"index"`
+	StatusCode int `json:"status_code"`
+	// Hits is the number of the requests served by (or simulating timeout with) the response.
+	Hits uint64 `json:"hits"`
+}
+
+// fileBasedResolver Stats
+// Returns the hit counts of all the loaded definitions, since they are loaded (the counts start over after reload).
+func (r *fileBasedResolver) Stats() []DefinitionStats {
+	definitions := r.loadedDefinitions()
+	stats := make([]DefinitionStats, 0, len(definitions))
+	for _, definition := range definitions {
+		definitionStats := DefinitionStats{Definition: definition.name(), File: definition.file, Required: definition.Required}
+
+		if parsed, ok := r.parsedDefinition(definition); ok {
+			definitionStats.Hits = parsed.callCounter.Load()
+			for idx, response := range parsed.Responses {
+				definitionStats.Responses = append(definitionStats.Responses, ResponseStats{
+					Index:      idx,
+					StatusCode: response.StatusCode,
+					Hits:       response.useCounter.Load(),
+				})
+			}
+		}
+		stats = append(stats, definitionStats)
+	}
+	return stats
+}
+
+// Served returns the number of the requests served by any of the definition responses.
+func (s DefinitionStats) Served() uint64 {
+	var served uint64
+	for _, response := range s.Responses {
+		served += response.Hits
+	}
+	return served
+}
@@ -0,0 +1,36 @@
+package mockhttp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileBasedResolver_Stats(t *testing.T) {
+	resolver := newTestResolverWithFiles(t, map[string]string{"products.yaml": `
+host: marketplace.com
+path: /products
+method: GET
+strategy: round_robin
+responses:
+  - status_code: 200
+  - status_code: 503
+`})
+
+	for i := 0; i < 5; i++ {
+		_, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, "http://marketplace.com/products", ""))
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, []DefinitionStats{{
+		Definition: "GET marketplace.com/products",
+		File:       "products.yaml",
+		Hits:       5,
+		Responses: []ResponseStats{
+			{Index: 0, StatusCode: http.StatusOK, Hits: 3},
+			{Index: 1, StatusCode: http.StatusServiceUnavailable, Hits: 2},
+		},
+	}}, resolver.Stats())
+}
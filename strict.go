@@ -0,0 +1,38 @@
+package mockhttp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// UnmatchedHandler is called in mock-only mode (Client.MockOnly) instead of the upstream request,
+// when no mock response is found for the request.
+type UnmatchedHandler func(req *Request) (*http.Response, error)
+
+// NotImplementedHandler is an UnmatchedHandler that respond the unmatched request with 501 Not Implemented,
+// so the caller can handle it like any other upstream error response.
+func NotImplementedHandler(req *Request) (*http.Response, error) {
+	body := fmt.Sprintf("mockhttp: no mock response for %s %s", req.Method, req.URL)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", http.StatusNotImplemented, http.StatusText(http.StatusNotImplemented)),
+		StatusCode:    http.StatusNotImplemented,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"text/plain; charset=utf-8"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req.Request,
+	}, nil
+}
+
+// unmatched handle the request with no mock response in mock-only mode,
+// either via the UnmatchedHandler or by returning ErrUnmatchedRequest.
+func (c *Client) unmatched(req *Request) (*http.Response, error) {
+	if c.UnmatchedHandler != nil {
+		return c.UnmatchedHandler(req)
+	}
+	return nil, &UnmatchedRequestError{Method: req.Method, URL: req.URL.String()}
+}
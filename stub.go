@@ -0,0 +1,214 @@
+package mockhttp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// StubManager is implemented by resolver adapters that can create, update, delete and list
+// mock definitions at runtime by ID (stub), ex: programmed by test frameworks in other languages
+// via NewStubHandler in server mode.
+//
+// The built-in file based resolver implements StubManager:
+//
+//	created, err := resolver.(mockhttp.StubManager).PutStub(ctx, "checkout-down", []byte(spec))
+//
+// PutStub reports whether a new stub is created (false when the stub with the same ID is replaced).
+type StubManager interface {
+	PutStub(ctx context.Context, id string, spec []byte) (bool, error)
+	DeleteStub(ctx context.Context, id string) error
+	Stubs() []Stub
+}
+
+// Stub is a mock definition registered at runtime by ID.
+type Stub struct {
+	ID     string `json:"id"`
+	Method string `json:"method"`
+	Host   string `json:"host"`
+	Path   string `json:"path"`
+	// Spec is the mock definition spec (.yaml) of the stub.
+	Spec string `json:"spec"`
+}
+
+// fileBasedResolver PutStub
+// Parse the mock definition spec (.yaml) and register it as stub before the loaded definitions
+// (just like AddDefinition), replacing the previous stub with the same ID.
+//
+// The stubs are kept until the definitions are reloaded, reset or restored (see Snapshotter).
+func (r *fileBasedResolver) PutStub(ctx context.Context, id string, spec []byte) (bool, error) {
+	definition, err := r.parseDefinition(spec)
+	if err != nil {
+		return false, err
+	}
+	definition.stub = &Stub{ID: id, Method: definition.Method, Host: definition.Host, Path: definition.Path, Spec: string(spec)}
+
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+
+	created := true
+	definitions := mockDefinitions{definition}
+	for _, loaded := range r.loadedDefinitions() {
+		if loaded.stub != nil && loaded.stub.ID == id {
+			created = false
+			continue
+		}
+		definitions = append(definitions, loaded)
+	}
+	r.setDefinitions(definitions)
+	r.isLoaded.Store(true)
+	return created, nil
+}
+
+// fileBasedResolver DeleteStub
+// Unregister the stub with the ID, return ErrStubNotFound when there is no such stub.
+func (r *fileBasedResolver) DeleteStub(ctx context.Context, id string) error {
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+
+	loaded := r.loadedDefinitions()
+	definitions := make(mockDefinitions, 0, len(loaded))
+	for _, definition := range loaded {
+		if definition.stub == nil || definition.stub.ID != id {
+			definitions = append(definitions, definition)
+		}
+	}
+	if len(definitions) == len(loaded) {
+		return ErrStubNotFound
+	}
+	r.setDefinitions(definitions)
+	return nil
+}
+
+// fileBasedResolver Stubs
+// List the registered stubs, in the matching priority order (the most recently put first).
+func (r *fileBasedResolver) Stubs() []Stub {
+	stubs := []Stub{}
+	for _, definition := range r.loadedDefinitions() {
+		if definition.stub != nil {
+			stubs = append(stubs, *definition.stub)
+		}
+	}
+	return stubs
+}
+
+// maxStubSpecSize limit the size of the stub spec accepted by the stub handler.
+const maxStubSpecSize = 1 << 20
+
+type stubHandler struct {
+	manager StubManager
+}
+
+// NewStubHandler creates HTTP handler to manage the stubs at runtime (WireMock admin style),
+// so test frameworks in other languages can program the standalone mock server:
+//   - GET    /stubs      : list the stubs as JSON
+//   - POST   /stubs      : create stub from the mock definition spec (.yaml) in the request body, with generated ID
+//   - GET    /stubs/{id} : get the stub as JSON
+//   - PUT    /stubs/{id} : create or update the stub from the mock definition spec (.yaml) in the request body
+//   - DELETE /stubs/{id} : delete the stub
+//
+// The handler routes are relative, mount it with http.StripPrefix:
+//
+//	mux.Handle(mockhttp.AdminPath+"/stubs", http.StripPrefix(mockhttp.AdminPath, mockhttp.NewStubHandler(manager)))
+//	mux.Handle(mockhttp.AdminPath+"/stubs/", http.StripPrefix(mockhttp.AdminPath, mockhttp.NewStubHandler(manager)))
+func NewStubHandler(manager StubManager) http.Handler {
+	return &stubHandler{manager: manager}
+}
+
+func (h *stubHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/stubs" || r.URL.Path == "/stubs/" {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, h.manager.Stubs())
+		case http.MethodPost:
+			id, err := NewStubID()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			h.put(w, r, id)
+		default:
+			http.Error(w, "mockhttp: method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/stubs/")
+	if id == r.URL.Path || id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		stub, exist := h.find(id)
+		if !exist {
+			http.Error(w, "mockhttp: "+ErrStubNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, stub)
+	case http.MethodPut:
+		h.put(w, r, id)
+	case http.MethodDelete:
+		err := h.manager.DeleteStub(r.Context(), id)
+		switch {
+		case errors.Is(err, ErrStubNotFound):
+			http.Error(w, "mockhttp: "+err.Error(), http.StatusNotFound)
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		http.Error(w, "mockhttp: method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// put create or update the stub from the request body, respond with 201 Created for new stub (200 OK otherwise).
+// The spec larger than maxStubSpecSize is rejected with 413 Request Entity Too Large.
+func (h *stubHandler) put(w http.ResponseWriter, r *http.Request, id string) {
+	spec, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxStubSpecSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "mockhttp: stub spec too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.manager.PutStub(r.Context(), id, spec)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stub, _ := h.find(id)
+	if created {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+	}
+	writeJSON(w, stub)
+}
+
+func (h *stubHandler) find(id string) (Stub, bool) {
+	for _, stub := range h.manager.Stubs() {
+		if stub.ID == id {
+			return stub, true
+		}
+	}
+	return Stub{}, false
+}
+
+// NewStubID generate random ID for the stub created without ID (see StubManager).
+func NewStubID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,200 @@
+package mockhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// StubBuilder build a mock definition in code, matched by the same engine as the definition files:
+//
+//	err := mockhttp.NewStub().
+//		Host("api.example.com").
+//		Post("/orders").
+//		WhenBody(`body.amount > 100`).Reply(402).JSON(map[string]string{"error": "payment required"}).
+//		Reply(201).
+//		Register(ctx, resolver)
+//
+// Every Reply add a response to the definition, with the rules (When...) declared since the previous Reply.
+// The response without rule is the default response, just like in the definition file.
+type StubBuilder struct {
+	definition stubDefinition
+	// rules are declared for the next Reply.
+	rules []string
+	err   error
+}
+
+type stubDefinition struct {
+	Host      string         `yaml:"host"`
+	Path      string         `yaml:"path"`
+	Method    string         `yaml:"method"`
+	Desc      string         `yaml:"desc,omitempty"`
+	Required  bool           `yaml:"required,omitempty"`
+	Responses []stubResponse `yaml:"responses"`
+}
+
+type stubResponse struct {
+	Rules           []string          `yaml:"rules,omitempty"`
+	StatusCode      int               `yaml:"status_code"`
+	ResponseHeaders map[string]string `yaml:"response_headers,omitempty"`
+	Body            string            `yaml:"response_body,omitempty"`
+	Delay           int               `yaml:"delay,omitempty"`
+}
+
+// NewStub starts building a mock definition in code.
+func NewStub() *StubBuilder {
+	return &StubBuilder{}
+}
+
+// Host set the host matched by the definition, ex: api.example.com.
+func (b *StubBuilder) Host(host string) *StubBuilder {
+	b.definition.Host = host
+	return b
+}
+
+// Method set the http method and path (same pattern as the definition file, ex: /orders/:id) matched by the definition.
+func (b *StubBuilder) Method(method, path string) *StubBuilder {
+	b.definition.Method = method
+	b.definition.Path = path
+	return b
+}
+
+// Get match GET request on the path.
+func (b *StubBuilder) Get(path string) *StubBuilder {
+	return b.Method(http.MethodGet, path)
+}
+
+// Post match POST request on the path.
+func (b *StubBuilder) Post(path string) *StubBuilder {
+	return b.Method(http.MethodPost, path)
+}
+
+// Put match PUT request on the path.
+func (b *StubBuilder) Put(path string) *StubBuilder {
+	return b.Method(http.MethodPut, path)
+}
+
+// Patch match PATCH request on the path.
+func (b *StubBuilder) Patch(path string) *StubBuilder {
+	return b.Method(http.MethodPatch, path)
+}
+
+// Delete match DELETE request on the path.
+func (b *StubBuilder) Delete(path string) *StubBuilder {
+	return b.Method(http.MethodDelete, path)
+}
+
+// Desc set the description of the definition.
+func (b *StubBuilder) Desc(desc string) *StubBuilder {
+	b.definition.Desc = desc
+	return b
+}
+
+// Required expect the definition to serve at least one request (see Client.AssertExpectations).
+func (b *StubBuilder) Required() *StubBuilder {
+	b.definition.Required = true
+	return b
+}
+
+// When add the rule expression (same as the definition file rules) to the next Reply.
+func (b *StubBuilder) When(rule string) *StubBuilder {
+	b.rules = append(b.rules, rule)
+	return b
+}
+
+// WhenBody add the rule expression on the request body to the next Reply, ex: body.amount > 100.
+func (b *StubBuilder) WhenBody(rule string) *StubBuilder {
+	return b.When(rule)
+}
+
+// WhenHeader add the rule matching the request header value to the next Reply.
+func (b *StubBuilder) WhenHeader(name, value string) *StubBuilder {
+	return b.When(fmt.Sprintf("headers[%q] == %q", http.CanonicalHeaderKey(name), value))
+}
+
+// WhenQuery add the rule matching the request query param value to the next Reply.
+func (b *StubBuilder) WhenQuery(name, value string) *StubBuilder {
+	return b.When(fmt.Sprintf("queryParams[%q] == %q", name, value))
+}
+
+// Reply add the response with the status code, selected when all the rules declared since the previous Reply are fulfilled.
+func (b *StubBuilder) Reply(statusCode int) *StubBuilder {
+	b.definition.Responses = append(b.definition.Responses, stubResponse{Rules: b.rules, StatusCode: statusCode})
+	b.rules = nil
+	return b
+}
+
+// Header set the header of the last response.
+func (b *StubBuilder) Header(name, value string) *StubBuilder {
+	response := b.response()
+	if response.ResponseHeaders == nil {
+		response.ResponseHeaders = make(map[string]string)
+	}
+	response.ResponseHeaders[http.CanonicalHeaderKey(name)] = value
+	return b
+}
+
+// Body set the body of the last response.
+func (b *StubBuilder) Body(body string) *StubBuilder {
+	b.response().Body = body
+	return b
+}
+
+// JSON set the body of the last response to v encoded as JSON, along with the application/json content type.
+func (b *StubBuilder) JSON(v interface{}) *StubBuilder {
+	body, err := json.Marshal(v)
+	if err != nil {
+		b.fail(err)
+		return b
+	}
+	return b.Header("Content-Type", "application/json").Body(string(body))
+}
+
+// Delay set the delay of the last response, in millisecond precision.
+func (b *StubBuilder) Delay(d time.Duration) *StubBuilder {
+	b.response().Delay = int(d / time.Millisecond)
+	return b
+}
+
+// response returns the last response, replying 200 when no response is added yet.
+func (b *StubBuilder) response() *stubResponse {
+	if len(b.definition.Responses) == 0 {
+		b.Reply(http.StatusOK)
+	}
+	return &b.definition.Responses[len(b.definition.Responses)-1]
+}
+
+func (b *StubBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Spec returns the mock definition spec (.yaml) of the stub, the same as the definition file.
+func (b *StubBuilder) Spec() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.rules) > 0 {
+		return nil, fmt.Errorf("%w: rules %q without reply", ErrInvalidDefinition, b.rules)
+	}
+	return yaml.Marshal(b.definition)
+}
+
+// Register add the stub definition into the resolver (see DefinitionAdder),
+// taking priority over the loaded definitions with the same method, host and path.
+func (b *StubBuilder) Register(ctx context.Context, resolver ResolverAdapter) error {
+	adder, ok := resolver.(DefinitionAdder)
+	if !ok {
+		return fmt.Errorf("resolver %T does not support adding definition", resolver)
+	}
+	spec, err := b.Spec()
+	if err != nil {
+		return err
+	}
+	return adder.AddDefinition(ctx, spec)
+}
@@ -0,0 +1,73 @@
+package mockhttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStubBuilder(t *testing.T) {
+	resolver := NewMemoryResolverAdapter()
+	assert.Nil(t, resolver.LoadDefinition(context.Background()))
+
+	err := NewStub().
+		Host("api.example.com").
+		Post("/orders").
+		Desc("create order").
+		WhenBody(`body.amount > 100`).Reply(http.StatusPaymentRequired).JSON(map[string]string{"error": "payment required"}).
+		WhenHeader("x-region", "RU").Reply(http.StatusUnavailableForLegalReasons).
+		Reply(http.StatusCreated).Header("Location", "/orders/1").Delay(time.Millisecond).
+		Register(context.Background(), resolver)
+	assert.Nil(t, err)
+
+	client := newTestClient(resolver)
+	client.MockOnly = true
+
+	resp, err := client.Post("http://api.example.com/orders", "application/json", strings.NewReader(`{"amount": 150}`))
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusPaymentRequired, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"error": "payment required"}`, string(body))
+
+	req, err := NewRequest(http.MethodPost, "http://api.example.com/orders", []byte(`{"amount": 50}`))
+	assert.Nil(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Region", "RU")
+	resp, err = client.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnavailableForLegalReasons, resp.StatusCode)
+
+	resp, err = client.Post("http://api.example.com/orders", "application/json", strings.NewReader(`{"amount": 50}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "/orders/1", resp.Header.Get("Location"))
+
+	_, err = client.Get("http://api.example.com/orders")
+	assert.ErrorIs(t, err, ErrUnmatchedRequest)
+}
+
+func TestStubBuilder_Spec(t *testing.T) {
+	spec, err := NewStub().Host("api.example.com").Get("/products/:id").WhenQuery("page", "1").Body("page 1").Spec()
+	assert.Nil(t, err)
+	assert.Equal(t, `host: api.example.com
+path: /products/:id
+method: GET
+responses:
+- rules:
+  - queryParams["page"] == "1"
+  status_code: 200
+  response_body: page 1
+`, string(spec))
+
+	_, err = NewStub().Host("api.example.com").Get("/products").Reply(http.StatusOK).When("callCount > 1").Spec()
+	assert.ErrorIs(t, err, ErrInvalidDefinition)
+
+	_, err = NewStub().Host("api.example.com").Get("/products").JSON(func() {}).Spec()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,119 @@
+package mockhttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const checkoutStub = `
+host: marketplace.com
+path: /checkout
+method: GET
+responses:
+  - status_code: 503
+`
+
+func TestFileBasedResolver_Stubs(t *testing.T) {
+	resolver := newTestResolverWithFiles(t, map[string]string{"checkout.yaml": `
+host: marketplace.com
+path: /checkout
+method: GET
+responses:
+  - status_code: 200
+`})
+	var manager StubManager = resolver
+	ctx := context.Background()
+
+	resolve := func() int {
+		resp, err := resolver.Resolve(ctx, newTestRequest(t, http.MethodGet, "http://marketplace.com/checkout", ""))
+		assert.Nil(t, err)
+		return resp.StatusCode
+	}
+
+	created, err := manager.PutStub(ctx, "checkout-down", []byte(checkoutStub))
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.Equal(t, http.StatusServiceUnavailable, resolve())
+	assert.Equal(t, []Stub{{ID: "checkout-down", Method: "GET", Host: "marketplace.com", Path: "/checkout", Spec: checkoutStub}}, manager.Stubs())
+
+	// update replace the previous stub with the same ID
+	created, err = manager.PutStub(ctx, "checkout-down", []byte(strings.Replace(checkoutStub, "503", "502", 1)))
+	assert.Nil(t, err)
+	assert.False(t, created)
+	assert.Equal(t, http.StatusBadGateway, resolve())
+	assert.Len(t, manager.Stubs(), 1)
+
+	_, err = manager.PutStub(ctx, "broken", []byte("host: [marketplace.com"))
+	assert.NotNil(t, err)
+
+	assert.Nil(t, manager.DeleteStub(ctx, "checkout-down"))
+	assert.Equal(t, http.StatusOK, resolve())
+	assert.Empty(t, manager.Stubs())
+	assert.ErrorIs(t, manager.DeleteStub(ctx, "checkout-down"), ErrStubNotFound)
+}
+
+func TestStubHandler(t *testing.T) {
+	resolver := newTestResolverWithFiles(t, map[string]string{})
+	server := httptest.NewServer(NewStubHandler(resolver))
+	defer server.Close()
+
+	do := func(method, path, body string) (*http.Response, Stub) {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		assert.Nil(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		defer resp.Body.Close()
+
+		var stub Stub
+		_ = json.NewDecoder(resp.Body).Decode(&stub)
+		return resp, stub
+	}
+
+	resp, stub := do(http.MethodPost, "/stubs", checkoutStub)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.NotEmpty(t, stub.ID)
+	assert.Equal(t, "/checkout", stub.Path)
+
+	resp, stub = do(http.MethodPut, "/stubs/checkout-down", checkoutStub)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "checkout-down", stub.ID)
+
+	resp, _ = do(http.MethodPut, "/stubs/checkout-down", checkoutStub)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, stub = do(http.MethodGet, "/stubs/checkout-down", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, checkoutStub, stub.Spec)
+
+	resp, err := http.Get(server.URL + "/stubs")
+	assert.Nil(t, err)
+	var stubs []Stub
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&stubs))
+	resp.Body.Close()
+	assert.Len(t, stubs, 2)
+	assert.Equal(t, "checkout-down", stubs[0].ID)
+
+	resp, _ = do(http.MethodPut, "/stubs/broken", "host: [marketplace.com")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// the oversized spec is rejected, instead of registering the truncated spec
+	resp, _ = do(http.MethodPut, "/stubs/large", checkoutStub+"desc: "+strings.Repeat("a", maxStubSpecSize)+"\n")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	resp, _ = do(http.MethodGet, "/stubs/large", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = do(http.MethodDelete, "/stubs/checkout-down", "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, _ = do(http.MethodDelete, "/stubs/checkout-down", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = do(http.MethodGet, "/stubs/checkout-down", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = do(http.MethodPatch, "/stubs", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
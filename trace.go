@@ -0,0 +1,102 @@
+package mockhttp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// definitionStoreNames name the definition stores (see definitionStores), in the same order.
+var definitionStoreNames = []string{"exact", "path_params", "wildcard"}
+
+// MatchTrace is the structured trace of how Resolve matched a request (see WithMatchTrace):
+// the candidate definitions queried from every definition store, the path regex results, and the rule outcomes.
+type MatchTrace struct {
+	Method string
+	Host   string
+	Path   string
+
+	// Stores are the definition stores queried (exact path, with path parameters, with wildcard),
+	// until a definition path matched.
+	Stores []StoreTrace
+
+	// Definition is the matched definition, empty when no definition path matched.
+	Definition string
+
+	// Responses are the rule outcomes of the matched definition responses, Selected mark the served response.
+	Responses []ResponseExplanation
+
+	// Err is the error returned by Resolve (ErrNoMockResponse when no mock response found).
+	Err error
+}
+
+// StoreTrace is the candidate definitions queried from a single definition store.
+type StoreTrace struct {
+	Store      string
+	Candidates []CandidateTrace
+}
+
+// CandidateTrace is the path regex result of a single candidate definition.
+type CandidateTrace struct {
+	Definition string
+	// Regex is the compiled regex of the definition path.
+	Regex       string
+	PathMatched bool
+}
+
+// MatchTraceHandler is called with the trace of every Resolve call.
+type MatchTraceHandler func(trace MatchTrace)
+
+// WithMatchTrace enable the debug mode, where every Resolve call build the trace of the matching pipeline,
+// to answer "why did this request match that mock". The trace is passed to the handler (ex: to log it).
+//
+// Tracing evaluate the rules of the matched definition one more time, so it should not be enabled in load tests.
+func WithMatchTrace(fn MatchTraceHandler) FileResolverOption {
+	return func(r *fileBasedResolver) {
+		r.matchTraceHandler = fn
+	}
+}
+
+// traceCandidate add the path regex result of the definition into the store trace.
+func (t *MatchTrace) traceCandidate(store int, definition fileBasedMockDefinition, matched bool) {
+	if t == nil {
+		return
+	}
+	for len(t.Stores) <= store {
+		t.Stores = append(t.Stores, StoreTrace{Store: definitionStoreNames[len(t.Stores)]})
+	}
+	t.Stores[store].Candidates = append(t.Stores[store].Candidates, CandidateTrace{
+		Definition:  definition.name(),
+		Regex:       definition.pattern().String(),
+		PathMatched: matched,
+	})
+}
+
+// String format the trace into human readable text.
+func (t MatchTrace) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "request: %s %s%s\n", t.Method, t.Host, t.Path)
+	for _, store := range t.Stores {
+		fmt.Fprintf(&sb, "- store %s (%d candidates)\n", store.Store, len(store.Candidates))
+		for _, candidate := range store.Candidates {
+			fmt.Fprintf(&sb, "    %s %s => %t\n", candidate.Definition, candidate.Regex, candidate.PathMatched)
+		}
+	}
+	if t.Definition != "" {
+		fmt.Fprintf(&sb, "matched: %s\n", t.Definition)
+	}
+	for _, response := range t.Responses {
+		marker := " "
+		if response.Selected {
+			marker = "*"
+		}
+		fmt.Fprintf(&sb, "  %s response #%d status %d (default: %t, exhausted: %t, stale: %t, fulfilled: %t)\n",
+			marker, response.Index, response.StatusCode, response.Default, response.Exhausted, response.Stale, response.Fulfilled)
+		for _, rule := range response.Rules {
+			writeRuleExplanation(&sb, rule, 3)
+		}
+	}
+	if t.Err != nil {
+		fmt.Fprintf(&sb, "error: %s\n", t.Err)
+	}
+	return sb.String()
+}
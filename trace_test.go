@@ -0,0 +1,56 @@
+package mockhttp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileBasedResolver_MatchTrace(t *testing.T) {
+	var traces []MatchTrace
+	resolver := newTestResolverWithFiles(t, map[string]string{
+		"product.yaml": `
+host: marketplace.com
+path: /products/:id
+method: GET
+responses:
+  - status_code: 404
+    rules:
+      - routeParams.id == "0"
+  - status_code: 200
+`,
+		"products.yaml": `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+`,
+	}, WithMatchTrace(func(trace MatchTrace) { traces = append(traces, trace) }))
+
+	_, err := resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, "http://marketplace.com/products/1", ""))
+	assert.Nil(t, err)
+	_, err = resolver.Resolve(context.Background(), newTestRequest(t, http.MethodGet, "http://marketplace.com/orders", ""))
+	assert.ErrorIs(t, err, ErrNoMockResponse)
+
+	assert.Len(t, traces, 2)
+	matched := traces[0]
+	assert.Equal(t, "GET marketplace.com/products/:id", matched.Definition)
+	assert.Len(t, matched.Stores, 2)
+	assert.Equal(t, "exact", matched.Stores[0].Store)
+	assert.Equal(t, []CandidateTrace{{Definition: "GET marketplace.com/products", Regex: `^\/products\/*$`, PathMatched: false}}, matched.Stores[0].Candidates)
+	assert.Equal(t, "path_params", matched.Stores[1].Store)
+	assert.True(t, matched.Stores[1].Candidates[0].PathMatched)
+	assert.Len(t, matched.Responses, 2)
+	assert.False(t, matched.Responses[0].Fulfilled)
+	assert.False(t, matched.Responses[0].Selected)
+	assert.True(t, matched.Responses[1].Selected)
+	assert.Nil(t, matched.Err)
+	assert.Contains(t, matched.String(), "  * response #1 status 200")
+
+	unmatched := traces[1]
+	assert.Empty(t, unmatched.Definition)
+	assert.ErrorIs(t, unmatched.Err, ErrNoMockResponse)
+}
@@ -0,0 +1,249 @@
+package mockhttp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// yamlErrorLine extract the line number reported by the YAML parser error (ex: "yaml: line 3: ...").
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// Validator is implemented by resolver adapters that can check the mock definitions without serving traffic,
+// intended for a pre-commit / CI gate.
+//
+// The built-in file based resolver implements Validator:
+//
+//	for _, problem := range resolver.(mockhttp.Validator).Validate(ctx) {
+//		fmt.Println(problem)
+//	}
+type Validator interface {
+	Validate(ctx context.Context) []ValidationError
+}
+
+// ValidationError describes a single problem of a mock definition.
+type ValidationError struct {
+	File string
+	// Field locates the invalid field within the file (ex: responses[0].rules), empty when the whole file is invalid.
+	Field string
+	// Line is the line number of the invalid field (or the YAML syntax error) within the file, 0 when unknown.
+	Line int
+	Err  error
+}
+
+func (e ValidationError) Error() string {
+	location := e.File
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d", e.File, e.Line)
+	}
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %s", location, e.Err)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, e.Field, e.Err)
+}
+
+func (e ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// fileBasedResolver Validate
+// Check all the mock definition specs file (.yaml) in the dir, and return every problem found (empty when all valid):
+//   - file is a valid YAML
+//   - host, method and path are defined, and path start with "/"
+//   - strategy and replay are known
+//   - the same method, host and path is not defined twice
+//   - every response has valid status code (or timeout), non-negative delay and max_uses
+//   - rules compile, schema files can be parsed
+//   - templates (response body, set_state and callbacks) can be parsed
+//
+// The registered definitions are not touched, so Validate can be called before LoadDefinition.
+func (r *fileBasedResolver) Validate(ctx context.Context) []ValidationError {
+	if r.dir == "" {
+		return []ValidationError{}
+	}
+
+	fileItems, err := os.ReadDir(r.dir)
+	if err != nil {
+		return []ValidationError{{File: r.dir, Err: err}}
+	}
+
+	problems := []ValidationError{}
+	definedIn := make(map[string]string)
+	for _, item := range fileItems {
+		if item.IsDir() || !isDefinitionFile(item.Name()) {
+			continue
+		}
+
+		f, err := os.ReadFile(filepath.Join(r.dir, item.Name()))
+		if err != nil {
+			problems = append(problems, ValidationError{File: item.Name(), Err: err})
+			continue
+		}
+
+		var definition fileBasedMockDefinition
+		if err := yaml.Unmarshal(f, &definition); err != nil {
+			problem := ValidationError{File: item.Name(), Err: err}
+			if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+				problem.Line, _ = strconv.Atoi(match[1])
+			}
+			problems = append(problems, problem)
+			continue
+		}
+
+		for _, problem := range r.validateDefinition(definition) {
+			problem.File = item.Name()
+			problem.Line = fieldLine(f, problem.Field)
+			problems = append(problems, problem)
+		}
+
+		name := strings.ToUpper(definition.Method) + " " + definition.Host + definition.Path
+		if file, exist := definedIn[name]; exist {
+			problems = append(problems, ValidationError{
+				File: item.Name(),
+				Err:  fmt.Errorf("%w: %s is already defined in %s", ErrInvalidDefinition, definition.name(), file),
+			})
+			continue
+		}
+		definedIn[name] = item.Name()
+	}
+	return problems
+}
+
+func (r *fileBasedResolver) validateDefinition(definition fileBasedMockDefinition) []ValidationError {
+	var problems []ValidationError
+	invalid := func(field, format string, args ...interface{}) {
+		problems = append(problems, ValidationError{Field: field, Err: fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidDefinition}, args...)...)})
+	}
+
+	if definition.Host == "" {
+		invalid("host", "host is required")
+	}
+	if definition.Method == "" {
+		invalid("method", "method is required")
+	}
+	if !strings.HasPrefix(definition.Path, "/") {
+		invalid("path", "path %q must start with /", definition.Path)
+	}
+	if !in[string](definition.Strategy, []string{"", strategyFirst, strategyRoundRobin, strategyRandom}) {
+		problems = append(problems, ValidationError{Field: "strategy", Err: fmt.Errorf("%w: %q", ErrUnknownStrategy, definition.Strategy)})
+	}
+	if !in[string](definition.Replay, []string{"", replayAlways, replayOnce}) {
+		problems = append(problems, ValidationError{Field: "replay", Err: fmt.Errorf("%w: %q", ErrUnknownReplay, definition.Replay)})
+	}
+	if len(definition.Responses) == 0 {
+		invalid("responses", "at least one response is required")
+	}
+
+	for idx, response := range definition.Responses {
+		field := fmt.Sprintf("responses[%d]", idx)
+		if !response.Timeout && (response.StatusCode < 100 || response.StatusCode > 599) {
+			invalid(field+".status_code", "invalid status code %d", response.StatusCode)
+		}
+		if response.Delay < 0 {
+			invalid(field+".delay", "delay must not be negative")
+		}
+		if response.MaxUses < 0 {
+			invalid(field+".max_uses", "max_uses must not be negative")
+		}
+		if err := r.compileRules(response.Rules); err != nil {
+			problems = append(problems, ValidationError{Field: field + ".rules", Err: err})
+		}
+		if response.SchemaFile != "" {
+			if _, err := r.loadSchema(response.SchemaFile); err != nil {
+				invalid(field+".response_schema", "%s", err)
+			}
+		}
+		if response.EnableTemplate {
+			if err := r.checkTemplate(response.Body); err != nil {
+				invalid(field+".response_body", "%s", err)
+			}
+		}
+
+		keys := make([]string, 0, len(response.SetState))
+		for key := range response.SetState {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := r.checkTemplate(response.SetState[key]); err != nil {
+				invalid(field+".set_state."+key, "%s", err)
+			}
+		}
+
+		for cbIdx, callback := range response.Callbacks {
+			cbField := fmt.Sprintf("%s.callbacks[%d]", field, cbIdx)
+			if callback.URL == "" {
+				invalid(cbField+".url", "url is required")
+			}
+			if !callback.EnableTemplate {
+				continue
+			}
+			for _, text := range []string{callback.URL, callback.Body} {
+				if err := r.checkTemplate(text); err != nil {
+					invalid(cbField, "%s", err)
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// fileBasedResolver checkTemplate check the text can be parsed as Go template (see parseTemplate).
+func (r *fileBasedResolver) checkTemplate(text string) error {
+	_, err := r.parseTemplate("mock-validate", text)
+	return err
+}
+
+// fieldLine find the line number of the field (ex: responses[0].rules) within the YAML spec.
+// When the field is not defined (ex: missing required field), the line of its closest defined parent is returned,
+// or 0 for missing top level field.
+func fieldLine(spec []byte, field string) int {
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(spec, &root); err != nil || len(root.Content) == 0 || field == "" {
+		return 0
+	}
+
+	node, line := root.Content[0], 0
+	for _, part := range strings.Split(field, ".") {
+		name, idx := part, -1
+		if open := strings.IndexByte(part, '['); open >= 0 && strings.HasSuffix(part, "]") {
+			name = part[:open]
+			idx, _ = strconv.Atoi(part[open+1 : len(part)-1])
+		}
+
+		key, value := mappingEntry(node, name)
+		if key == nil {
+			return line
+		}
+		node, line = value, key.Line
+		if idx < 0 {
+			continue
+		}
+		if node.Kind != yamlv3.SequenceNode || idx >= len(node.Content) {
+			return line
+		}
+		node, line = node.Content[idx], node.Content[idx].Line
+	}
+	return line
+}
+
+// mappingEntry returns the key and value node of the key in the YAML mapping node, nil when not found.
+func mappingEntry(node *yamlv3.Node, key string) (*yamlv3.Node, *yamlv3.Node) {
+	if node.Kind != yamlv3.MappingNode {
+		return nil, nil
+	}
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		if node.Content[idx].Value == key {
+			return node.Content[idx], node.Content[idx+1]
+		}
+	}
+	return nil, nil
+}
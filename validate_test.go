@@ -0,0 +1,76 @@
+package mockhttp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileBasedResolver_Validate(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"products.yaml": `
+host: marketplace.com
+path: /products
+method: GET
+responses:
+  - status_code: 200
+`,
+		"products-copy.yaml": `
+host: marketplace.com
+path: /products
+method: get
+responses:
+  - status_code: 200
+`,
+		"orders.yaml": `
+host: marketplace.com
+path: orders
+method: POST
+strategy: sometimes
+responses:
+  - status_code: 2000
+    rules:
+      - body.amount >
+  - status_code: 200
+    enable_template: true
+    response_body: '{"id": "{{ .id "}'
+    callbacks:
+      - method: POST
+  - timeout: true
+`,
+		"broken.yaml": "host: [marketplace.com",
+	}
+	for name, content := range files {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	resolver, err := NewFileResolverAdapter(dir)
+	assert.Nil(t, err)
+
+	problems := resolver.(Validator).Validate(context.Background())
+
+	var located []string
+	for _, problem := range problems {
+		located = append(located, fmt.Sprintf("%s:%d %s", problem.File, problem.Line, problem.Field))
+	}
+	assert.Equal(t, []string{
+		"broken.yaml:1 ",
+		"orders.yaml:3 path",
+		"orders.yaml:5 strategy",
+		"orders.yaml:7 responses[0].status_code",
+		"orders.yaml:8 responses[0].rules",
+		"orders.yaml:12 responses[1].response_body",
+		"orders.yaml:14 responses[1].callbacks[0].url",
+		"products.yaml:0 ",
+	}, located)
+	assert.Equal(t, `orders.yaml:5: strategy: unknown response selection strategy: "sometimes"`, problems[2].Error())
+	assert.ErrorIs(t, problems[2], ErrUnknownStrategy)
+	assert.ErrorIs(t, problems[4], ErrInvalidRule)
+	assert.ErrorIs(t, problems[7], ErrInvalidDefinition)
+	assert.Contains(t, problems[7].Error(), "products-copy.yaml")
+}
@@ -0,0 +1,67 @@
+package mockhttp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Verification assert how the upstreams were called through the client, based on the journal of intercepted requests.
+//
+// ex:
+//
+//	err := client.Verify().Called("POST", "marketplace.com", "/check-price").Times(2)
+type Verification struct {
+	journal Journal
+}
+
+// Verify returns the verification of all the requests intercepted by the client so far
+// (mocked, passthrough and bypassed requests).
+func (c *Client) Verify() *Verification {
+	return &Verification{journal: c.journal.list()}
+}
+
+// Called select the requests with the http method, host and path.
+// Path support the same pattern as the mock definition path (ex: /products/:id, /products/*).
+func (v *Verification) Called(method, host, path string) *CallVerification {
+	return &CallVerification{
+		description: fmt.Sprintf("%s %s%s", strings.ToUpper(method), host, path),
+		count:       len(v.journal.Matching(method, host, path)),
+	}
+}
+
+// CallVerification assert the number of the selected requests.
+type CallVerification struct {
+	description string
+	count       int
+}
+
+// Count returns the number of the selected requests.
+func (c *CallVerification) Count() int {
+	return c.count
+}
+
+// Times returns error when the selected requests were not called exactly n times.
+func (c *CallVerification) Times(n int) error {
+	if c.count != n {
+		return fmt.Errorf("expected %s to be called %d time(s), but called %d time(s)", c.description, n, c.count)
+	}
+	return nil
+}
+
+// Once returns error when the selected requests were not called exactly once.
+func (c *CallVerification) Once() error {
+	return c.Times(1)
+}
+
+// Never returns error when any of the selected requests was called.
+func (c *CallVerification) Never() error {
+	return c.Times(0)
+}
+
+// AtLeast returns error when the selected requests were called less than n times.
+func (c *CallVerification) AtLeast(n int) error {
+	if c.count < n {
+		return fmt.Errorf("expected %s to be called at least %d time(s), but called %d time(s)", c.description, n, c.count)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package mockhttp
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Verify(t *testing.T) {
+	client := newTestClient(staticResolver{statusCode: http.StatusOK})
+
+	for _, url := range []string{
+		"http://marketplace.com/check-price",
+		"http://marketplace.com/check-price",
+	} {
+		_, err := client.Post(url, "application/json", strings.NewReader(`{}`))
+		assert.Nil(t, err)
+	}
+	_, err := client.Get("http://marketplace.com:8080/products/1")
+	assert.Nil(t, err)
+
+	verify := client.Verify()
+	assert.Nil(t, verify.Called("POST", "marketplace.com", "/check-price").Times(2))
+	assert.Nil(t, verify.Called("get", "marketplace.com", "/products/:id").Once())
+	assert.Nil(t, verify.Called("GET", "marketplace.com:8080", "/products/*").AtLeast(1))
+	assert.Nil(t, verify.Called("DELETE", "marketplace.com", "/products/:id").Never())
+	assert.EqualError(t, verify.Called("POST", "marketplace.com", "/check-price").Once(),
+		"expected POST marketplace.com/check-price to be called 1 time(s), but called 2 time(s)")
+
+	client.ResetJournal()
+	assert.Equal(t, 0, client.Verify().Called("POST", "marketplace.com", "/check-price").Count())
+}